@@ -13,6 +13,8 @@ type AppConfig struct {
 	*ReleasePathConfig
 	*COSConfig
 	*FilePathConfig
+	*JudgeConfig
+	Languages []*LanguageConfig `ini:"-"` //配置文件中的编程语言
 }
 
 type ReleasePathConfig struct {
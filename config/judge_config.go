@@ -0,0 +1,42 @@
+package config
+
+import "gopkg.in/ini.v1"
+
+type JudgeConfig struct {
+	Sandbox           bool   `ini:"sandbox"`           //是否在沙箱中运行用户程序
+	SandboxUID        int    `ini:"sandboxUid"`        //沙箱中的用户在宿主机上对应的用户id
+	SandboxGID        int    `ini:"sandboxGid"`        //沙箱中的用户组在宿主机上对应的用户组id
+	SandboxTmpfsSize  int64  `ini:"sandboxTmpfsSize"`  //沙箱工作目录大小，单位为字节
+	Seccomp           bool   `ini:"seccomp"`           //是否根据语言过滤用户程序的系统调用，目前只支持amd64
+	CompileCacheDir   string `ini:"compileCacheDir"`   //编译缓存目录，为空时不使用编译缓存
+	CompileCacheSize  int64  `ini:"compileCacheSize"`  //编译缓存最大大小，单位为字节
+	WorkerPoolSize    int    `ini:"workerPoolSize"`    //预先创建的cgroup数量，也是同时执行的用户程序数量上限，为0时不使用worker池
+	WorkerIdleTimeout int    `ini:"workerIdleTimeout"` //worker空闲超时时间，单位为秒
+	WorkerHealthCheck int    `ini:"workerHealthCheck"` //检查空闲worker的间隔，单位为秒
+	CheckerHeader     string `ini:"checkerHeader"`     //特判程序使用的头文件，比如testlib.h，编译前复制到特判程序所在目录
+	JudgeWorkers      int    `ini:"judgeWorkers"`      //判题队列的worker数量，也是同时判题的提交数量
+	JudgeQueueSize    int    `ini:"judgeQueueSize"`    //判题队列的长度，队列已满时拒绝提交
+	Distributed       bool   `ini:"distributed"`       //是否通过redis stream把提交分发给单独部署的判题worker进程
+	JudgeHeartbeat    int    `ini:"judgeHeartbeat"`    //判题worker的心跳间隔，单位为秒，超过3个间隔没有心跳的worker视为已经退出
+	CaseParallelism   int    `ini:"caseParallelism"`   //一次提交中同时运行的用例数量
+	CPUSlots          int    `ini:"cpuSlots"`          //所有提交同时运行的用户程序数量上限，为0时使用cpu核数
+}
+
+func NewJudgeConfig(cfg *ini.File) *JudgeConfig {
+	judgeConfig := &JudgeConfig{
+		Sandbox:           true,
+		SandboxUID:        65534,
+		SandboxGID:        65534,
+		SandboxTmpfsSize:  16 * 1024 * 1024,
+		Seccomp:           true,
+		CompileCacheSize:  512 * 1024 * 1024,
+		WorkerIdleTimeout: 300,
+		WorkerHealthCheck: 30,
+		JudgeWorkers:      4,
+		JudgeQueueSize:    1024,
+		JudgeHeartbeat:    10,
+		CaseParallelism:   4,
+	}
+	cfg.Section("judge").MapTo(judgeConfig)
+	return judgeConfig
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"gopkg.in/ini.v1"
+	"strings"
+)
+
+// 语言配置的section前缀，比如[language.cpp]
+const languageSectionPrefix = "language."
+
+// LanguageConfig 编程语言配置，没有配置的字段使用内置语言的默认值
+type LanguageConfig struct {
+	Name         string  `ini:"-"`            //语言名称，即section名称去掉前缀
+	SourceFile   string  `ini:"sourceFile"`   //main文件名称
+	CompileCmd   string  `ini:"compileCmd"`   //编译命令模板，为空表示不需要编译
+	RunCmd       string  `ini:"runCmd"`       //运行命令模板
+	TimeFactor   float64 `ini:"timeFactor"`   //时间限制的倍数
+	MemoryFactor float64 `ini:"memoryFactor"` //内存限制的倍数
+	Debugger     string  `ini:"debugger"`     //调试器类型
+	Template     string  `ini:"template"`     //acm模式代码模板文件路径
+	Seccomp      string  `ini:"seccomp"`      //系统调用过滤规则名称
+	Standard     string  `ini:"standard"`     //语言标准，比如c++17、c++20
+	Interpreted  bool    `ini:"interpreted"`  //解释型语言，编译命令只做语法检查
+}
+
+func NewLanguageConfigs(cfg *ini.File) []*LanguageConfig {
+	var languageConfigs []*LanguageConfig
+	for _, section := range cfg.Sections() {
+		if !strings.HasPrefix(section.Name(), languageSectionPrefix) {
+			continue
+		}
+		languageConfig := &LanguageConfig{
+			Name: strings.TrimPrefix(section.Name(), languageSectionPrefix),
+		}
+		section.MapTo(languageConfig)
+		languageConfigs = append(languageConfigs, languageConfig)
+	}
+	return languageConfigs
+}
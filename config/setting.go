@@ -26,5 +26,7 @@ func InitSetting(file string) (*AppConfig, error) {
 	config.EmailConfig = NewEmailConfig(cfg)
 	config.COSConfig = NewCOSConfig(cfg)
 	config.FilePathConfig = NewFilePathConfig(cfg)
+	config.JudgeConfig = NewJudgeConfig(cfg)
+	config.Languages = NewLanguageConfigs(cfg)
 	return config, nil
 }
@@ -9,11 +9,49 @@ const (
 	WrongAnswer
 	// CompileError 编译出错
 	CompileError
-	// RuntimeError 运行出错
+	// RuntimeError 运行出错，包括非0退出码和被信号终止
 	RuntimeError
+	// TimeLimitExceeded 运行超时
+	TimeLimitExceeded
+	// MemoryLimitExceeded 内存超出限制
+	MemoryLimitExceeded
+	// OutputLimitExceeded 输出超出限制
+	OutputLimitExceeded
+	// PresentationError 格式错误，忽略空白字符后答案正确
+	PresentationError
+	// SystemError 判题系统出错
+	SystemError
+	// RestrictedFunction 调用了受限函数，即被禁止的系统调用
+	RestrictedFunction
+	// Pending 等待判题
+	Pending
+	// Compiling 编译中
+	Compiling
+	// Running 运行用例中
+	Running
 )
 
 const (
 	GdbCore = "gdb"
 	PdbCore = "pdb"
 )
+
+// ComparatorType 题目比较用户输出和期望输出的方式
+type ComparatorType string
+
+const (
+	// ComparatorDefault 忽略首尾的空格和换行后比较
+	ComparatorDefault ComparatorType = ""
+	// ComparatorExact 完全一致
+	ComparatorExact ComparatorType = "exact"
+	// ComparatorLine 逐行比较，忽略每行末尾的空白字符以及末尾的空行
+	ComparatorLine ComparatorType = "line"
+	// ComparatorToken 按空白字符分割后逐个比较
+	ComparatorToken ComparatorType = "token"
+	// ComparatorCaseInsensitive 逐行比较并忽略大小写
+	ComparatorCaseInsensitive ComparatorType = "case"
+	// ComparatorFloat 按空白字符分割后逐个比较，数字的绝对误差或者相对误差不超过epsilon时相同
+	ComparatorFloat ComparatorType = "float"
+	// ComparatorUnordered 忽略行的顺序，每行忽略末尾的空白字符
+	ComparatorUnordered ComparatorType = "unordered"
+)
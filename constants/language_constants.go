@@ -6,4 +6,8 @@ const (
 	LanguageC    LanguageType = "c"
 	LanguageJava LanguageType = "java"
 	LanguageGo   LanguageType = "go"
+	LanguageCpp  LanguageType = "cpp"
+	// 解释型语言
+	LanguagePython     LanguageType = "python"
+	LanguageJavaScript LanguageType = "javascript"
 )
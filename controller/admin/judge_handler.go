@@ -0,0 +1,87 @@
+package admin
+
+import (
+	"FanCode/controller/utils"
+	e "FanCode/error"
+	"FanCode/models/dto"
+	r "FanCode/models/vo"
+	"FanCode/service"
+	"github.com/gin-gonic/gin"
+	"time"
+)
+
+// JudgeManagementController
+// @Description: 判题管理，修改用例后重判提交
+type JudgeManagementController interface {
+	// RejudgeProblem 重判题目的所有提交
+	RejudgeProblem(ctx *gin.Context)
+	// RejudgeUser 重判用户的所有提交
+	RejudgeUser(ctx *gin.Context)
+	// RejudgeByTime 重判一段时间内的所有提交
+	RejudgeByTime(ctx *gin.Context)
+}
+
+type judgeManagementController struct {
+	judgeService service.JudgeService
+}
+
+func NewJudgeManagementController(judgeService service.JudgeService) JudgeManagementController {
+	return &judgeManagementController{
+		judgeService: judgeService,
+	}
+}
+
+func (j *judgeManagementController) RejudgeProblem(ctx *gin.Context) {
+	result := r.NewResult(ctx)
+	problemID := utils.GetIntParamOrDefault(ctx, "id", 0)
+	if problemID <= 0 {
+		result.Error(e.ErrBadRequest)
+		return
+	}
+	count, err := j.judgeService.Rejudge(&dto.RejudgeRequestDto{ProblemID: uint(problemID)})
+	if err != nil {
+		result.Error(err)
+		return
+	}
+	result.SuccessData(count)
+}
+
+func (j *judgeManagementController) RejudgeUser(ctx *gin.Context) {
+	result := r.NewResult(ctx)
+	userID := utils.GetIntParamOrDefault(ctx, "id", 0)
+	if userID <= 0 {
+		result.Error(e.ErrBadRequest)
+		return
+	}
+	count, err := j.judgeService.Rejudge(&dto.RejudgeRequestDto{UserID: uint(userID)})
+	if err != nil {
+		result.Error(err)
+		return
+	}
+	result.SuccessData(count)
+}
+
+func (j *judgeManagementController) RejudgeByTime(ctx *gin.Context) {
+	result := r.NewResult(ctx)
+	begin, err := time.ParseInLocation("2006-01-02 15:04:05", ctx.PostForm("begin"), time.Local)
+	if err != nil {
+		result.Error(e.ErrBadRequest)
+		return
+	}
+	end, err := time.ParseInLocation("2006-01-02 15:04:05", ctx.PostForm("end"), time.Local)
+	if err != nil || end.Before(begin) {
+		result.Error(e.ErrBadRequest)
+		return
+	}
+	// 可以同时限定题目
+	count, err2 := j.judgeService.Rejudge(&dto.RejudgeRequestDto{
+		ProblemID: uint(utils.AtoiOrDefault(ctx.PostForm("problemID"), 0)),
+		Begin:     begin,
+		End:       end,
+	})
+	if err2 != nil {
+		result.Error(err2)
+		return
+	}
+	result.SuccessData(count)
+}
@@ -2,11 +2,16 @@ package admin
 
 import (
 	"FanCode/controller/utils"
+	e "FanCode/error"
 	"FanCode/models/po"
 	r "FanCode/models/vo"
 	"FanCode/service"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"io"
+	"mime/multipart"
+	"strconv"
+	"strings"
 )
 
 type ProblemCaseManagementController interface {
@@ -71,10 +76,15 @@ func (p *problemCaseManagementController) InsertProblemCase(ctx *gin.Context) {
 	pcase := &po.ProblemCase{
 		ProblemID: uint(utils.AtoiOrDefault(ctx.PostForm("problemID"), 0)),
 		Name:      ctx.PostForm("name"),
-		Input:     ctx.PostForm("input"),
-		Output:    ctx.PostForm("output"),
+		Subtask:   utils.AtoiOrDefault(ctx.PostForm("subtask"), 0),
 	}
-	id, err := p.problemCaseService.InsertProblemCase(pcase)
+	input, output, closeData, err := getProblemCaseData(ctx)
+	if err != nil {
+		result.Error(err)
+		return
+	}
+	defer closeData()
+	id, err := p.problemCaseService.InsertProblemCase(pcase, input, output)
 	if err != nil {
 		result.Error(err)
 		return
@@ -88,11 +98,25 @@ func (p *problemCaseManagementController) UpdateProblemCase(ctx *gin.Context) {
 		Model: gorm.Model{
 			ID: uint(utils.AtoiOrDefault(ctx.PostForm("id"), 0)),
 		},
-		Name:   ctx.PostForm("name"),
-		Input:  ctx.PostForm("input"),
-		Output: ctx.PostForm("output"),
+		Name: ctx.PostForm("name"),
 	}
-	if err := p.problemCaseService.UpdateProblemCase(pcase); err != nil {
+	// 没有提交子任务时不修改
+	var subtask *int
+	if value, ok := ctx.GetPostForm("subtask"); ok {
+		s, err := strconv.Atoi(value)
+		if err != nil {
+			result.Error(e.ErrBadRequest)
+			return
+		}
+		subtask = &s
+	}
+	input, output, closeData, err := getProblemCaseData(ctx)
+	if err != nil {
+		result.Error(err)
+		return
+	}
+	defer closeData()
+	if err = p.problemCaseService.UpdateProblemCase(pcase, subtask, input, output); err != nil {
 		result.Error(err)
 		return
 	}
@@ -132,3 +156,29 @@ func (p *problemCaseManagementController) GenerateNewProblemCaseName(ctx *gin.Co
 	}
 	result.SuccessData(name)
 }
+
+// getProblemCaseData 读取用例的输入和期望输出，较大的数据通过inputFile和outputFile上传文件，
+// 否则读取input和output表单。没有提交的数据返回nil表示不修改，提交空字符串表示数据为空
+func getProblemCaseData(ctx *gin.Context) (io.Reader, io.Reader, func(), *e.Error) {
+	var files []multipart.File
+	closeData := func() {
+		for _, file := range files {
+			_ = file.Close()
+		}
+	}
+	data := make([]io.Reader, 2)
+	for i, name := range []string{"input", "output"} {
+		if header, err := ctx.FormFile(name + "File"); err == nil {
+			file, err := header.Open()
+			if err != nil {
+				closeData()
+				return nil, nil, nil, e.ErrBadRequest
+			}
+			files = append(files, file)
+			data[i] = file
+		} else if value, ok := ctx.GetPostForm(name); ok {
+			data[i] = strings.NewReader(value)
+		}
+	}
+	return data[0], data[1], closeData, nil
+}
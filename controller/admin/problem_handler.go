@@ -149,6 +149,15 @@ func (q *problemManagementController) getProblemByForm(ctx *gin.Context) (*po.Pr
 	difficultyStr := ctx.PostForm("difficulty")
 	bankIDStr := ctx.PostForm("bankID")
 	problem.Languages = ctx.PostForm("languages")
+	problem.Comparator = ctx.PostForm("comparator")
+	problem.Checker = ctx.PostForm("checker")
+	problem.CheckerLanguage = ctx.PostForm("checkerLanguage")
+	problem.Interactor = ctx.PostForm("interactor")
+	problem.InteractorLanguage = ctx.PostForm("interactorLanguage")
+	problem.RunAllCases = ctx.PostForm("runAllCases") == "true"
+	problem.Subtasks = ctx.PostForm("subtasks")
+	problem.LimitFactors = ctx.PostForm("limitFactors")
+	problem.FunctionSignature = ctx.PostForm("functionSignature")
 	enableStr := ctx.PostForm("enable")
 	var err error
 	// 难度设置
@@ -164,6 +173,17 @@ func (q *problemManagementController) getProblemByForm(ctx *gin.Context) (*po.Pr
 	if problem.Difficulty > 5 || problem.Difficulty < 0 {
 		return nil, e.ErrBadRequest
 	}
+	// 浮点数比较的误差
+	if epsilonStr := ctx.PostForm("epsilon"); epsilonStr != "" {
+		problem.Epsilon, err = strconv.ParseFloat(epsilonStr, 64)
+		if err != nil || problem.Epsilon < 0 {
+			return nil, e.ErrBadRequest
+		}
+	}
+	// 时间、内存和栈大小限制，为空时使用默认值
+	problem.TimeLimit = int64(utils.AtoiOrDefault(ctx.PostForm("timeLimit"), 0))
+	problem.MemoryLimit = int64(utils.AtoiOrDefault(ctx.PostForm("memoryLimit"), 0))
+	problem.StackLimit = int64(utils.AtoiOrDefault(ctx.PostForm("stackLimit"), 0))
 	// 题库id设置
 	var bankID int
 	if bankIDStr != "" {
@@ -17,6 +17,7 @@ var ProviderSet = wire.NewSet(
 	admin.NewSysMenuController,
 	admin.NewSysRoleController,
 	admin.NewSysUserController,
+	admin.NewJudgeManagementController,
 	user.NewJudgeController,
 	user.NewProblemController,
 	user.NewProblemBankController,
@@ -33,6 +34,7 @@ type Controller struct {
 	MenuController                  admin.SysMenuController
 	RoleController                  admin.SysRoleController
 	UserController                  admin.SysUserController
+	JudgeManagementController       admin.JudgeManagementController
 	JudgeController                 user.JudgeController
 	DebugController                 user.DebugController
 	ProblemController               user.ProblemController
@@ -50,6 +52,7 @@ func NewController(
 	menuController admin.SysMenuController,
 	roleController admin.SysRoleController,
 	userController admin.SysUserController,
+	judgeManagementController admin.JudgeManagementController,
 	judgeController user.JudgeController,
 	debugController user.DebugController,
 	problemController user.ProblemController,
@@ -66,6 +69,7 @@ func NewController(
 		MenuController:                  menuController,
 		RoleController:                  roleController,
 		UserController:                  userController,
+		JudgeManagementController:       judgeManagementController,
 		JudgeController:                 judgeController,
 		ProblemController:               problemController,
 		ProblemBankController:           problemBankController,
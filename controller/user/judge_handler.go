@@ -3,10 +3,13 @@ package user
 import (
 	"FanCode/constants"
 	"FanCode/controller/utils"
+	e "FanCode/error"
 	"FanCode/models/dto"
 	r "FanCode/models/vo"
 	"FanCode/service"
+	"encoding/json"
 	"github.com/gin-gonic/gin"
+	"io"
 )
 
 // JudgeController
@@ -16,6 +19,8 @@ type JudgeController interface {
 	Execute(ctx *gin.Context)
 	// Submit 提交
 	Submit(ctx *gin.Context)
+	// Progress 通过SSE推送提交的判题进度
+	Progress(ctx *gin.Context)
 }
 
 type judgeController struct {
@@ -30,11 +35,27 @@ func NewJudgeController(judgeService service.JudgeService) JudgeController {
 
 func (j *judgeController) Execute(ctx *gin.Context) {
 	result := r.NewResult(ctx)
+	files, err := getSourceFiles(ctx)
+	if err != nil {
+		result.Error(err)
+		return
+	}
+	// 多个自测用例为json数组，每个元素包含input和可选的expectedOutput
+	var inputs []*dto.ExecuteInputDto
+	if data := ctx.PostForm("inputs"); data != "" {
+		if err2 := json.Unmarshal([]byte(data), &inputs); err2 != nil {
+			result.Error(e.ErrBadRequest)
+			return
+		}
+	}
 	judgeRequest := &dto.ExecuteRequestDto{
 		Code:      ctx.PostForm("code"),
 		Input:     ctx.PostForm("input"),
 		Language:  constants.LanguageType(ctx.PostForm("language")),
 		ProblemID: uint(utils.AtoiOrDefault(ctx.PostForm("problemID"), 0)),
+		Files:     files,
+		EntryFile: ctx.PostForm("entryFile"),
+		Inputs:    inputs,
 	}
 	// 读取题目id
 	response, err := j.judgeService.Execute(judgeRequest)
@@ -48,10 +69,19 @@ func (j *judgeController) Execute(ctx *gin.Context) {
 func (j *judgeController) Submit(ctx *gin.Context) {
 	result := r.NewResult(ctx)
 	problemID := utils.AtoiOrDefault(ctx.PostForm("problemID"), 0)
+	files, err := getSourceFiles(ctx)
+	if err != nil {
+		result.Error(err)
+		return
+	}
 	judgeRequest := &dto.SubmitRequestDto{
 		Code:      ctx.PostForm("code"),
 		Language:  constants.LanguageType(ctx.PostForm("language")),
 		ProblemID: uint(problemID),
+		Files:     files,
+		EntryFile: ctx.PostForm("entryFile"),
+		// 运行所有用例，获取每个用例的判题结果
+		RunAllCases: ctx.PostForm("runAllCases") == "true",
 	}
 	// 读取题目id
 	response, err := j.judgeService.Submit(ctx, judgeRequest)
@@ -61,3 +91,43 @@ func (j *judgeController) Submit(ctx *gin.Context) {
 	}
 	result.SuccessData(response)
 }
+
+// getSourceFiles 读取多文件提交的文件列表，files为json对象，key为相对路径，value为文件内容
+func getSourceFiles(ctx *gin.Context) (map[string]string, *e.Error) {
+	data := ctx.PostForm("files")
+	if data == "" {
+		return nil, nil
+	}
+	var files map[string]string
+	if err := json.Unmarshal([]byte(data), &files); err != nil {
+		return nil, e.ErrBadRequest
+	}
+	return files, nil
+}
+
+func (j *judgeController) Progress(ctx *gin.Context) {
+	result := r.NewResult(ctx)
+	submissionID := utils.GetIntParamOrDefault(ctx, "id", 0)
+	if submissionID <= 0 {
+		result.Error(e.ErrBadRequest)
+		return
+	}
+	progressCh, cancel, err := j.judgeService.SubscribeProgress(ctx, uint(submissionID))
+	if err != nil {
+		result.Error(err)
+		return
+	}
+	defer cancel()
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case progress, ok := <-progressCh:
+			if !ok {
+				return false
+			}
+			ctx.SSEvent("progress", progress)
+			return true
+		case <-ctx.Request.Context().Done():
+			return false
+		}
+	})
+}
@@ -17,6 +17,8 @@ type SubmissionController interface {
 	GetUserActivityYear(ctx *gin.Context)
 	// GetUserSubmissionList 获取用户提交列表
 	GetUserSubmissionList(ctx *gin.Context)
+	// GetSubmission 获取提交详情
+	GetSubmission(ctx *gin.Context)
 }
 
 func NewSubmissionController(submissionService service.SubmissionService) SubmissionController {
@@ -77,6 +79,21 @@ func (a *submissionController) GetUserSubmissionList(ctx *gin.Context) {
 	result.SuccessData(pageInfo)
 }
 
+func (a *submissionController) GetSubmission(ctx *gin.Context) {
+	result := r.NewResult(ctx)
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id <= 0 {
+		result.Error(e.ErrBadRequest)
+		return
+	}
+	submission, err2 := a.submissionService.GetSubmission(ctx, uint(id))
+	if err2 != nil {
+		result.Error(err2)
+		return
+	}
+	result.SuccessData(submission)
+}
+
 func checkYear(str string) (int, bool) {
 	year, err := strconv.Atoi(str)
 	if err != nil {
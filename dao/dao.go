@@ -8,6 +8,7 @@ var ProviderSet = wire.NewSet(
 	NewProblemDao,
 	NewProblemCaseDao,
 	NewSubmissionDao,
+	NewSubmissionCaseDao,
 	NewSysApiDao,
 	NewSysMenuDao,
 	NewSysRoleDao,
@@ -50,6 +50,21 @@ func (mr *MockProblemAttemptDaoMockRecorder) GetProblemAttemptByID(db, userId, p
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProblemAttemptByID", reflect.TypeOf((*MockProblemAttemptDao)(nil).GetProblemAttemptByID), db, userId, problemId)
 }
 
+// GetProblemAttemptForUpdate mocks base method.
+func (m *MockProblemAttemptDao) GetProblemAttemptForUpdate(db *gorm.DB, userId, problemId uint) (*po.ProblemAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProblemAttemptForUpdate", db, userId, problemId)
+	ret0, _ := ret[0].(*po.ProblemAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProblemAttemptForUpdate indicates an expected call of GetProblemAttemptForUpdate.
+func (mr *MockProblemAttemptDaoMockRecorder) GetProblemAttemptForUpdate(db, userId, problemId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProblemAttemptForUpdate", reflect.TypeOf((*MockProblemAttemptDao)(nil).GetProblemAttemptForUpdate), db, userId, problemId)
+}
+
 // GetProblemAttemptStatus mocks base method.
 func (m *MockProblemAttemptDao) GetProblemAttemptStatus(db *gorm.DB, userId, problemID uint) (int, error) {
 	m.ctrl.T.Helper()
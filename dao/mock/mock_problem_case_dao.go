@@ -0,0 +1,153 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: dao/problem_case_dao.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	dto "FanCode/models/dto"
+	po "FanCode/models/po"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	gorm "gorm.io/gorm"
+)
+
+// MockProblemCaseDao is a mock of ProblemCaseDao interface.
+type MockProblemCaseDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockProblemCaseDaoMockRecorder
+}
+
+// MockProblemCaseDaoMockRecorder is the mock recorder for MockProblemCaseDao.
+type MockProblemCaseDaoMockRecorder struct {
+	mock *MockProblemCaseDao
+}
+
+// NewMockProblemCaseDao creates a new mock instance.
+func NewMockProblemCaseDao(ctrl *gomock.Controller) *MockProblemCaseDao {
+	mock := &MockProblemCaseDao{ctrl: ctrl}
+	mock.recorder = &MockProblemCaseDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProblemCaseDao) EXPECT() *MockProblemCaseDaoMockRecorder {
+	return m.recorder
+}
+
+// DeleteProblemCaseByID mocks base method.
+func (m *MockProblemCaseDao) DeleteProblemCaseByID(db *gorm.DB, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProblemCaseByID", db, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteProblemCaseByID indicates an expected call of DeleteProblemCaseByID.
+func (mr *MockProblemCaseDaoMockRecorder) DeleteProblemCaseByID(db, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProblemCaseByID", reflect.TypeOf((*MockProblemCaseDao)(nil).DeleteProblemCaseByID), db, id)
+}
+
+// DeleteProblemCaseByProblemID mocks base method.
+func (m *MockProblemCaseDao) DeleteProblemCaseByProblemID(db *gorm.DB, problemID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProblemCaseByProblemID", db, problemID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteProblemCaseByProblemID indicates an expected call of DeleteProblemCaseByProblemID.
+func (mr *MockProblemCaseDaoMockRecorder) DeleteProblemCaseByProblemID(db, problemID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProblemCaseByProblemID", reflect.TypeOf((*MockProblemCaseDao)(nil).DeleteProblemCaseByProblemID), db, problemID)
+}
+
+// GetProblemCaseByID mocks base method.
+func (m *MockProblemCaseDao) GetProblemCaseByID(db *gorm.DB, id uint) (*po.ProblemCase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProblemCaseByID", db, id)
+	ret0, _ := ret[0].(*po.ProblemCase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProblemCaseByID indicates an expected call of GetProblemCaseByID.
+func (mr *MockProblemCaseDaoMockRecorder) GetProblemCaseByID(db, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProblemCaseByID", reflect.TypeOf((*MockProblemCaseDao)(nil).GetProblemCaseByID), db, id)
+}
+
+// GetProblemCaseCount mocks base method.
+func (m *MockProblemCaseDao) GetProblemCaseCount(db *gorm.DB, problemCase *po.ProblemCase) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProblemCaseCount", db, problemCase)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProblemCaseCount indicates an expected call of GetProblemCaseCount.
+func (mr *MockProblemCaseDaoMockRecorder) GetProblemCaseCount(db, problemCase interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProblemCaseCount", reflect.TypeOf((*MockProblemCaseDao)(nil).GetProblemCaseCount), db, problemCase)
+}
+
+// GetProblemCaseList mocks base method.
+func (m *MockProblemCaseDao) GetProblemCaseList(db *gorm.DB, query *dto.PageQuery) ([]*po.ProblemCase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProblemCaseList", db, query)
+	ret0, _ := ret[0].([]*po.ProblemCase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProblemCaseList indicates an expected call of GetProblemCaseList.
+func (mr *MockProblemCaseDaoMockRecorder) GetProblemCaseList(db, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProblemCaseList", reflect.TypeOf((*MockProblemCaseDao)(nil).GetProblemCaseList), db, query)
+}
+
+// GetProblemCaseList2 mocks base method.
+func (m *MockProblemCaseDao) GetProblemCaseList2(db *gorm.DB, problemID uint) ([]*po.ProblemCase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProblemCaseList2", db, problemID)
+	ret0, _ := ret[0].([]*po.ProblemCase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProblemCaseList2 indicates an expected call of GetProblemCaseList2.
+func (mr *MockProblemCaseDaoMockRecorder) GetProblemCaseList2(db, problemID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProblemCaseList2", reflect.TypeOf((*MockProblemCaseDao)(nil).GetProblemCaseList2), db, problemID)
+}
+
+// InsertProblemCase mocks base method.
+func (m *MockProblemCaseDao) InsertProblemCase(db *gorm.DB, problemCase *po.ProblemCase) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertProblemCase", db, problemCase)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertProblemCase indicates an expected call of InsertProblemCase.
+func (mr *MockProblemCaseDaoMockRecorder) InsertProblemCase(db, problemCase interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertProblemCase", reflect.TypeOf((*MockProblemCaseDao)(nil).InsertProblemCase), db, problemCase)
+}
+
+// UpdateProblemCase mocks base method.
+func (m *MockProblemCaseDao) UpdateProblemCase(db *gorm.DB, problemCase *po.ProblemCase, subtask *int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProblemCase", db, problemCase, subtask)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProblemCase indicates an expected call of UpdateProblemCase.
+func (mr *MockProblemCaseDaoMockRecorder) UpdateProblemCase(db, problemCase, subtask interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProblemCase", reflect.TypeOf((*MockProblemCaseDao)(nil).UpdateProblemCase), db, problemCase, subtask)
+}
@@ -0,0 +1,79 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: dao/submission_case_dao.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	po "FanCode/models/po"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	gorm "gorm.io/gorm"
+)
+
+// MockSubmissionCaseDao is a mock of SubmissionCaseDao interface.
+type MockSubmissionCaseDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockSubmissionCaseDaoMockRecorder
+}
+
+// MockSubmissionCaseDaoMockRecorder is the mock recorder for MockSubmissionCaseDao.
+type MockSubmissionCaseDaoMockRecorder struct {
+	mock *MockSubmissionCaseDao
+}
+
+// NewMockSubmissionCaseDao creates a new mock instance.
+func NewMockSubmissionCaseDao(ctrl *gomock.Controller) *MockSubmissionCaseDao {
+	mock := &MockSubmissionCaseDao{ctrl: ctrl}
+	mock.recorder = &MockSubmissionCaseDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSubmissionCaseDao) EXPECT() *MockSubmissionCaseDaoMockRecorder {
+	return m.recorder
+}
+
+// DeleteSubmissionCases mocks base method.
+func (m *MockSubmissionCaseDao) DeleteSubmissionCases(db *gorm.DB, submissionID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSubmissionCases", db, submissionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSubmissionCases indicates an expected call of DeleteSubmissionCases.
+func (mr *MockSubmissionCaseDaoMockRecorder) DeleteSubmissionCases(db, submissionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSubmissionCases", reflect.TypeOf((*MockSubmissionCaseDao)(nil).DeleteSubmissionCases), db, submissionID)
+}
+
+// GetSubmissionCaseList mocks base method.
+func (m *MockSubmissionCaseDao) GetSubmissionCaseList(db *gorm.DB, submissionID uint) ([]*po.SubmissionCase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubmissionCaseList", db, submissionID)
+	ret0, _ := ret[0].([]*po.SubmissionCase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubmissionCaseList indicates an expected call of GetSubmissionCaseList.
+func (mr *MockSubmissionCaseDaoMockRecorder) GetSubmissionCaseList(db, submissionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubmissionCaseList", reflect.TypeOf((*MockSubmissionCaseDao)(nil).GetSubmissionCaseList), db, submissionID)
+}
+
+// InsertSubmissionCases mocks base method.
+func (m *MockSubmissionCaseDao) InsertSubmissionCases(db *gorm.DB, cases []*po.SubmissionCase) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertSubmissionCases", db, cases)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertSubmissionCases indicates an expected call of InsertSubmissionCases.
+func (mr *MockSubmissionCaseDaoMockRecorder) InsertSubmissionCases(db, cases interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertSubmissionCases", reflect.TypeOf((*MockSubmissionCaseDao)(nil).InsertSubmissionCases), db, cases)
+}
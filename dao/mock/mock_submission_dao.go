@@ -1,7 +1,7 @@
 // Code generated by MockGen. DO NOT EDIT.
 // Source: dao/submission_dao.go
 
-// Package dao is a generated GoMock package.
+// Package mock is a generated GoMock package.
 package mock
 
 import (
@@ -67,6 +67,36 @@ func (mr *MockSubmissionDaoMockRecorder) GetLastSubmission(db, userID, problemID
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastSubmission", reflect.TypeOf((*MockSubmissionDao)(nil).GetLastSubmission), db, userID, problemID)
 }
 
+// GetRejudgeSubmissions mocks base method.
+func (m *MockSubmissionDao) GetRejudgeSubmissions(db *gorm.DB, query *dto.RejudgeRequestDto) ([]*po.Submission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRejudgeSubmissions", db, query)
+	ret0, _ := ret[0].([]*po.Submission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRejudgeSubmissions indicates an expected call of GetRejudgeSubmissions.
+func (mr *MockSubmissionDaoMockRecorder) GetRejudgeSubmissions(db, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRejudgeSubmissions", reflect.TypeOf((*MockSubmissionDao)(nil).GetRejudgeSubmissions), db, query)
+}
+
+// GetSubmissionByID mocks base method.
+func (m *MockSubmissionDao) GetSubmissionByID(db *gorm.DB, id uint) (*po.Submission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubmissionByID", db, id)
+	ret0, _ := ret[0].(*po.Submission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubmissionByID indicates an expected call of GetSubmissionByID.
+func (mr *MockSubmissionDaoMockRecorder) GetSubmissionByID(db, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubmissionByID", reflect.TypeOf((*MockSubmissionDao)(nil).GetSubmissionByID), db, id)
+}
+
 // GetSubmissionCount mocks base method.
 func (m *MockSubmissionDao) GetSubmissionCount(db *gorm.DB, submission *po.Submission) (int64, error) {
 	m.ctrl.T.Helper()
@@ -97,6 +127,36 @@ func (mr *MockSubmissionDaoMockRecorder) GetSubmissionList(db, pageQuery interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubmissionList", reflect.TypeOf((*MockSubmissionDao)(nil).GetSubmissionList), db, pageQuery)
 }
 
+// GetSubmissionsByStatus mocks base method.
+func (m *MockSubmissionDao) GetSubmissionsByStatus(db *gorm.DB, statuses []int) ([]*po.Submission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubmissionsByStatus", db, statuses)
+	ret0, _ := ret[0].([]*po.Submission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubmissionsByStatus indicates an expected call of GetSubmissionsByStatus.
+func (mr *MockSubmissionDaoMockRecorder) GetSubmissionsByStatus(db, statuses interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubmissionsByStatus", reflect.TypeOf((*MockSubmissionDao)(nil).GetSubmissionsByStatus), db, statuses)
+}
+
+// GetUserProblemSubmissions mocks base method.
+func (m *MockSubmissionDao) GetUserProblemSubmissions(db *gorm.DB, userID, problemID uint) ([]*po.Submission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserProblemSubmissions", db, userID, problemID)
+	ret0, _ := ret[0].([]*po.Submission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserProblemSubmissions indicates an expected call of GetUserProblemSubmissions.
+func (mr *MockSubmissionDaoMockRecorder) GetUserProblemSubmissions(db, userID, problemID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserProblemSubmissions", reflect.TypeOf((*MockSubmissionDao)(nil).GetUserProblemSubmissions), db, userID, problemID)
+}
+
 // GetUserSimpleSubmissionsByTime mocks base method.
 func (m *MockSubmissionDao) GetUserSimpleSubmissionsByTime(db *gorm.DB, userID uint, begin, end time.Time) ([]*po.Submission, error) {
 	m.ctrl.T.Helper()
@@ -125,3 +185,31 @@ func (mr *MockSubmissionDaoMockRecorder) InsertSubmission(db, submission interfa
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertSubmission", reflect.TypeOf((*MockSubmissionDao)(nil).InsertSubmission), db, submission)
 }
+
+// UpdateSubmission mocks base method.
+func (m *MockSubmissionDao) UpdateSubmission(db *gorm.DB, submission *po.Submission) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSubmission", db, submission)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSubmission indicates an expected call of UpdateSubmission.
+func (mr *MockSubmissionDaoMockRecorder) UpdateSubmission(db, submission interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSubmission", reflect.TypeOf((*MockSubmissionDao)(nil).UpdateSubmission), db, submission)
+}
+
+// UpdateSubmissionStatus mocks base method.
+func (m *MockSubmissionDao) UpdateSubmissionStatus(db *gorm.DB, id uint, status int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSubmissionStatus", db, id, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSubmissionStatus indicates an expected call of UpdateSubmissionStatus.
+func (mr *MockSubmissionDaoMockRecorder) UpdateSubmissionStatus(db, id, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSubmissionStatus", reflect.TypeOf((*MockSubmissionDao)(nil).UpdateSubmissionStatus), db, id, status)
+}
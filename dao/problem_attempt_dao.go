@@ -3,6 +3,7 @@ package dao
 import (
 	"FanCode/models/po"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ProblemAttemptDao
@@ -11,6 +12,8 @@ type ProblemAttemptDao interface {
 	InsertProblemAttempt(db *gorm.DB, problemAttempt *po.ProblemAttempt) error
 	UpdateProblemAttempt(db *gorm.DB, problemAttempt *po.ProblemAttempt) error
 	GetProblemAttemptByID(db *gorm.DB, userId uint, problemId uint) (*po.ProblemAttempt, error)
+	// GetProblemAttemptForUpdate 读取做题情况并加行锁，需要在事务中调用，事务结束前其他事务无法修改该记录
+	GetProblemAttemptForUpdate(db *gorm.DB, userId uint, problemId uint) (*po.ProblemAttempt, error)
 	GetProblemAttemptStatus(db *gorm.DB, userId uint, problemID uint) (int, error)
 }
 
@@ -31,7 +34,9 @@ func (p *problemAttemptDao) UpdateProblemAttempt(db *gorm.DB, problemAttempt *po
 		"success_count":    problemAttempt.SuccessCount,
 		"err_count":        problemAttempt.ErrCount,
 		"code":             problemAttempt.Code,
+		"language":         problemAttempt.Language,
 		"status":           problemAttempt.Status,
+		"best_score":       problemAttempt.BestScore,
 		"updated_at":       problemAttempt.UpdatedAt,
 	}).Error
 }
@@ -43,6 +48,13 @@ func (p *problemAttemptDao) GetProblemAttemptByID(db *gorm.DB, userId uint, prob
 	return &problemAttempt, err
 }
 
+func (p *problemAttemptDao) GetProblemAttemptForUpdate(db *gorm.DB, userId uint, problemId uint) (*po.ProblemAttempt, error) {
+	problemAttempt := po.ProblemAttempt{}
+	err := db.Model(&po.ProblemAttempt{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? and problem_id = ?", userId, problemId).First(&problemAttempt).Error
+	return &problemAttempt, err
+}
+
 func (p *problemAttemptDao) GetProblemAttemptStatus(db *gorm.DB, userId uint, problemID uint) (int, error) {
 	var problemAttempt po.ProblemAttempt
 	err := db.Model(&po.ProblemAttempt{}).Select("status", "id").
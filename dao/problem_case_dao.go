@@ -20,8 +20,8 @@ type ProblemCaseDao interface {
 	DeleteProblemCaseByProblemID(db *gorm.DB, problemID uint) error
 	// InsertProblemCase 添加题目用例
 	InsertProblemCase(db *gorm.DB, problemCase *po.ProblemCase) error
-	// UpdateProblemCase 更新题目用例
-	UpdateProblemCase(db *gorm.DB, problemCase *po.ProblemCase) error
+	// UpdateProblemCase 更新题目用例，subtask为nil时不修改子任务
+	UpdateProblemCase(db *gorm.DB, problemCase *po.ProblemCase, subtask *int) error
 }
 
 type problemCaseDao struct {
@@ -90,6 +90,30 @@ func (p *problemCaseDao) InsertProblemCase(db *gorm.DB, problemCase *po.ProblemC
 	return db.Create(problemCase).Error
 }
 
-func (p *problemCaseDao) UpdateProblemCase(db *gorm.DB, problemCase *po.ProblemCase) error {
-	return db.Model(problemCase).Updates(problemCase).Error
+func (p *problemCaseDao) UpdateProblemCase(db *gorm.DB, problemCase *po.ProblemCase, subtask *int) error {
+	if err := db.Model(problemCase).Omit("subtask").Updates(problemCase).Error; err != nil {
+		return err
+	}
+	// 子任务编号可以为0，需要单独更新
+	if subtask != nil {
+		if err := db.Model(problemCase).Update("subtask", *subtask).Error; err != nil {
+			return err
+		}
+	}
+	// 重新上传了数据时预览和长度可以为空，也需要单独更新
+	if problemCase.InputHash != "" {
+		if err := db.Model(problemCase).Updates(map[string]interface{}{
+			"input":      problemCase.Input,
+			"input_size": problemCase.InputSize,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	if problemCase.OutputHash != "" {
+		return db.Model(problemCase).Updates(map[string]interface{}{
+			"output":      problemCase.Output,
+			"output_size": problemCase.OutputSize,
+		}).Error
+	}
+	return nil
 }
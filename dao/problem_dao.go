@@ -83,6 +83,10 @@ func (p *problemDao) GetProblemList(db *gorm.DB, pageQuery *dto.PageQuery) ([]*p
 	if problem != nil && problem.BankID != nil {
 		db = db.Where("bank_id = ?", problem.BankID)
 	}
+	// 没有限制语言的题目支持所有语言
+	if problem != nil && problem.Languages != "" {
+		db = db.Where("(languages IS NULL OR languages = '' OR FIND_IN_SET(?, languages))", problem.Languages)
+	}
 	offset := (pageQuery.Page - 1) * pageQuery.PageSize
 	var problems []*po.Problem
 	db = db.Offset(offset).Limit(pageQuery.PageSize)
@@ -108,6 +112,10 @@ func (p *problemDao) GetProblemCount(db *gorm.DB, problem *po.Problem) (int64, e
 	if problem != nil && problem.BankID != nil {
 		db = db.Where("bank_id = ?", problem.BankID)
 	}
+	// 没有限制语言的题目支持所有语言
+	if problem != nil && problem.Languages != "" {
+		db = db.Where("(languages IS NULL OR languages = '' OR FIND_IN_SET(?, languages))", problem.Languages)
+	}
 	if problem != nil && problem.Enable != 0 {
 		db = db.Where("enable = ?", problem.Enable)
 	}
@@ -121,15 +129,28 @@ func (p *problemDao) InsertProblem(db *gorm.DB, problem *po.Problem) error {
 
 func (p *problemDao) UpdateProblem(db *gorm.DB, problem *po.Problem) error {
 	return db.Model(&po.Problem{}).Where("id = ?", problem.ID).Updates(map[string]interface{}{
-		"updated_at":  problem.UpdatedAt,
-		"bank_id":     problem.BankID,
-		"number":      problem.Number,
-		"name":        problem.Name,
-		"description": problem.Description,
-		"difficulty":  problem.Difficulty,
-		"title":       problem.Title,
-		"languages":   problem.Languages,
-		"enable":      problem.Enable,
+		"updated_at":          problem.UpdatedAt,
+		"bank_id":             problem.BankID,
+		"number":              problem.Number,
+		"name":                problem.Name,
+		"description":         problem.Description,
+		"difficulty":          problem.Difficulty,
+		"title":               problem.Title,
+		"languages":           problem.Languages,
+		"enable":              problem.Enable,
+		"comparator":          problem.Comparator,
+		"epsilon":             problem.Epsilon,
+		"checker":             problem.Checker,
+		"checker_language":    problem.CheckerLanguage,
+		"interactor":          problem.Interactor,
+		"interactor_language": problem.InteractorLanguage,
+		"run_all_cases":       problem.RunAllCases,
+		"subtasks":            problem.Subtasks,
+		"time_limit":          problem.TimeLimit,
+		"memory_limit":        problem.MemoryLimit,
+		"stack_limit":         problem.StackLimit,
+		"limit_factors":       problem.LimitFactors,
+		"function_signature":  problem.FunctionSignature,
 	}).Error
 }
 
@@ -0,0 +1,41 @@
+package dao
+
+import (
+	"FanCode/models/po"
+	"gorm.io/gorm"
+)
+
+// SubmissionCaseDao
+// 记录每次提交中每个用例的判题结果
+type SubmissionCaseDao interface {
+	// InsertSubmissionCases 批量插入用例的判题结果
+	InsertSubmissionCases(db *gorm.DB, cases []*po.SubmissionCase) error
+	// GetSubmissionCaseList 获取一次提交的所有用例的判题结果
+	GetSubmissionCaseList(db *gorm.DB, submissionID uint) ([]*po.SubmissionCase, error)
+	// DeleteSubmissionCases 删除一次提交的所有用例的判题结果
+	DeleteSubmissionCases(db *gorm.DB, submissionID uint) error
+}
+
+type submissionCaseDao struct {
+}
+
+func NewSubmissionCaseDao() SubmissionCaseDao {
+	return &submissionCaseDao{}
+}
+
+func (s *submissionCaseDao) InsertSubmissionCases(db *gorm.DB, cases []*po.SubmissionCase) error {
+	if len(cases) == 0 {
+		return nil
+	}
+	return db.Create(&cases).Error
+}
+
+func (s *submissionCaseDao) GetSubmissionCaseList(db *gorm.DB, submissionID uint) ([]*po.SubmissionCase, error) {
+	var cases []*po.SubmissionCase
+	err := db.Where("submission_id = ?", submissionID).Order("id").Find(&cases).Error
+	return cases, err
+}
+
+func (s *submissionCaseDao) DeleteSubmissionCases(db *gorm.DB, submissionID uint) error {
+	return db.Where("submission_id = ?", submissionID).Delete(&po.SubmissionCase{}).Error
+}
@@ -11,11 +11,20 @@ import (
 // 记录用户每次的提交记录
 type SubmissionDao interface {
 	GetLastSubmission(db *gorm.DB, userID uint, problemID uint) (*po.Submission, error)
+	GetSubmissionByID(db *gorm.DB, id uint) (*po.Submission, error)
 	GetSubmissionList(db *gorm.DB, pageQuery *dto.PageQuery) ([]*po.Submission, error)
 	GetSubmissionCount(db *gorm.DB, submission *po.Submission) (int64, error)
 	GetUserSimpleSubmissionsByTime(db *gorm.DB, userID uint, begin time.Time, end time.Time) ([]*po.Submission, error)
 	CheckUserIsSubmittedByTime(db *gorm.DB, userID uint, begin time.Time, end time.Time) (bool, error)
 	InsertSubmission(db *gorm.DB, submission *po.Submission) error
+	UpdateSubmission(db *gorm.DB, submission *po.Submission) error
+	UpdateSubmissionStatus(db *gorm.DB, id uint, status int) error
+	// GetRejudgeSubmissions 获取需要重判的提交，只使用设置了的条件
+	GetRejudgeSubmissions(db *gorm.DB, query *dto.RejudgeRequestDto) ([]*po.Submission, error)
+	// GetUserProblemSubmissions 获取用户在一道题目中的所有提交的状态和得分
+	GetUserProblemSubmissions(db *gorm.DB, userID uint, problemID uint) ([]*po.Submission, error)
+	// GetSubmissionsByStatus 获取处于这些状态的所有提交
+	GetSubmissionsByStatus(db *gorm.DB, statuses []int) ([]*po.Submission, error)
 }
 
 type submissionDao struct {
@@ -31,6 +40,12 @@ func (s *submissionDao) GetLastSubmission(db *gorm.DB, userID uint, problemID ui
 	return submission, err
 }
 
+func (s *submissionDao) GetSubmissionByID(db *gorm.DB, id uint) (*po.Submission, error) {
+	submission := &po.Submission{}
+	err := db.First(submission, id).Error
+	return submission, err
+}
+
 func (s *submissionDao) GetSubmissionList(db *gorm.DB, pageQuery *dto.PageQuery) ([]*po.Submission, error) {
 	submission := pageQuery.Query.(*po.Submission)
 	var submissions []*po.Submission
@@ -84,3 +99,42 @@ func (s *submissionDao) CheckUserIsSubmittedByTime(db *gorm.DB, userID uint, beg
 func (s *submissionDao) InsertSubmission(db *gorm.DB, submission *po.Submission) error {
 	return db.Create(submission).Error
 }
+
+func (s *submissionDao) UpdateSubmission(db *gorm.DB, submission *po.Submission) error {
+	return db.Save(submission).Error
+}
+
+func (s *submissionDao) UpdateSubmissionStatus(db *gorm.DB, id uint, status int) error {
+	return db.Model(&po.Submission{}).Where("id = ?", id).Update("status", status).Error
+}
+
+func (s *submissionDao) GetRejudgeSubmissions(db *gorm.DB, query *dto.RejudgeRequestDto) ([]*po.Submission, error) {
+	var submissions []*po.Submission
+	if query.ProblemID != 0 {
+		db = db.Where("problem_id = ?", query.ProblemID)
+	}
+	if query.UserID != 0 {
+		db = db.Where("user_id = ?", query.UserID)
+	}
+	if !query.Begin.IsZero() {
+		db = db.Where("created_at >= ?", query.Begin)
+	}
+	if !query.End.IsZero() {
+		db = db.Where("created_at <= ?", query.End)
+	}
+	err := db.Order("id").Find(&submissions).Error
+	return submissions, err
+}
+
+func (s *submissionDao) GetUserProblemSubmissions(db *gorm.DB, userID uint, problemID uint) ([]*po.Submission, error) {
+	var submissions []*po.Submission
+	err := db.Where("user_id = ? and problem_id = ?", userID, problemID).
+		Select("id", "status", "score", "code", "language").Order("id").Find(&submissions).Error
+	return submissions, err
+}
+
+func (s *submissionDao) GetSubmissionsByStatus(db *gorm.DB, statuses []int) ([]*po.Submission, error) {
+	var submissions []*po.Submission
+	err := db.Where("status IN ?", statuses).Order("id").Find(&submissions).Error
+	return submissions, err
+}
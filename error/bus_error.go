@@ -74,6 +74,8 @@ const (
 	CodeExecuteFailed
 	CodeCompileFailed
 	CodeLanguageNotSupported
+	CodeSubmissionNotExist
+	CodeJudgeQueueFull
 )
 
 var (
@@ -81,6 +83,8 @@ var (
 	ErrExecuteFailed        = NewError(CodeExecuteFailed, "Execute error", ErrTypeBus)
 	ErrCompileFailed        = NewError(CodeCompileFailed, "Compilation error", ErrTypeBus)
 	ErrLanguageNotSupported = NewError(CodeLanguageNotSupported, "This language is not supported", ErrTypeBus)
+	ErrSubmissionNotExist   = NewError(CodeSubmissionNotExist, "The submission does not exist", ErrTypeBus)
+	ErrJudgeQueueFull       = NewError(CodeJudgeQueueFull, "The judge queue is full, please try again later", ErrTypeBus)
 )
 
 /************api相关错误**************/
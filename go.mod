@@ -14,6 +14,7 @@ require (
 	github.com/stretchr/testify v1.8.3
 	github.com/tencentyun/cos-go-sdk-v5 v0.7.42
 	golang.org/x/crypto v0.18.0
+	golang.org/x/sys v0.19.0
 	gopkg.in/gomail.v2 v2.0.0-20160411212932-81ebce5c23df
 	gopkg.in/ini.v1 v1.67.0
 	gorm.io/driver/mysql v1.4.7
@@ -59,7 +60,6 @@ require (
 	github.com/ugorji/go/codec v1.2.11 // indirect
 	golang.org/x/arch v0.3.0 // indirect
 	golang.org/x/net v0.20.0 // indirect
-	golang.org/x/sys v0.19.0 // indirect
 	golang.org/x/term v0.19.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
 	google.golang.org/protobuf v1.30.0 // indirect
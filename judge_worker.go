@@ -0,0 +1,26 @@
+package main
+
+import (
+	"FanCode/config"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// runJudgeWorker 运行判题worker，从redis stream中读取提交进行判题，收到退出信号后等待正在判题的提交完成再退出
+func runJudgeWorker(conf *config.AppConfig) error {
+	worker, err := initJudgeWorker(conf)
+	if err != nil {
+		return err
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-signals
+		log.Println("judge worker stopping")
+		worker.Stop()
+	}()
+	log.Println("judge worker started")
+	return worker.Run()
+}
@@ -4,6 +4,8 @@ import (
 	"FanCode/config"
 	"FanCode/global"
 	"FanCode/models/po"
+	"FanCode/service/judger"
+	"flag"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"log"
@@ -22,6 +24,8 @@ func newApp(engine *gin.Engine, config *config.AppConfig) *http.Server {
 
 func main() {
 	//获取参数
+	worker := flag.Bool("worker", false, "以判题worker模式运行，从redis中读取提交进行判题")
+	flag.Parse()
 	path, _ := os.Getwd()
 	path = strings.ReplaceAll(path, "\\", "/")
 	path = path + "/conf/config.ini"
@@ -33,6 +37,12 @@ func main() {
 		return
 	}
 
+	//加载编程语言配置
+	if err := judger.InitLanguageSpecs(conf.Languages); err != nil {
+		fmt.Println("加载编程语言配置出错")
+		return
+	}
+
 	//连接数据库
 	if err := global.InitMysql(conf.MySqlConfig); err != nil {
 		fmt.Println("数据库连接失败")
@@ -54,12 +64,21 @@ func main() {
 		&po.ProblemCase{},
 		&po.ProblemAttempt{},
 		&po.Submission{},
+		&po.SubmissionCase{},
 		&po.UserCode{},
 	)
 	if err != nil {
 		log.Println(err)
 	}
 
+	//判题worker模式不启动http服务
+	if *worker {
+		if err = runJudgeWorker(conf); err != nil {
+			fmt.Println(err)
+		}
+		return
+	}
+
 	//注册路由
 	srv, err := initApp(conf)
 	if err != nil {
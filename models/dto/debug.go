@@ -9,6 +9,10 @@ type StartDebugRequest struct {
 	Key string `json:"key"`
 	// 用户代码
 	Code string `json:"code"`
+	// 多文件调试时的所有文件，key为相对路径，为空时只有Code一个文件
+	Files map[string]string `json:"files"`
+	// 入口文件，为空时使用语言默认的main文件名称
+	EntryFile string `json:"entryFile"`
 	// Language 调试语言
 	Language constants.LanguageType `json:"language"`
 	// 初始断点
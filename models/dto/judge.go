@@ -11,50 +11,133 @@ type SubmitRequestDto struct {
 	ProblemID uint
 	Code      string
 	Language  constants.LanguageType
+	// 多文件提交时的所有文件，key为相对路径，为空时只有Code一个文件
+	Files map[string]string
+	// 入口文件，为空时使用语言默认的main文件名称
+	EntryFile string
+	// 是否运行所有用例，题目设置了运行所有用例时总是运行所有用例
+	RunAllCases bool
+}
+
+// RejudgeRequestDto 重判的条件，只使用设置了的条件
+type RejudgeRequestDto struct {
+	ProblemID uint
+	UserID    uint
+	Begin     time.Time
+	End       time.Time
 }
 
 type SubmitResultDto struct {
+	// 提交id，用于获取每个用例的判题结果
+	SubmissionID uint   `json:"submissionID"`
 	ProblemID    uint   `json:"problemID"`
 	Status       int    `json:"status"`
 	ErrorMessage string `json:"errorMessage"`
+	ExitCode     int    `json:"exitCode"`
+	Signal       string `json:"signal"`
 	CaseName     string `json:"caseName"`
 	CaseData     string `json:"caseData"`
 	// 预期输出
 	ExpectedOutput string `json:"expectedOutput"`
 	// 用户输出
 	UserOutput string `json:"userOutput"`
+	// 特判程序输出的信息
+	CheckerMessage string `json:"checkerMessage"`
 	// 判题使用时间
 	TimeUsed time.Duration `json:"timeUsed"`
 	// 内存使用量（以字节为单位）
 	MemoryUsed int64 `json:"memoryUsed"`
+	// 得分
+	Score int `json:"score"`
 }
 
 func NewSubmitResultDto(submission *po.Submission) *SubmitResultDto {
 	return &SubmitResultDto{
+		SubmissionID:   submission.ID,
 		ProblemID:      submission.ProblemID,
 		Status:         submission.Status,
 		ErrorMessage:   submission.ErrorMessage,
+		ExitCode:       submission.ExitCode,
+		Signal:         submission.Signal,
 		CaseName:       submission.CaseName,
 		CaseData:       submission.CaseData,
 		ExpectedOutput: submission.ExpectedOutput,
 		UserOutput:     submission.UserOutput,
+		CheckerMessage: submission.CheckerMessage,
 		TimeUsed:       submission.TimeUsed,
 		MemoryUsed:     submission.MemoryUsed,
+		Score:          submission.Score,
 	}
 }
 
+// JudgeProgressDto 判题进度
+type JudgeProgressDto struct {
+	SubmissionID uint `json:"submissionID"`
+	// 等待判题、编译中、运行用例中，判题完成时为最终的判题结果
+	Status int `json:"status"`
+	// 已经运行完成的用例数量以及用例总数
+	Case      int `json:"case"`
+	CaseCount int `json:"caseCount"`
+	// 判题完成时的结果
+	Result *SubmitResultDto `json:"result,omitempty"`
+}
+
 // ExecuteRequestDto 执行请求需要的dto
 type ExecuteRequestDto struct {
 	ProblemID uint                   // 题目id
 	Code      string                 // 代码
 	Input     string                 // 自测用例
 	Language  constants.LanguageType // 编程语言
+	Files     map[string]string      // 多文件运行时的所有文件，为空时只有Code一个文件
+	EntryFile string                 // 入口文件，为空时使用语言默认的main文件名称
+	// 多个自测用例，不为空时忽略Input，编译一次后运行所有用例
+	Inputs []*ExecuteInputDto
+}
+
+// ExecuteInputDto 一个自测用例
+type ExecuteInputDto struct {
+	Input string `json:"input"`
+	// 期望输出，为nil时不比较
+	ExpectedOutput *string `json:"expectedOutput"`
 }
 
-// ExecuteResultDto 执行的响应结果
+// ExecuteResultDto 执行的响应结果，Status等字段为第一个用例的运行结果，编译错误时Results为空
 type ExecuteResultDto struct {
 	ProblemID    uint   `json:"problemID"`
 	Status       uint   `json:"status"`
 	ErrorMessage string `json:"errorMessage"`
+	ExitCode     int    `json:"exitCode"`
+	Signal       string `json:"signal"`
 	UserOutput   string `json:"userOutput"` //用户输出
+	// 输出超出限制时，UserOutput为截断后的输出
+	OutputTruncated bool `json:"outputTruncated"`
+	// 每个自测用例的运行结果
+	Results []*ExecuteCaseResultDto `json:"results"`
+}
+
+// ExecuteCaseResultDto 一个自测用例的运行结果
+type ExecuteCaseResultDto struct {
+	Status       uint   `json:"status"`
+	ErrorMessage string `json:"errorMessage"`
+	ExitCode     int    `json:"exitCode"`
+	Signal       string `json:"signal"`
+	UserOutput   string `json:"userOutput"`
+	// 输出超出限制时，UserOutput为截断后的输出
+	OutputTruncated bool `json:"outputTruncated"`
+	// 标准错误输出
+	ErrorOutput string `json:"errorOutput"`
+	// cpu使用时间以及内存使用峰值（以字节为单位）
+	TimeUsed   time.Duration `json:"timeUsed"`
+	MemoryUsed int64         `json:"memoryUsed"`
+	// 设置了期望输出时和期望输出的比较结果，取值为Accepted、WrongAnswer、PresentationError
+	Verdict int `json:"verdict,omitempty"`
+	// 不通过时第一个不同的行
+	Diff *ExecuteDiffDto `json:"diff,omitempty"`
+}
+
+// ExecuteDiffDto 用户输出和期望输出第一个不同的行，行号从1开始，没有该行时为空字符串
+type ExecuteDiffDto struct {
+	Line     int    `json:"line"`
+	Output   string `json:"output"`
+	Expected string `json:"expected"`
 }
@@ -18,19 +18,34 @@ type ProblemDtoForGet struct {
 	// 支持的语言用,分割
 	Languages string `json:"languages"`
 	Enable    int    `json:"enable"`
+	// 每种语言的时间、内存和栈大小限制
+	Limits []*ProblemLimitDto `json:"limits"`
+	// 核心代码模式的函数签名，为空时为acm模式
+	FunctionSignature string `json:"functionSignature"`
+}
+
+// ProblemLimitDto 题目在一种语言下的限制
+type ProblemLimitDto struct {
+	Language string `json:"language"`
+	// 时间限制（以毫秒为单位）
+	TimeLimit int64 `json:"timeLimit"`
+	// 内存和栈大小限制（以MB为单位）
+	MemoryLimit int64 `json:"memoryLimit"`
+	StackLimit  int64 `json:"stackLimit"`
 }
 
 func NewProblemDtoForGet(problem *po.Problem) *ProblemDtoForGet {
 	response := &ProblemDtoForGet{
-		ID:          problem.ID,
-		BankID:      problem.BankID,
-		Name:        problem.Name,
-		Number:      problem.Number,
-		Description: problem.Description,
-		Title:       problem.Title,
-		Difficulty:  problem.Difficulty,
-		Languages:   problem.Languages,
-		Enable:      problem.Enable,
+		ID:                problem.ID,
+		BankID:            problem.BankID,
+		Name:              problem.Name,
+		Number:            problem.Number,
+		Description:       problem.Description,
+		Title:             problem.Title,
+		Difficulty:        problem.Difficulty,
+		Languages:         problem.Languages,
+		Enable:            problem.Enable,
+		FunctionSignature: problem.FunctionSignature,
 	}
 	return response
 }
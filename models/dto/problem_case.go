@@ -5,36 +5,57 @@ import (
 	"FanCode/utils"
 )
 
+// ProblemCaseDtoForList 输入和期望输出只有开头的一部分，InputSize和OutputSize为完整数据的长度
 type ProblemCaseDtoForList struct {
-	ID        uint       `json:"id"`
-	Name      string     `json:"name"`
-	Input     string     `json:"input"`
-	Output    string     `json:"output"`
-	CreatedAt utils.Time `json:"createdAt"`
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Input      string     `json:"input"`
+	Output     string     `json:"output"`
+	InputSize  int64      `json:"inputSize"`
+	OutputSize int64      `json:"outputSize"`
+	Subtask    int        `json:"subtask"`
+	CreatedAt  utils.Time `json:"createdAt"`
 }
 
 func NewProblemCaseDtoForList(problemCase *po.ProblemCase) *ProblemCaseDtoForList {
 	return &ProblemCaseDtoForList{
-		ID:        problemCase.ID,
-		Name:      problemCase.Name,
-		Input:     problemCase.Input,
-		Output:    problemCase.Output,
-		CreatedAt: utils.Time(problemCase.CreatedAt),
+		ID:         problemCase.ID,
+		Name:       problemCase.Name,
+		Input:      problemCase.Input,
+		Output:     problemCase.Output,
+		InputSize:  getCaseDataSize(problemCase.InputHash, problemCase.InputSize, problemCase.Input),
+		OutputSize: getCaseDataSize(problemCase.OutputHash, problemCase.OutputSize, problemCase.Output),
+		Subtask:    problemCase.Subtask,
+		CreatedAt:  utils.Time(problemCase.CreatedAt),
 	}
 }
 
 type ProblemCaseDtoForGet struct {
-	ID     uint   `json:"id"`
-	Name   string `json:"name"`
-	Input  string `json:"input"`
-	Output string `json:"output"`
+	ID         uint   `json:"id"`
+	Name       string `json:"name"`
+	Input      string `json:"input"`
+	Output     string `json:"output"`
+	InputSize  int64  `json:"inputSize"`
+	OutputSize int64  `json:"outputSize"`
+	Subtask    int    `json:"subtask"`
 }
 
 func NewProblemCaseDtoForGet(problemCase *po.ProblemCase) *ProblemCaseDtoForGet {
 	return &ProblemCaseDtoForGet{
-		ID:     problemCase.ID,
-		Name:   problemCase.Name,
-		Input:  problemCase.Input,
-		Output: problemCase.Output,
+		ID:         problemCase.ID,
+		Name:       problemCase.Name,
+		Input:      problemCase.Input,
+		Output:     problemCase.Output,
+		InputSize:  getCaseDataSize(problemCase.InputHash, problemCase.InputSize, problemCase.Input),
+		OutputSize: getCaseDataSize(problemCase.OutputHash, problemCase.OutputSize, problemCase.Output),
+		Subtask:    problemCase.Subtask,
 	}
 }
+
+// getCaseDataSize 没有保存在对象存储中的用例，数据库中为完整的数据
+func getCaseDataSize(hash string, size int64, data string) int64 {
+	if hash == "" {
+		return int64(len(data))
+	}
+	return size
+}
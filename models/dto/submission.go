@@ -1,14 +1,18 @@
 package dto
 
 import (
+	"FanCode/constants"
 	"FanCode/models/po"
 	"FanCode/utils"
+	"encoding/json"
+	"time"
 )
 
 type SubmissionDtoForList struct {
 	ID           uint       `json:"id"`
 	ProblemName  string     `json:"problemName"`
 	Status       int        `json:"status"`
+	Score        int        `json:"score"`
 	ErrorMessage string     `json:"errorMessage"`
 	CreatedAt    utils.Time `json:"createdAt"`
 }
@@ -17,7 +21,96 @@ func NewSubmissionDtoForList(submission *po.Submission) *SubmissionDtoForList {
 	return &SubmissionDtoForList{
 		ID:           submission.ID,
 		Status:       submission.Status,
+		Score:        submission.Score,
 		ErrorMessage: submission.ErrorMessage,
 		CreatedAt:    utils.Time(submission.CreatedAt),
 	}
 }
+
+// SubmissionDetailDto 提交详情，包括每个用例的判题结果
+type SubmissionDetailDto struct {
+	ID          uint   `json:"id"`
+	ProblemID   uint   `json:"problemID"`
+	ProblemName string `json:"problemName"`
+	Language    string `json:"language"`
+	Code        string `json:"code"`
+	// 多文件提交时的所有文件和入口文件
+	Files        map[string]string `json:"files,omitempty"`
+	EntryFile    string            `json:"entryFile,omitempty"`
+	Status       int               `json:"status"`
+	Score        int               `json:"score"`
+	ErrorMessage string            `json:"errorMessage"`
+	TimeUsed     time.Duration     `json:"timeUsed"`
+	MemoryUsed   int64             `json:"memoryUsed"`
+	CreatedAt    utils.Time        `json:"createdAt"`
+	// 重判前的状态和得分，没有重判过时rejudgedAt为空
+	PreviousStatus int         `json:"previousStatus"`
+	PreviousScore  int         `json:"previousScore"`
+	RejudgedAt     *utils.Time `json:"rejudgedAt"`
+	// 运行的用例数量以及通过的用例数量
+	CaseCount   int                  `json:"caseCount"`
+	PassedCount int                  `json:"passedCount"`
+	Cases       []*SubmissionCaseDto `json:"cases"`
+}
+
+func NewSubmissionDetailDto(submission *po.Submission, cases []*po.SubmissionCase) *SubmissionDetailDto {
+	response := &SubmissionDetailDto{
+		ID:           submission.ID,
+		ProblemID:    submission.ProblemID,
+		Language:     submission.Language,
+		Code:         submission.Code,
+		EntryFile:    submission.EntryFile,
+		Status:       submission.Status,
+		Score:        submission.Score,
+		ErrorMessage: submission.ErrorMessage,
+		TimeUsed:     submission.TimeUsed,
+		MemoryUsed:   submission.MemoryUsed,
+		CreatedAt:    utils.Time(submission.CreatedAt),
+		CaseCount:    len(cases),
+		Cases:        make([]*SubmissionCaseDto, len(cases)),
+	}
+	if submission.Files != "" {
+		if err := json.Unmarshal([]byte(submission.Files), &response.Files); err != nil {
+			response.Files = nil
+		}
+	}
+	if submission.RejudgedAt != nil {
+		rejudgedAt := utils.Time(*submission.RejudgedAt)
+		response.PreviousStatus = submission.PreviousStatus
+		response.PreviousScore = submission.PreviousScore
+		response.RejudgedAt = &rejudgedAt
+	}
+	for i, c := range cases {
+		response.Cases[i] = NewSubmissionCaseDto(c)
+		if c.Status == constants.Accepted {
+			response.PassedCount++
+		}
+	}
+	return response
+}
+
+// SubmissionCaseDto 一个用例的判题结果
+type SubmissionCaseDto struct {
+	CaseID     uint          `json:"caseID"`
+	CaseName   string        `json:"caseName"`
+	Subtask    int           `json:"subtask"`
+	Status     int           `json:"status"`
+	TimeUsed   time.Duration `json:"timeUsed"`
+	MemoryUsed int64         `json:"memoryUsed"`
+	// 截断后的用户输出
+	UserOutput string `json:"userOutput"`
+	Message    string `json:"message"`
+}
+
+func NewSubmissionCaseDto(submissionCase *po.SubmissionCase) *SubmissionCaseDto {
+	return &SubmissionCaseDto{
+		CaseID:     submissionCase.CaseID,
+		CaseName:   submissionCase.CaseName,
+		Subtask:    submissionCase.Subtask,
+		Status:     submissionCase.Status,
+		TimeUsed:   submissionCase.TimeUsed,
+		MemoryUsed: submissionCase.MemoryUsed,
+		UserOutput: submissionCase.UserOutput,
+		Message:    submissionCase.Message,
+	}
+}
@@ -19,4 +19,46 @@ type Problem struct {
 	Languages string `gorm:"column:languages" json:"languages"`
 	// 所属题库id
 	BankID *uint `gorm:"column:bank_id" json:"bankID"`
+	// 比较用户输出和期望输出的方式，取值为constants中的ComparatorType，为空时忽略首尾的空格和换行
+	Comparator string `gorm:"column:comparator" json:"comparator"`
+	// 浮点数比较允许的绝对误差或者相对误差，为0时使用默认值1e-6
+	Epsilon float64 `gorm:"column:epsilon" json:"epsilon"`
+	// 特判程序代码，为空时按照Comparator比较用户输出和期望输出
+	Checker string `gorm:"column:checker;type:text" json:"checker"`
+	// 特判程序使用的编程语言
+	CheckerLanguage string `gorm:"column:checker_language" json:"checkerLanguage"`
+	// 交互程序代码，不为空时为交互题，用户程序和交互程序交互完成判题，忽略Checker和Comparator
+	Interactor string `gorm:"column:interactor;type:text" json:"interactor"`
+	// 交互程序使用的编程语言
+	InteractorLanguage string `gorm:"column:interactor_language" json:"interactorLanguage"`
+	// 是否运行所有用例，为false时遇到第一个不通过的用例就结束判题
+	RunAllCases bool `gorm:"column:run_all_cases" json:"runAllCases"`
+	// 子任务列表的json，为空时通过所有用例得满分，否则按照通过的子任务计算得分
+	Subtasks string `gorm:"column:subtasks;type:text" json:"subtasks"`
+	// 时间限制（以毫秒为单位），为0时使用默认值
+	TimeLimit int64 `gorm:"column:time_limit" json:"timeLimit"`
+	// 内存限制（以MB为单位），为0时使用默认值
+	MemoryLimit int64 `gorm:"column:memory_limit" json:"memoryLimit"`
+	// 栈大小限制（以MB为单位），为0时和内存限制相同
+	StackLimit int64 `gorm:"column:stack_limit" json:"stackLimit"`
+	// 每种语言的时间和内存限制倍数的json，没有设置的语言使用语言配置中的倍数
+	LimitFactors string `gorm:"column:limit_factors;type:text" json:"limitFactors"`
+	// 函数签名的json，不为空时为核心代码模式，用户只需要实现该函数，判题时生成读取输入和输出返回值的代码
+	FunctionSignature string `gorm:"column:function_signature;type:text" json:"functionSignature"`
+}
+
+// LimitFactor 一种语言的时间和内存限制倍数，为0时使用语言配置中的倍数
+type LimitFactor struct {
+	Time   float64 `json:"time"`
+	Memory float64 `json:"memory"`
+}
+
+// Subtask 题目的子任务，用例通过Subtask字段指定所属的子任务
+type Subtask struct {
+	// 子任务编号，从1开始
+	ID int `json:"id"`
+	// 子任务的分值，子任务中所有用例都通过并且依赖的子任务都通过时得分
+	Score int `json:"score"`
+	// 依赖的子任务编号，只能依赖编号更小的子任务
+	Dependencies []int `json:"dependencies"`
 }
@@ -15,4 +15,6 @@ type ProblemAttempt struct {
 	Language string `gorm:"column:language"`
 	// 0 未开始，1进行中 2 提交成功
 	Status int `gorm:"column:status"`
+	// 所有提交中的最高得分
+	BestScore int `gorm:"column:best_score"`
 }
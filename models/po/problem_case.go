@@ -3,11 +3,18 @@ package po
 import "gorm.io/gorm"
 
 // ProblemCase
-// 表示一道题目的一个用例
+// 表示一道题目的一个用例，输入和期望输出保存在对象存储中，通过sha256引用，
+// Input和Output只保存开头的一部分用于预览。没有保存在对象存储中的旧用例的Input和Output为完整的数据
 type ProblemCase struct {
 	gorm.Model
-	ProblemID uint   `gorm:"column:problem_id" json:"problemID"`
-	Name      string `gorm:"column:name"`
-	Input     string `gorm:"column:input"`
-	Output    string `gorm:"column:output"`
+	ProblemID  uint   `gorm:"column:problem_id" json:"problemID"`
+	Name       string `gorm:"column:name"`
+	Input      string `gorm:"column:input"`
+	Output     string `gorm:"column:output"`
+	InputHash  string `gorm:"column:input_hash" json:"inputHash"`
+	InputSize  int64  `gorm:"column:input_size" json:"inputSize"`
+	OutputHash string `gorm:"column:output_hash" json:"outputHash"`
+	OutputSize int64  `gorm:"column:output_size" json:"outputSize"`
+	// 所属的子任务编号，为0时不属于任何子任务
+	Subtask int `gorm:"column:subtask" json:"subtask"`
 }
@@ -13,12 +13,20 @@ type Submission struct {
 	ProblemID uint `gorm:"column:problem_id"`
 	// 使用的编程语言
 	Language string `gorm:"column:language"`
-	// 用户代码
+	// 用户代码，多文件提交时为入口文件的代码
 	Code string `gorm:"column:code"`
+	// 多文件提交时所有文件的json，key为相对路径，单文件提交时为空
+	Files string `gorm:"column:files;type:text"`
+	// 多文件提交时的入口文件
+	EntryFile string `gorm:"column:entry_file"`
 	// 状态
 	Status int `gorm:"column:status"`
 	// 异常信息
 	ErrorMessage string `gorm:"column:error_message"`
+	// 程序退出码，被信号终止时为-1
+	ExitCode int `gorm:"column:exit_code"`
+	// 终止程序的信号名称
+	Signal string `gorm:"column:signal"`
 	// 用例名称
 	CaseName string `gorm:"column:case_name"`
 	// 用例数据
@@ -27,7 +35,18 @@ type Submission struct {
 	ExpectedOutput string `gorm:"column:expected_output"`
 	// 用户输出
 	UserOutput string        `gorm:"user_output"`
-	TimeUsed   time.Duration // 判题使用时间
-	MemoryUsed int64         // 内存使用量（以字节为单位）
+	TimeUsed   time.Duration // 所有用例中最大的cpu使用时间
+	MemoryUsed int64         // 所有用例中最大的内存使用峰值（以字节为单位）
 
+	// 特判程序输出的信息
+	CheckerMessage string `gorm:"column:checker_message"`
+	// 得分，没有设置子任务的题目通过时为满分
+	Score int `gorm:"column:score"`
+	// 重判前的状态和得分，用于审计
+	PreviousStatus int `gorm:"column:previous_status"`
+	PreviousScore  int `gorm:"column:previous_score"`
+	// 最近一次重判的时间
+	RejudgedAt *time.Time `gorm:"column:rejudged_at"`
+	// 每个用例的判题结果，保存在单独的表中
+	Cases []*SubmissionCase `gorm:"-"`
 }
@@ -0,0 +1,29 @@
+package po
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// SubmissionCase
+// 表示一次提交中一个用例的判题结果
+type SubmissionCase struct {
+	gorm.Model
+	// 提交id
+	SubmissionID uint `gorm:"column:submission_id;index"`
+	// 用例id以及用例名称
+	CaseID   uint   `gorm:"column:case_id"`
+	CaseName string `gorm:"column:case_name"`
+	// 用例所属的子任务编号
+	Subtask int `gorm:"column:subtask"`
+	// 判题结果
+	Status int `gorm:"column:status"`
+	// cpu使用时间
+	TimeUsed time.Duration `gorm:"column:time_used"`
+	// 内存使用峰值（以字节为单位）
+	MemoryUsed int64 `gorm:"column:memory_used"`
+	// 用户输出，超过长度限制时截断
+	UserOutput string `gorm:"column:user_output;type:text"`
+	// 异常信息，或者特判程序、交互程序输出的信息
+	Message string `gorm:"column:message;type:text"`
+}
@@ -0,0 +1,16 @@
+package admin
+
+import (
+	"FanCode/controller/admin"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupJudgeRoutes(r *gin.Engine, judgeController admin.JudgeManagementController) {
+	//重判相关路由
+	rejudge := r.Group("/manage/judge/rejudge")
+	{
+		rejudge.POST("/problem/:id", judgeController.RejudgeProblem)
+		rejudge.POST("/user/:id", judgeController.RejudgeUser)
+		rejudge.POST("/time", judgeController.RejudgeByTime)
+	}
+}
@@ -51,6 +51,7 @@ func SetupRouter(
 	admin.SetupProblemBankRoutes(r, controller.ProblemBankManagementController)
 	admin.SetupProblemRoutes(r, controller.ProblemManagementController)
 	admin.SetupProblemCaseRoutes(r, controller.ProblemCaseManagementController)
+	admin.SetupJudgeRoutes(r, controller.JudgeManagementController)
 	user.SetupJudgeRoutes(r, controller.JudgeController)
 	user.SetupDebugRoutes(r, controller.DebugController)
 	user.SetupProblemRoutes(r, controller.ProblemController)
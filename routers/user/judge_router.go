@@ -11,5 +11,6 @@ func SetupJudgeRoutes(r *gin.Engine, judgeController user.JudgeController) {
 	{
 		judge.POST("/submit", judgeController.Submit)
 		judge.POST("/execute", judgeController.Execute)
+		judge.GET("/progress/:id", judgeController.Progress)
 	}
 }
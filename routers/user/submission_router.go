@@ -12,5 +12,6 @@ func SetupSubmissionRoutes(r *gin.Engine, submissionController user.SubmissionCo
 		submission.GET("/active/year", submissionController.GetUserActivityYear)
 		submission.GET("/active/map/:year", submissionController.GetUserActivityMap)
 		submission.GET("/list", submissionController.GetUserSubmissionList)
+		submission.GET("/:id", submissionController.GetSubmission)
 	}
 }
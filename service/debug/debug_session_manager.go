@@ -4,6 +4,8 @@ import (
 	"FanCode/constants"
 	de "FanCode/service/debug/debugger"
 	"FanCode/service/debug/debugger/gdb_debugger"
+	"FanCode/service/judger"
+	"fmt"
 	"log"
 )
 
@@ -30,10 +32,17 @@ func (d *debugSessionManage) CreateDebugSession(key string, language constants.L
 		}()
 		channel <- data
 	}
+	// 根据语言配置的调试器类型创建调试器
+	spec, ok := judger.GetLanguageSpec(language)
+	if !ok {
+		return fmt.Errorf("language %s is not supported", language)
+	}
 	var debugger de.Debugger
-	switch language {
-	case constants.LanguageC:
+	switch spec.Debugger {
+	case constants.GdbCore:
 		debugger = gdb_debugger.NewGdbDebugger(notificationCallback)
+	default:
+		return fmt.Errorf("language %s does not support debugging", language)
 	}
 	d.debugContextMap[key] = &DebugSession{
 		StopProcessDebuggerEventChan: make(chan struct{}, 2),
@@ -16,7 +16,6 @@ import (
 	"log"
 	"net/http"
 	"os"
-	"path"
 )
 
 // DebugService
@@ -115,10 +114,12 @@ func (d *debugService) Start(ctx *gin.Context, startReq dto.StartDebugRequest) *
 		return e.ErrUnknown
 	}
 	// 保存用户代码到用户的执行路径，并获取编译文件列表
+	sources, err2 := newSourceFiles(startReq.Language, startReq.Code, startReq.Files, startReq.EntryFile)
+	if err2 != nil {
+		return err2
+	}
 	var compileFiles []string
-	var err2 *e.Error
-	if compileFiles, err2 = d.saveUserCode(startReq.Language,
-		startReq.Code, executePath); err2 != nil {
+	if compileFiles, err2 = d.saveUserCode(sources, executePath); err2 != nil {
 		return e.ErrUnknown
 	}
 
@@ -367,21 +368,12 @@ func (d *debugService) GetVariables(key string, reference string) ([]*debugger.V
 
 // saveUserCode
 // 保存用户代码到用户的executePath，并返回需要编译的文件列表
-func (d *debugService) saveUserCode(language constants.LanguageType, codeStr string, executePath string) ([]string, *e.Error) {
-	var compileFiles []string
-	var mainFile string
-	var err2 *e.Error
-
-	if mainFile, err2 = getMainFileNameByLanguage(language); err2 != nil {
-		log.Println(err2)
-		return nil, err2
-	}
-	if err := os.WriteFile(path.Join(executePath, mainFile), []byte(codeStr), 0644); err != nil {
+func (d *debugService) saveUserCode(sources *sourceFiles, executePath string) ([]string, *e.Error) {
+	// 头文件等不需要编译的文件只保存到执行目录
+	compileFiles, _, err := sources.save(executePath)
+	if err != nil {
 		log.Println(err)
 		return nil, e.ErrServer
 	}
-	// 将main文件进行编译即可
-	compileFiles = []string{path.Join(executePath, mainFile)}
-
 	return compileFiles, nil
 }
@@ -0,0 +1,369 @@
+package harness
+
+import (
+	"fmt"
+	"strings"
+)
+
+// cGenerator 和LeetCode一致，数组参数后面有表示长度的参数，二维数组还有表示每一行长度的参数，
+// 返回数组时通过returnSize和returnColumnSizes返回长度
+type cGenerator struct{}
+
+var cBaseTypes = map[string]string{
+	TypeInt:    "int",
+	TypeLong:   "long long",
+	TypeDouble: "double",
+	TypeBool:   "bool",
+	TypeString: "char*",
+}
+
+// cTypeName 比如int[][]为int**
+func cTypeName(t valueType) string {
+	return cBaseTypes[t.base] + strings.Repeat("*", t.dims)
+}
+
+// cFuncName 读取和输出函数名称中的类型，比如int[][]为int_matrix
+func cFuncName(t valueType) string {
+	switch t.dims {
+	case 1:
+		return t.base + "_array"
+	case 2:
+		return t.base + "_matrix"
+	}
+	return t.base
+}
+
+func (cGenerator) stub(signature *Signature) string {
+	var params []string
+	for i, t := range signature.params() {
+		name := signature.Params[i].Name
+		params = append(params, cTypeName(t)+" "+name)
+		if t.dims != 0 {
+			params = append(params, "int "+name+"Size")
+		}
+		if t.dims == 2 {
+			params = append(params, "int* "+name+"ColSize")
+		}
+	}
+	returnType := signature.returnType()
+	var comment string
+	switch returnType.dims {
+	case 1:
+		params = append(params, "int* returnSize")
+		comment = "/**\n * 返回的数组必须使用malloc分配，数组长度保存在*returnSize中\n */\n"
+	case 2:
+		params = append(params, "int* returnSize", "int** returnColumnSizes")
+		comment = "/**\n * 返回的数组和*returnColumnSizes必须使用malloc分配，" +
+			"数组长度保存在*returnSize中，每一行的长度保存在*returnColumnSizes中\n */\n"
+	}
+	return fmt.Sprintf("%s%s %s(%s) {\n\n}\n", comment, cTypeName(returnType), signature.Name,
+		strings.Join(params, ", "))
+}
+
+func (cGenerator) generate(signature *Signature, mainFile string, code string) []*SourceFile {
+	var main strings.Builder
+	main.WriteString("int main(void) {\n    harness_read_all();\n")
+	var args []string
+	for i, t := range signature.params() {
+		arg := argName(i)
+		switch t.dims {
+		case 0:
+			fmt.Fprintf(&main, "    %s %s = harness_read_%s();\n", cTypeName(t), arg, cFuncName(t))
+			args = append(args, arg)
+		case 1:
+			fmt.Fprintf(&main, "    int %sSize;\n    %s %s = harness_read_%s(&%sSize);\n", arg, cTypeName(t), arg,
+				cFuncName(t), arg)
+			args = append(args, arg, arg+"Size")
+		case 2:
+			fmt.Fprintf(&main, "    int %sSize;\n    int* %sColSize;\n    %s %s = harness_read_%s(&%sSize, &%sColSize);\n",
+				arg, arg, cTypeName(t), arg, cFuncName(t), arg, arg)
+			args = append(args, arg, arg+"Size", arg+"ColSize")
+		}
+	}
+	returnType := signature.returnType()
+	switch returnType.dims {
+	case 0:
+		fmt.Fprintf(&main, "    %s harness_result = %s(%s);\n    harness_print_%s(harness_result);\n",
+			cTypeName(returnType), signature.Name, strings.Join(args, ", "), cFuncName(returnType))
+	case 1:
+		args = append(args, "&harness_returnSize")
+		fmt.Fprintf(&main, "    int harness_returnSize = 0;\n    %s harness_result = %s(%s);\n"+
+			"    harness_print_%s(harness_result, harness_returnSize);\n",
+			cTypeName(returnType), signature.Name, strings.Join(args, ", "), cFuncName(returnType))
+	case 2:
+		args = append(args, "&harness_returnSize", "&harness_returnColumnSizes")
+		fmt.Fprintf(&main, "    int harness_returnSize = 0;\n    int* harness_returnColumnSizes = NULL;\n"+
+			"    %s harness_result = %s(%s);\n"+
+			"    harness_print_%s(harness_result, harness_returnSize, harness_returnColumnSizes);\n",
+			cTypeName(returnType), signature.Name, strings.Join(args, ", "), cFuncName(returnType))
+	}
+	main.WriteString("    putchar('\\n');\n    return 0;\n}\n")
+	return []*SourceFile{{
+		Name: mainFile,
+		Code: cPrelude + code + "\n" + cRuntime + main.String(),
+	}}
+}
+
+// cPrelude 放在用户代码前面的头文件
+const cPrelude = `#include <ctype.h>
+#include <limits.h>
+#include <stdbool.h>
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+`
+
+// cRuntime 读取输入和输出返回值的函数，所有输入读入内存后依次解析
+const cRuntime = `
+static char *harness_buf;
+static size_t harness_len, harness_pos;
+
+static void harness_fail(void) {
+    fprintf(stderr, "invalid input at %zu\n", harness_pos);
+    exit(1);
+}
+
+static void harness_read_all(void) {
+    size_t cap = 1 << 16, n;
+    harness_buf = malloc(cap + 1);
+    while ((n = fread(harness_buf + harness_len, 1, cap - harness_len, stdin)) > 0) {
+        harness_len += n;
+        if (harness_len == cap) {
+            cap *= 2;
+            harness_buf = realloc(harness_buf, cap + 1);
+        }
+    }
+    harness_buf[harness_len] = '\0';
+}
+
+static void harness_skip(void) {
+    while (harness_pos < harness_len && isspace((unsigned char)harness_buf[harness_pos])) {
+        harness_pos++;
+    }
+}
+
+static void harness_expect(char c) {
+    harness_skip();
+    if (harness_pos >= harness_len || harness_buf[harness_pos] != c) {
+        harness_fail();
+    }
+    harness_pos++;
+}
+
+static long long harness_read_long(void) {
+    char *end;
+    long long v;
+    harness_skip();
+    v = strtoll(harness_buf + harness_pos, &end, 10);
+    if (end == harness_buf + harness_pos) {
+        harness_fail();
+    }
+    harness_pos = end - harness_buf;
+    return v;
+}
+
+static int harness_read_int(void) {
+    return (int)harness_read_long();
+}
+
+static double harness_read_double(void) {
+    char *end;
+    double v;
+    harness_skip();
+    v = strtod(harness_buf + harness_pos, &end);
+    if (end == harness_buf + harness_pos) {
+        harness_fail();
+    }
+    harness_pos = end - harness_buf;
+    return v;
+}
+
+static bool harness_read_bool(void) {
+    harness_skip();
+    if (strncmp(harness_buf + harness_pos, "true", 4) == 0) {
+        harness_pos += 4;
+        return true;
+    }
+    if (strncmp(harness_buf + harness_pos, "false", 5) == 0) {
+        harness_pos += 5;
+        return false;
+    }
+    harness_fail();
+    return false;
+}
+
+static char *harness_read_string(void) {
+    char *s;
+    size_t n = 0;
+    harness_expect('"');
+    s = malloc(harness_len - harness_pos + 1);
+    while (harness_pos < harness_len && harness_buf[harness_pos] != '"') {
+        char c = harness_buf[harness_pos++];
+        if (c == '\\' && harness_pos < harness_len) {
+            c = harness_buf[harness_pos++];
+            switch (c) {
+            case 'n': c = '\n'; break;
+            case 't': c = '\t'; break;
+            case 'r': c = '\r'; break;
+            case 'b': c = '\b'; break;
+            case 'f': c = '\f'; break;
+            case 'u': {
+                unsigned int u = 0;
+                int i;
+                for (i = 0; i < 4 && harness_pos < harness_len; i++) {
+                    char h = harness_buf[harness_pos++];
+                    u = u * 16 + (isdigit((unsigned char)h) ? h - '0' : (tolower((unsigned char)h) - 'a' + 10));
+                }
+                if (u < 0x80) {
+                    s[n++] = (char)u;
+                } else if (u < 0x800) {
+                    s[n++] = (char)(0xC0 | (u >> 6));
+                    s[n++] = (char)(0x80 | (u & 0x3F));
+                } else {
+                    s[n++] = (char)(0xE0 | (u >> 12));
+                    s[n++] = (char)(0x80 | ((u >> 6) & 0x3F));
+                    s[n++] = (char)(0x80 | (u & 0x3F));
+                }
+                continue;
+            }
+            default: break;
+            }
+        }
+        s[n++] = c;
+    }
+    harness_expect('"');
+    s[n] = '\0';
+    return s;
+}
+
+#define HARNESS_READ_ARRAY(name, T, read)                       \
+    static T *harness_read_##name##_array(int *size) {          \
+        int cap = 16, n = 0;                                    \
+        T *a = malloc(sizeof(T) * cap);                         \
+        harness_expect('[');                                    \
+        harness_skip();                                         \
+        if (harness_buf[harness_pos] == ']') {                  \
+            harness_pos++;                                      \
+            *size = 0;                                          \
+            return a;                                           \
+        }                                                       \
+        for (;;) {                                              \
+            if (n == cap) {                                     \
+                cap *= 2;                                       \
+                a = realloc(a, sizeof(T) * cap);                \
+            }                                                   \
+            a[n++] = read();                                    \
+            harness_skip();                                     \
+            if (harness_buf[harness_pos] != ',') {              \
+                break;                                          \
+            }                                                   \
+            harness_pos++;                                      \
+        }                                                       \
+        harness_expect(']');                                    \
+        *size = n;                                              \
+        return a;                                               \
+    }                                                           \
+    static T **harness_read_##name##_matrix(int *size, int **colSize) { \
+        int cap = 16, n = 0;                                    \
+        T **a = malloc(sizeof(T *) * cap);                      \
+        int *cols = malloc(sizeof(int) * cap);                  \
+        harness_expect('[');                                    \
+        harness_skip();                                         \
+        if (harness_buf[harness_pos] != ']') {                  \
+            for (;;) {                                          \
+                if (n == cap) {                                 \
+                    cap *= 2;                                   \
+                    a = realloc(a, sizeof(T *) * cap);          \
+                    cols = realloc(cols, sizeof(int) * cap);    \
+                }                                               \
+                a[n] = harness_read_##name##_array(&cols[n]);   \
+                n++;                                            \
+                harness_skip();                                 \
+                if (harness_buf[harness_pos] != ',') {          \
+                    break;                                      \
+                }                                               \
+                harness_pos++;                                  \
+            }                                                   \
+        }                                                       \
+        harness_expect(']');                                    \
+        *size = n;                                              \
+        *colSize = cols;                                        \
+        return a;                                               \
+    }
+
+HARNESS_READ_ARRAY(int, int, harness_read_int)
+HARNESS_READ_ARRAY(long, long long, harness_read_long)
+HARNESS_READ_ARRAY(double, double, harness_read_double)
+HARNESS_READ_ARRAY(bool, bool, harness_read_bool)
+HARNESS_READ_ARRAY(string, char *, harness_read_string)
+
+static void harness_print_int(int v) {
+    printf("%d", v);
+}
+
+static void harness_print_long(long long v) {
+    printf("%lld", v);
+}
+
+static void harness_print_double(double v) {
+    printf("%.5f", v);
+}
+
+static void harness_print_bool(bool v) {
+    fputs(v ? "true" : "false", stdout);
+}
+
+static void harness_print_string(const char *s) {
+    putchar('"');
+    for (; s != NULL && *s != '\0'; s++) {
+        unsigned char c = (unsigned char)*s;
+        if (c == '"' || c == '\\') {
+            putchar('\\');
+            putchar(c);
+        } else if (c == '\n') {
+            fputs("\\n", stdout);
+        } else if (c == '\r') {
+            fputs("\\r", stdout);
+        } else if (c == '\t') {
+            fputs("\\t", stdout);
+        } else if (c < 0x20) {
+            printf("\\u%04x", c);
+        } else {
+            putchar(c);
+        }
+    }
+    putchar('"');
+}
+
+#define HARNESS_PRINT_ARRAY(name, T)                                        \
+    static void harness_print_##name##_array(T *a, int n) {                 \
+        int i;                                                              \
+        putchar('[');                                                       \
+        for (i = 0; i < n; i++) {                                           \
+            if (i != 0) {                                                   \
+                putchar(',');                                               \
+            }                                                               \
+            harness_print_##name(a[i]);                                     \
+        }                                                                   \
+        putchar(']');                                                       \
+    }                                                                       \
+    static void harness_print_##name##_matrix(T **a, int n, int *cols) {    \
+        int i;                                                              \
+        putchar('[');                                                       \
+        for (i = 0; i < n; i++) {                                           \
+            if (i != 0) {                                                   \
+                putchar(',');                                               \
+            }                                                               \
+            harness_print_##name##_array(a[i], cols == NULL ? 0 : cols[i]); \
+        }                                                                   \
+        putchar(']');                                                       \
+    }
+
+HARNESS_PRINT_ARRAY(int, int)
+HARNESS_PRINT_ARRAY(long, long long)
+HARNESS_PRINT_ARRAY(double, double)
+HARNESS_PRINT_ARRAY(bool, bool)
+HARNESS_PRINT_ARRAY(string, char *)
+
+`
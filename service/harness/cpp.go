@@ -0,0 +1,242 @@
+package harness
+
+import (
+	"fmt"
+	"strings"
+)
+
+// cppGenerator 和LeetCode一致，用户实现Solution类中的成员函数，数组使用vector
+type cppGenerator struct{}
+
+var cppBaseTypes = map[string]string{
+	TypeInt:    "int",
+	TypeLong:   "long long",
+	TypeDouble: "double",
+	TypeBool:   "bool",
+	TypeString: "string",
+}
+
+// cppTypeName 比如int[][]为vector<vector<int>>
+func cppTypeName(t valueType) string {
+	name := cppBaseTypes[t.base]
+	for i := 0; i < t.dims; i++ {
+		name = "vector<" + name + ">"
+	}
+	return name
+}
+
+func (cppGenerator) stub(signature *Signature) string {
+	var params []string
+	for i, t := range signature.params() {
+		// 数组和字符串使用引用传递
+		name := cppTypeName(t)
+		if t.dims != 0 || t.base == TypeString {
+			name += "&"
+		}
+		params = append(params, name+" "+signature.Params[i].Name)
+	}
+	return fmt.Sprintf("class Solution {\npublic:\n    %s %s(%s) {\n\n    }\n};\n",
+		cppTypeName(signature.returnType()), signature.Name, strings.Join(params, ", "))
+}
+
+func (cppGenerator) generate(signature *Signature, mainFile string, code string) []*SourceFile {
+	var main strings.Builder
+	main.WriteString("int main() {\n    harness_read_all();\n")
+	var args []string
+	for i, t := range signature.params() {
+		arg := argName(i)
+		fmt.Fprintf(&main, "    %s %s;\n    harness_read(%s);\n", cppTypeName(t), arg, arg)
+		args = append(args, arg)
+	}
+	fmt.Fprintf(&main, "    Solution harness_solution;\n    %s harness_result = harness_solution.%s(%s);\n",
+		cppTypeName(signature.returnType()), signature.Name, strings.Join(args, ", "))
+	main.WriteString("    harness_print(harness_result);\n    putchar('\\n');\n    return 0;\n}\n")
+	return []*SourceFile{{
+		Name: mainFile,
+		Code: cppPrelude + code + "\n" + cppRuntime + main.String(),
+	}}
+}
+
+// cppPrelude 放在用户代码前面的头文件
+const cppPrelude = `#include <bits/stdc++.h>
+using namespace std;
+
+`
+
+// cppRuntime 读取输入和输出返回值的函数，通过重载支持不同的类型
+const cppRuntime = `
+static string harness_buf;
+static size_t harness_pos;
+
+static void harness_fail() {
+    fprintf(stderr, "invalid input at %zu\n", harness_pos);
+    exit(1);
+}
+
+static void harness_read_all() {
+    harness_buf.assign(istreambuf_iterator<char>(cin), istreambuf_iterator<char>());
+}
+
+static void harness_skip() {
+    while (harness_pos < harness_buf.size() && isspace((unsigned char)harness_buf[harness_pos])) {
+        harness_pos++;
+    }
+}
+
+static void harness_expect(char c) {
+    harness_skip();
+    if (harness_pos >= harness_buf.size() || harness_buf[harness_pos] != c) {
+        harness_fail();
+    }
+    harness_pos++;
+}
+
+static void harness_read(long long &v) {
+    harness_skip();
+    const char *begin = harness_buf.c_str() + harness_pos;
+    char *end;
+    v = strtoll(begin, &end, 10);
+    if (end == begin) {
+        harness_fail();
+    }
+    harness_pos += end - begin;
+}
+
+static void harness_read(int &v) {
+    long long x;
+    harness_read(x);
+    v = (int)x;
+}
+
+static void harness_read(double &v) {
+    harness_skip();
+    const char *begin = harness_buf.c_str() + harness_pos;
+    char *end;
+    v = strtod(begin, &end);
+    if (end == begin) {
+        harness_fail();
+    }
+    harness_pos += end - begin;
+}
+
+static void harness_read(bool &v) {
+    harness_skip();
+    if (harness_buf.compare(harness_pos, 4, "true") == 0) {
+        harness_pos += 4;
+        v = true;
+    } else if (harness_buf.compare(harness_pos, 5, "false") == 0) {
+        harness_pos += 5;
+        v = false;
+    } else {
+        harness_fail();
+    }
+}
+
+static void harness_read(string &v) {
+    harness_expect('"');
+    v.clear();
+    while (harness_pos < harness_buf.size() && harness_buf[harness_pos] != '"') {
+        char c = harness_buf[harness_pos++];
+        if (c == '\\' && harness_pos < harness_buf.size()) {
+            c = harness_buf[harness_pos++];
+            switch (c) {
+            case 'n': c = '\n'; break;
+            case 't': c = '\t'; break;
+            case 'r': c = '\r'; break;
+            case 'b': c = '\b'; break;
+            case 'f': c = '\f'; break;
+            case 'u': {
+                unsigned int u = stoul(harness_buf.substr(harness_pos, 4), nullptr, 16);
+                harness_pos += 4;
+                if (u < 0x80) {
+                    v += (char)u;
+                } else if (u < 0x800) {
+                    v += (char)(0xC0 | (u >> 6));
+                    v += (char)(0x80 | (u & 0x3F));
+                } else {
+                    v += (char)(0xE0 | (u >> 12));
+                    v += (char)(0x80 | ((u >> 6) & 0x3F));
+                    v += (char)(0x80 | (u & 0x3F));
+                }
+                continue;
+            }
+            default: break;
+            }
+        }
+        v += c;
+    }
+    harness_expect('"');
+}
+
+template <typename T>
+static void harness_read(vector<T> &v) {
+    v.clear();
+    harness_expect('[');
+    harness_skip();
+    if (harness_pos < harness_buf.size() && harness_buf[harness_pos] == ']') {
+        harness_pos++;
+        return;
+    }
+    for (;;) {
+        T x;
+        harness_read(x);
+        v.push_back(x);
+        harness_skip();
+        if (harness_pos >= harness_buf.size() || harness_buf[harness_pos] != ',') {
+            break;
+        }
+        harness_pos++;
+    }
+    harness_expect(']');
+}
+
+static void harness_print(int v) {
+    printf("%d", v);
+}
+
+static void harness_print(long long v) {
+    printf("%lld", v);
+}
+
+static void harness_print(double v) {
+    printf("%.5f", v);
+}
+
+static void harness_print(bool v) {
+    fputs(v ? "true" : "false", stdout);
+}
+
+static void harness_print(const string &s) {
+    putchar('"');
+    for (unsigned char c : s) {
+        if (c == '"' || c == '\\') {
+            putchar('\\');
+            putchar(c);
+        } else if (c == '\n') {
+            fputs("\\n", stdout);
+        } else if (c == '\r') {
+            fputs("\\r", stdout);
+        } else if (c == '\t') {
+            fputs("\\t", stdout);
+        } else if (c < 0x20) {
+            printf("\\u%04x", c);
+        } else {
+            putchar(c);
+        }
+    }
+    putchar('"');
+}
+
+template <typename T>
+static void harness_print(const vector<T> &v) {
+    putchar('[');
+    for (size_t i = 0; i < v.size(); i++) {
+        if (i != 0) {
+            putchar(',');
+        }
+        harness_print((T)v[i]);
+    }
+    putchar(']');
+}
+
+`
@@ -0,0 +1,119 @@
+package harness
+
+import (
+	"fmt"
+	"strings"
+)
+
+// goGenerator 用户在main包中实现一个函数，读取输入和输出返回值的代码放在单独的文件中，
+// 不会和用户代码中的import冲突
+type goGenerator struct{}
+
+// goHarnessFile 生成的main函数所在的文件
+const goHarnessFile = "harness.go"
+
+var goBaseTypes = map[string]string{
+	TypeInt:    "int",
+	TypeLong:   "int64",
+	TypeDouble: "float64",
+	TypeBool:   "bool",
+	TypeString: "string",
+}
+
+// goTypeName 比如int[][]为[][]int
+func goTypeName(t valueType) string {
+	return strings.Repeat("[]", t.dims) + goBaseTypes[t.base]
+}
+
+func (goGenerator) stub(signature *Signature) string {
+	var params []string
+	for i, t := range signature.params() {
+		params = append(params, signature.Params[i].Name+" "+goTypeName(t))
+	}
+	return fmt.Sprintf("package main\n\nfunc %s(%s) %s {\n\n}\n", signature.Name,
+		strings.Join(params, ", "), goTypeName(signature.returnType()))
+}
+
+func (goGenerator) generate(signature *Signature, mainFile string, code string) []*SourceFile {
+	var main strings.Builder
+	main.WriteString(goRuntime)
+	main.WriteString("func main() {\n\tharness_decoder := json.NewDecoder(bufio.NewReader(os.Stdin))\n")
+	var args []string
+	for i, t := range signature.params() {
+		arg := argName(i)
+		fmt.Fprintf(&main, "\tvar %s %s\n\tharness_read(harness_decoder, &%s)\n", arg, goTypeName(t), arg)
+		args = append(args, arg)
+	}
+	fmt.Fprintf(&main, "\tharness_result := %s(%s)\n", signature.Name, strings.Join(args, ", "))
+	fmt.Fprintf(&main, "\tharness_out := bufio.NewWriter(os.Stdout)\n"+
+		"\tharness_print(harness_out, reflect.ValueOf(harness_result), \"%s\")\n"+
+		"\tharness_out.WriteByte('\\n')\n\tharness_out.Flush()\n}\n", signature.returnType())
+	return []*SourceFile{
+		{Name: mainFile, Code: code},
+		{Name: goHarnessFile, Code: main.String()},
+	}
+}
+
+// goRuntime 使用encoding/json读取输入，通过反射根据签名中的类型输出返回值
+const goRuntime = `package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+func harness_read(decoder *json.Decoder, v interface{}) {
+	if err := decoder.Decode(v); err != nil {
+		fmt.Fprintln(os.Stderr, "invalid input:", err)
+		os.Exit(1)
+	}
+}
+
+func harness_print(out *bufio.Writer, v reflect.Value, typ string) {
+	if strings.HasSuffix(typ, "[]") {
+		out.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i != 0 {
+				out.WriteByte(',')
+			}
+			harness_print(out, v.Index(i), typ[:len(typ)-2])
+		}
+		out.WriteByte(']')
+		return
+	}
+	switch typ {
+	case "double":
+		out.WriteString(strconv.FormatFloat(v.Float(), 'f', 5, 64))
+	case "bool":
+		out.WriteString(strconv.FormatBool(v.Bool()))
+	case "string":
+		out.WriteByte('"')
+		for _, c := range v.String() {
+			switch {
+			case c == '"' || c == '\\':
+				out.WriteByte('\\')
+				out.WriteRune(c)
+			case c == '\n':
+				out.WriteString("\\n")
+			case c == '\r':
+				out.WriteString("\\r")
+			case c == '\t':
+				out.WriteString("\\t")
+			case c < 0x20:
+				fmt.Fprintf(out, "\\u%04x", c)
+			default:
+				out.WriteRune(c)
+			}
+		}
+		out.WriteByte('"')
+	default:
+		out.WriteString(strconv.FormatInt(v.Int(), 10))
+	}
+}
+
+`
@@ -0,0 +1,59 @@
+package harness
+
+import (
+	"FanCode/constants"
+	"fmt"
+)
+
+// SourceFile 生成的源文件，第一个文件为main文件
+type SourceFile struct {
+	Name string
+	Code string
+}
+
+// generator 一种语言的代码生成方式
+type generator interface {
+	// stub 用户需要实现的函数的模板代码
+	stub(signature *Signature) string
+	// generate 在用户代码的基础上生成完整的程序，读取输入、调用用户的函数并输出返回值
+	generate(signature *Signature, mainFile string, code string) []*SourceFile
+}
+
+var generators = map[constants.LanguageType]generator{
+	constants.LanguageC:          cGenerator{},
+	constants.LanguageCpp:        cppGenerator{},
+	constants.LanguageJava:       javaGenerator{},
+	constants.LanguagePython:     pythonGenerator{},
+	constants.LanguageJavaScript: javascriptGenerator{},
+	constants.LanguageGo:         goGenerator{},
+}
+
+// Supported 是否支持为该语言生成代码
+func Supported(language constants.LanguageType) bool {
+	_, ok := generators[language]
+	return ok
+}
+
+// Stub 根据函数签名生成用户需要实现的函数的模板代码
+func Stub(language constants.LanguageType, signature *Signature) (string, error) {
+	g, ok := generators[language]
+	if !ok {
+		return "", fmt.Errorf("语言%s不支持核心代码模式", language)
+	}
+	return g.stub(signature), nil
+}
+
+// Generate 把用户实现的函数和读取输入、输出返回值的代码组合成完整的程序，mainFile为语言的main文件名称
+func Generate(language constants.LanguageType, signature *Signature, mainFile string,
+	code string) ([]*SourceFile, error) {
+	g, ok := generators[language]
+	if !ok {
+		return nil, fmt.Errorf("语言%s不支持核心代码模式", language)
+	}
+	return g.generate(signature, mainFile, code), nil
+}
+
+// argName 生成的代码中保存第i个参数的变量名称
+func argName(i int) string {
+	return fmt.Sprintf("harness_arg%d", i)
+}
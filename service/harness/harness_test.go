@@ -0,0 +1,294 @@
+package harness
+
+import (
+	"FanCode/constants"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSignature(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		ok   bool
+	}{
+		{"valid", `{"name":"twoSum","params":[{"name":"nums","type":"int[]"},{"name":"target","type":"int"}],"returnType":"int[]"}`, true},
+		{"no params", `{"name":"answer","params":[],"returnType":"long"}`, true},
+		{"matrix", `{"name":"transpose","params":[{"name":"grid","type":"double[][]"}],"returnType":"double[][]"}`, true},
+		{"invalid json", `{"name":`, false},
+		{"invalid name", `{"name":"two sum","params":[],"returnType":"int"}`, false},
+		{"keyword", `{"name":"solve","params":[{"name":"class","type":"int"}],"returnType":"int"}`, false},
+		{"reserved prefix", `{"name":"solve","params":[{"name":"harness_x","type":"int"}],"returnType":"int"}`, false},
+		{"unknown type", `{"name":"solve","params":[{"name":"x","type":"char"}],"returnType":"int"}`, false},
+		{"too many dims", `{"name":"solve","params":[{"name":"x","type":"int[][][]"}],"returnType":"int"}`, false},
+		{"duplicate", `{"name":"solve","params":[{"name":"x","type":"int"},{"name":"x","type":"long"}],"returnType":"int"}`, false},
+		{"size collision", `{"name":"solve","params":[{"name":"nums","type":"int[]"},{"name":"numsSize","type":"int"}],"returnType":"int"}`, false},
+		{"nil param", `{"name":"solve","params":[null],"returnType":"int"}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSignature(tt.data)
+			assert.Equal(t, tt.ok, err == nil, err)
+		})
+	}
+}
+
+func TestStubUnsupported(t *testing.T) {
+	signature, err := ParseSignature(`{"name":"solve","params":[],"returnType":"int"}`)
+	require.NoError(t, err)
+	_, err = Stub("rust", signature)
+	assert.Error(t, err)
+	assert.False(t, Supported("rust"))
+	assert.True(t, Supported(constants.LanguageGo))
+}
+
+// harnessLanguage 测试中编译和运行生成的代码的方式
+type harnessLanguage struct {
+	language constants.LanguageType
+	mainFile string
+	tool     string
+	compile  func(dir string, files []string) []string
+	run      func(dir string) []string
+}
+
+var harnessLanguages = []harnessLanguage{
+	{constants.LanguageC, "main.c", "gcc",
+		func(dir string, files []string) []string {
+			return append([]string{"gcc", "-o", path.Join(dir, "main")}, files...)
+		},
+		func(dir string) []string { return []string{path.Join(dir, "main")} }},
+	{constants.LanguageCpp, "main.cpp", "g++",
+		func(dir string, files []string) []string {
+			return append([]string{"g++", "-std=c++17", "-o", path.Join(dir, "main")}, files...)
+		},
+		func(dir string) []string { return []string{path.Join(dir, "main")} }},
+	{constants.LanguageJava, "Main.java", "javac",
+		func(dir string, files []string) []string { return append([]string{"javac", "-d", dir}, files...) },
+		func(dir string) []string { return []string{"java", "-cp", dir, "Main"} }},
+	{constants.LanguagePython, "main.py", "python3", nil,
+		func(dir string) []string { return []string{"python3", path.Join(dir, "main.py")} }},
+	{constants.LanguageJavaScript, "main.js", "node", nil,
+		func(dir string) []string { return []string{"node", path.Join(dir, "main.js")} }},
+	{constants.LanguageGo, "main.go", "go",
+		func(dir string, files []string) []string {
+			return append([]string{"go", "build", "-o", path.Join(dir, "main")}, files...)
+		},
+		func(dir string) []string { return []string{path.Join(dir, "main")} }},
+}
+
+// runHarness 生成完整的程序，编译后使用input运行，返回输出
+func runHarness(t *testing.T, lang harnessLanguage, signature *Signature, code string, input string) string {
+	files, err := Generate(lang.language, signature, lang.mainFile, code)
+	require.NoError(t, err)
+	require.Equal(t, lang.mainFile, files[0].Name)
+	dir := t.TempDir()
+	var paths []string
+	for _, file := range files {
+		p := path.Join(dir, file.Name)
+		require.NoError(t, os.WriteFile(p, []byte(file.Code), 0644))
+		paths = append(paths, p)
+	}
+	if lang.compile != nil {
+		args := lang.compile(dir, paths)
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = dir
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+	args := lang.run(dir)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(input)
+	output, err := cmd.Output()
+	require.NoError(t, err)
+	return string(output)
+}
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name      string
+		signature string
+		codes     map[constants.LanguageType]string
+		input     string
+		output    string
+	}{
+		{
+			name:      "two sum",
+			signature: `{"name":"twoSum","params":[{"name":"nums","type":"int[]"},{"name":"target","type":"int"}],"returnType":"int[]"}`,
+			codes: map[constants.LanguageType]string{
+				constants.LanguageC: `int* twoSum(int* nums, int numsSize, int target, int* returnSize) {
+    int* result = malloc(sizeof(int) * 2);
+    *returnSize = 0;
+    for (int i = 0; i < numsSize; i++)
+        for (int j = i + 1; j < numsSize; j++)
+            if (nums[i] + nums[j] == target) {
+                result[0] = i;
+                result[1] = j;
+                *returnSize = 2;
+                return result;
+            }
+    return result;
+}`,
+				constants.LanguageCpp: `class Solution {
+public:
+    vector<int> twoSum(vector<int>& nums, int target) {
+        for (int i = 0; i < nums.size(); i++)
+            for (int j = i + 1; j < nums.size(); j++)
+                if (nums[i] + nums[j] == target) return {i, j};
+        return {};
+    }
+};`,
+				constants.LanguageJava: `class Solution {
+    public int[] twoSum(int[] nums, int target) {
+        for (int i = 0; i < nums.length; i++)
+            for (int j = i + 1; j < nums.length; j++)
+                if (nums[i] + nums[j] == target) return new int[]{i, j};
+        return new int[0];
+    }
+}`,
+				constants.LanguagePython: `class Solution:
+    def twoSum(self, nums: List[int], target: int) -> List[int]:
+        for i in range(len(nums)):
+            for j in range(i + 1, len(nums)):
+                if nums[i] + nums[j] == target:
+                    return [i, j]
+        return []`,
+				constants.LanguageJavaScript: `var twoSum = function(nums, target) {
+    for (let i = 0; i < nums.length; i++)
+        for (let j = i + 1; j < nums.length; j++)
+            if (nums[i] + nums[j] === target) return [i, j];
+    return [];
+};`,
+				constants.LanguageGo: `package main
+
+func twoSum(nums []int, target int) []int {
+	for i := range nums {
+		for j := i + 1; j < len(nums); j++ {
+			if nums[i]+nums[j] == target {
+				return []int{i, j}
+			}
+		}
+	}
+	return nil
+}`,
+			},
+			input:  "[2, 7, 11, 15]\n9\n",
+			output: "[0,1]\n",
+		},
+		{
+			name:      "matrix and string",
+			signature: `{"name":"describe","params":[{"name":"grid","type":"double[][]"},{"name":"label","type":"string"},{"name":"flag","type":"bool"}],"returnType":"string[]"}`,
+			codes: map[constants.LanguageType]string{
+				constants.LanguageC: `char** describe(double** grid, int gridSize, int* gridColSize, char* label, bool flag, int* returnSize) {
+    char** result = malloc(sizeof(char*) * 2);
+    result[0] = malloc(64);
+    sprintf(result[0], "%d:%.2f", gridSize * gridColSize[0], grid[gridSize - 1][gridColSize[0] - 1]);
+    result[1] = flag ? label : "";
+    *returnSize = 2;
+    return result;
+}`,
+				constants.LanguageCpp: `class Solution {
+public:
+    vector<string> describe(vector<vector<double>>& grid, string& label, bool flag) {
+        char buf[64];
+        sprintf(buf, "%d:%.2f", (int)(grid.size() * grid[0].size()), grid.back().back());
+        return {buf, flag ? label : ""};
+    }
+};`,
+				constants.LanguageJava: `class Solution {
+    public String[] describe(double[][] grid, String label, boolean flag) {
+        double last = grid[grid.length - 1][grid[0].length - 1];
+        return new String[]{String.format("%d:%.2f", grid.length * grid[0].length, last), flag ? label : ""};
+    }
+}`,
+				constants.LanguagePython: `class Solution:
+    def describe(self, grid: List[List[float]], label: str, flag: bool) -> List[str]:
+        return ["%d:%.2f" % (len(grid) * len(grid[0]), grid[-1][-1]), label if flag else ""]`,
+				constants.LanguageJavaScript: `var describe = function(grid, label, flag) {
+    const last = grid[grid.length - 1];
+    return [(grid.length * grid[0].length) + ":" + last[last.length - 1].toFixed(2), flag ? label : ""];
+};`,
+				constants.LanguageGo: `package main
+
+import "fmt"
+
+func describe(grid [][]float64, label string, flag bool) []string {
+	last := grid[len(grid)-1]
+	result := []string{fmt.Sprintf("%d:%.2f", len(grid)*len(grid[0]), last[len(last)-1]), ""}
+	if flag {
+		result[1] = label
+	}
+	return result
+}`,
+			},
+			input:  "[[1, 2.5], [3, 4.25]]\n\"a \\\"b\\\"\\n\\\\c\"\ntrue\n",
+			output: "[\"4:4.25\",\"a \\\"b\\\"\\n\\\\c\"]\n",
+		},
+		{
+			name:      "double and long",
+			signature: `{"name":"scale","params":[{"name":"values","type":"long[]"},{"name":"factor","type":"double"}],"returnType":"double"}`,
+			codes: map[constants.LanguageType]string{
+				constants.LanguageC: `double scale(long long* values, int valuesSize, double factor) {
+    long long sum = 0;
+    for (int i = 0; i < valuesSize; i++) sum += values[i];
+    return sum * factor;
+}`,
+				constants.LanguageCpp: `class Solution {
+public:
+    double scale(vector<long long>& values, double factor) {
+        long long sum = 0;
+        for (long long v : values) sum += v;
+        return sum * factor;
+    }
+};`,
+				constants.LanguageJava: `class Solution {
+    public double scale(long[] values, double factor) {
+        long sum = 0;
+        for (long v : values) sum += v;
+        return sum * factor;
+    }
+}`,
+				constants.LanguagePython: `class Solution:
+    def scale(self, values: List[int], factor: float) -> float:
+        return sum(values) * factor`,
+				constants.LanguageJavaScript: `var scale = function(values, factor) {
+    return values.reduce((a, b) => a + b, 0) * factor;
+};`,
+				constants.LanguageGo: `package main
+
+func scale(values []int64, factor float64) float64 {
+	var sum int64
+	for _, v := range values {
+		sum += v
+	}
+	return float64(sum) * factor
+}`,
+			},
+			input:  "[10000000000, 2]\n0.5\n",
+			output: "5000000001.00000\n",
+		},
+	}
+	for _, lang := range harnessLanguages {
+		lang := lang
+		t.Run(string(lang.language), func(t *testing.T) {
+			if _, err := exec.LookPath(lang.tool); err != nil {
+				t.Skipf("%s not found", lang.tool)
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					signature, err := ParseSignature(tt.signature)
+					require.NoError(t, err)
+					stub, err := Stub(lang.language, signature)
+					require.NoError(t, err)
+					assert.Contains(t, stub, signature.Name)
+					assert.Equal(t, tt.output, runHarness(t, lang, signature, tt.codes[lang.language], tt.input))
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package harness
+
+import (
+	"fmt"
+	"strings"
+)
+
+// javaGenerator 和LeetCode一致，用户实现Solution类中的方法，Solution类和main类放在同一个文件中，
+// 所以Solution类不能是public的
+type javaGenerator struct{}
+
+var javaBaseTypes = map[string]string{
+	TypeInt:    "int",
+	TypeLong:   "long",
+	TypeDouble: "double",
+	TypeBool:   "boolean",
+	TypeString: "String",
+}
+
+// javaTypeName 比如int[][]为int[][]，bool[]为boolean[]
+func javaTypeName(t valueType) string {
+	return javaBaseTypes[t.base] + strings.Repeat("[]", t.dims)
+}
+
+func (javaGenerator) stub(signature *Signature) string {
+	var params []string
+	for i, t := range signature.params() {
+		params = append(params, javaTypeName(t)+" "+signature.Params[i].Name)
+	}
+	return fmt.Sprintf("class Solution {\n    public %s %s(%s) {\n\n    }\n}\n",
+		javaTypeName(signature.returnType()), signature.Name, strings.Join(params, ", "))
+}
+
+func (javaGenerator) generate(signature *Signature, mainFile string, code string) []*SourceFile {
+	// main类的名称和main文件名称相同
+	className := strings.TrimSuffix(mainFile, ".java")
+	var main strings.Builder
+	fmt.Fprintf(&main, "public class %s {\n", className)
+	main.WriteString(javaRuntime)
+	main.WriteString("    public static void main(String[] args) throws Exception {\n" +
+		"        harnessBuf = new String(System.in.readAllBytes(), java.nio.charset.StandardCharsets.UTF_8);\n")
+	var args []string
+	for i, t := range signature.params() {
+		arg := argName(i)
+		fmt.Fprintf(&main, "        %s %s = (%s) harnessRead(\"%s\");\n", javaTypeName(t), arg, javaTypeName(t), t)
+		args = append(args, arg)
+	}
+	returnType := signature.returnType()
+	fmt.Fprintf(&main, "        %s harnessResult = new Solution().%s(%s);\n", javaTypeName(returnType),
+		signature.Name, strings.Join(args, ", "))
+	// 输出使用utf8编码，不依赖系统默认的编码
+	fmt.Fprintf(&main, "        StringBuilder harnessOut = new StringBuilder();\n"+
+		"        harnessPrint(harnessOut, harnessResult, \"%s\");\n"+
+		"        System.out.write(harnessOut.append('\\n').toString().getBytes(java.nio.charset.StandardCharsets.UTF_8));\n"+
+		"        System.out.flush();\n    }\n}\n", returnType)
+	return []*SourceFile{{
+		Name: mainFile,
+		Code: code + "\n\n" + main.String(),
+	}}
+}
+
+// javaRuntime main类中读取输入和输出返回值的方法，根据签名中的类型通过反射创建数组，
+// 生成的代码中只使用完整的类名，不依赖用户代码中的import
+const javaRuntime = `    private static String harnessBuf;
+    private static int harnessPos;
+
+    private static RuntimeException harnessFail() {
+        System.err.println("invalid input at " + harnessPos);
+        System.exit(1);
+        return new RuntimeException();
+    }
+
+    private static void harnessSkip() {
+        while (harnessPos < harnessBuf.length() && Character.isWhitespace(harnessBuf.charAt(harnessPos))) {
+            harnessPos++;
+        }
+    }
+
+    private static void harnessExpect(char c) {
+        harnessSkip();
+        if (harnessPos >= harnessBuf.length() || harnessBuf.charAt(harnessPos) != c) {
+            throw harnessFail();
+        }
+        harnessPos++;
+    }
+
+    private static String harnessToken() {
+        harnessSkip();
+        int begin = harnessPos;
+        while (harnessPos < harnessBuf.length() && "-+.eE0123456789truefalsn".indexOf(harnessBuf.charAt(harnessPos)) >= 0) {
+            harnessPos++;
+        }
+        if (begin == harnessPos) {
+            throw harnessFail();
+        }
+        return harnessBuf.substring(begin, harnessPos);
+    }
+
+    private static String harnessReadString() {
+        harnessExpect('"');
+        StringBuilder s = new StringBuilder();
+        while (harnessPos < harnessBuf.length() && harnessBuf.charAt(harnessPos) != '"') {
+            char c = harnessBuf.charAt(harnessPos++);
+            if (c == '\\' && harnessPos < harnessBuf.length()) {
+                c = harnessBuf.charAt(harnessPos++);
+                switch (c) {
+                    case 'n': c = '\n'; break;
+                    case 't': c = '\t'; break;
+                    case 'r': c = '\r'; break;
+                    case 'b': c = '\b'; break;
+                    case 'f': c = '\f'; break;
+                    case 'u':
+                        c = (char) Integer.parseInt(harnessBuf.substring(harnessPos, harnessPos + 4), 16);
+                        harnessPos += 4;
+                        break;
+                    default: break;
+                }
+            }
+            s.append(c);
+        }
+        harnessExpect('"');
+        return s.toString();
+    }
+
+    private static Class<?> harnessClass(String type) {
+        if (type.endsWith("[]")) {
+            return java.lang.reflect.Array.newInstance(harnessClass(type.substring(0, type.length() - 2)), 0).getClass();
+        }
+        switch (type) {
+            case "int": return int.class;
+            case "long": return long.class;
+            case "double": return double.class;
+            case "bool": return boolean.class;
+            default: return String.class;
+        }
+    }
+
+    private static Object harnessRead(String type) {
+        if (type.endsWith("[]")) {
+            String elem = type.substring(0, type.length() - 2);
+            java.util.List<Object> list = new java.util.ArrayList<>();
+            harnessExpect('[');
+            harnessSkip();
+            if (harnessPos < harnessBuf.length() && harnessBuf.charAt(harnessPos) == ']') {
+                harnessPos++;
+            } else {
+                while (true) {
+                    list.add(harnessRead(elem));
+                    harnessSkip();
+                    if (harnessPos >= harnessBuf.length() || harnessBuf.charAt(harnessPos) != ',') {
+                        break;
+                    }
+                    harnessPos++;
+                }
+                harnessExpect(']');
+            }
+            Object array = java.lang.reflect.Array.newInstance(harnessClass(elem), list.size());
+            for (int i = 0; i < list.size(); i++) {
+                java.lang.reflect.Array.set(array, i, list.get(i));
+            }
+            return array;
+        }
+        try {
+            switch (type) {
+                case "int": return Integer.parseInt(harnessToken());
+                case "long": return Long.parseLong(harnessToken());
+                case "double": return Double.parseDouble(harnessToken());
+                case "bool": {
+                    String token = harnessToken();
+                    if (!token.equals("true") && !token.equals("false")) {
+                        throw harnessFail();
+                    }
+                    return token.equals("true");
+                }
+                default: return harnessReadString();
+            }
+        } catch (NumberFormatException e) {
+            throw harnessFail();
+        }
+    }
+
+    private static void harnessPrint(StringBuilder out, Object v, String type) {
+        if (type.endsWith("[]")) {
+            String elem = type.substring(0, type.length() - 2);
+            int n = v == null ? 0 : java.lang.reflect.Array.getLength(v);
+            out.append('[');
+            for (int i = 0; i < n; i++) {
+                if (i != 0) {
+                    out.append(',');
+                }
+                harnessPrint(out, java.lang.reflect.Array.get(v, i), elem);
+            }
+            out.append(']');
+        } else if (type.equals("double")) {
+            out.append(String.format(java.util.Locale.ROOT, "%.5f", (Double) v));
+        } else if (type.equals("string")) {
+            String s = v == null ? "" : (String) v;
+            out.append('"');
+            for (int i = 0; i < s.length(); i++) {
+                char c = s.charAt(i);
+                if (c == '"' || c == '\\') {
+                    out.append('\\').append(c);
+                } else if (c == '\n') {
+                    out.append("\\n");
+                } else if (c == '\r') {
+                    out.append("\\r");
+                } else if (c == '\t') {
+                    out.append("\\t");
+                } else if (c < 0x20) {
+                    out.append(String.format("\\u%04x", (int) c));
+                } else {
+                    out.append(c);
+                }
+            }
+            out.append('"');
+        } else {
+            out.append(v);
+        }
+    }
+
+`
@@ -0,0 +1,89 @@
+package harness
+
+import (
+	"fmt"
+	"strings"
+)
+
+// javascriptGenerator 和LeetCode一致，用户实现一个函数，每个参数为一行json。
+// long类型使用Number，超过2^53时会丢失精度
+type javascriptGenerator struct{}
+
+var javascriptBaseTypes = map[string]string{
+	TypeInt:    "number",
+	TypeLong:   "number",
+	TypeDouble: "number",
+	TypeBool:   "boolean",
+	TypeString: "string",
+}
+
+// javascriptTypeName jsdoc中的类型，比如int[][]为number[][]
+func javascriptTypeName(t valueType) string {
+	return javascriptBaseTypes[t.base] + strings.Repeat("[]", t.dims)
+}
+
+func (javascriptGenerator) stub(signature *Signature) string {
+	var doc strings.Builder
+	doc.WriteString("/**\n")
+	var params []string
+	for i, t := range signature.params() {
+		fmt.Fprintf(&doc, " * @param {%s} %s\n", javascriptTypeName(t), signature.Params[i].Name)
+		params = append(params, signature.Params[i].Name)
+	}
+	fmt.Fprintf(&doc, " * @return {%s}\n */\n", javascriptTypeName(signature.returnType()))
+	return fmt.Sprintf("%svar %s = function(%s) {\n\n};\n", doc.String(), signature.Name,
+		strings.Join(params, ", "))
+}
+
+func (javascriptGenerator) generate(signature *Signature, mainFile string, code string) []*SourceFile {
+	var main strings.Builder
+	main.WriteString("const harness_lines = require(\"fs\").readFileSync(0, \"utf8\").split(\"\\n\")" +
+		".filter((line) => line.trim() !== \"\");\n")
+	var args []string
+	for i := range signature.Params {
+		args = append(args, fmt.Sprintf("JSON.parse(harness_lines[%d])", i))
+	}
+	fmt.Fprintf(&main, "const harness_result = %s(%s);\n", signature.Name, strings.Join(args, ", "))
+	fmt.Fprintf(&main, "process.stdout.write(harness_format(harness_result, \"%s\") + \"\\n\");\n",
+		signature.returnType())
+	return []*SourceFile{{
+		Name: mainFile,
+		Code: code + "\n" + javascriptRuntime + main.String(),
+	}}
+}
+
+// javascriptRuntime 根据签名中的类型输出返回值
+const javascriptRuntime = `
+function harness_format(value, type) {
+    if (type.endsWith("[]")) {
+        return "[" + (value || []).map((v) => harness_format(v, type.slice(0, -2))).join(",") + "]";
+    }
+    if (type === "double") {
+        return Number(value).toFixed(5);
+    }
+    if (type === "bool") {
+        return value ? "true" : "false";
+    }
+    if (type === "string") {
+        let out = "";
+        for (const c of value || "") {
+            if (c === '"' || c === "\\") {
+                out += "\\" + c;
+            } else if (c === "\n") {
+                out += "\\n";
+            } else if (c === "\r") {
+                out += "\\r";
+            } else if (c === "\t") {
+                out += "\\t";
+            } else if (c.charCodeAt(0) < 0x20) {
+                out += "\\u" + c.charCodeAt(0).toString(16).padStart(4, "0");
+            } else {
+                out += c;
+            }
+        }
+        return '"' + out + '"';
+    }
+    return String(value);
+}
+
+`
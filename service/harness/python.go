@@ -0,0 +1,98 @@
+package harness
+
+import (
+	"fmt"
+	"strings"
+)
+
+// pythonGenerator 和LeetCode一致，用户实现Solution类中的方法，每个参数为一行json
+type pythonGenerator struct{}
+
+var pythonBaseTypes = map[string]string{
+	TypeInt:    "int",
+	TypeLong:   "int",
+	TypeDouble: "float",
+	TypeBool:   "bool",
+	TypeString: "str",
+}
+
+// pythonTypeName 类型注解，比如int[][]为List[List[int]]
+func pythonTypeName(t valueType) string {
+	name := pythonBaseTypes[t.base]
+	for i := 0; i < t.dims; i++ {
+		name = "List[" + name + "]"
+	}
+	return name
+}
+
+func (pythonGenerator) stub(signature *Signature) string {
+	params := []string{"self"}
+	for i, t := range signature.params() {
+		params = append(params, signature.Params[i].Name+": "+pythonTypeName(t))
+	}
+	return fmt.Sprintf("class Solution:\n    def %s(%s) -> %s:\n        pass\n", signature.Name,
+		strings.Join(params, ", "), pythonTypeName(signature.returnType()))
+}
+
+func (pythonGenerator) generate(signature *Signature, mainFile string, code string) []*SourceFile {
+	var main strings.Builder
+	main.WriteString("harness_lines = [line for line in sys.stdin.read().split(\"\\n\") if line.strip()]\n")
+	var args []string
+	for i, t := range signature.params() {
+		args = append(args, fmt.Sprintf("harness_convert(json.loads(harness_lines[%d]), \"%s\")", i, t))
+	}
+	fmt.Fprintf(&main, "harness_result = Solution().%s(%s)\n", signature.Name, strings.Join(args, ", "))
+	fmt.Fprintf(&main, "sys.stdout.write(harness_format(harness_result, \"%s\") + \"\\n\")\n",
+		signature.returnType())
+	return []*SourceFile{{
+		Name: mainFile,
+		Code: pythonPrelude + code + "\n" + pythonRuntime + main.String(),
+	}}
+}
+
+// pythonPrelude 放在用户代码前面，用户代码中可以直接使用List等类型注解
+const pythonPrelude = `from typing import *
+
+`
+
+// pythonRuntime 根据签名中的类型转换输入和输出返回值，用户代码中可能覆盖json和sys，需要重新导入
+const pythonRuntime = `
+import json
+import sys
+
+
+def harness_convert(value, type):
+    if type.endswith("[]"):
+        return [harness_convert(v, type[:-2]) for v in value]
+    if type == "double":
+        return float(value)
+    return value
+
+
+def harness_format(value, type):
+    if type.endswith("[]"):
+        return "[" + ",".join(harness_format(v, type[:-2]) for v in (value or [])) + "]"
+    if type == "double":
+        return "%.5f" % value
+    if type == "bool":
+        return "true" if value else "false"
+    if type == "string":
+        out = []
+        for c in value or "":
+            if c == '"' or c == "\\":
+                out.append("\\" + c)
+            elif c == "\n":
+                out.append("\\n")
+            elif c == "\r":
+                out.append("\\r")
+            elif c == "\t":
+                out.append("\\t")
+            elif ord(c) < 0x20:
+                out.append("\\u%04x" % ord(c))
+            else:
+                out.append(c)
+        return '"' + "".join(out) + '"'
+    return str(value)
+
+
+`
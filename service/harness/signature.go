@@ -0,0 +1,158 @@
+package harness
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// 支持的基本类型，类型后面加[]表示数组，最多为二维数组
+const (
+	TypeInt    = "int"
+	TypeLong   = "long"
+	TypeDouble = "double"
+	TypeBool   = "bool"
+	TypeString = "string"
+)
+
+// 数组的最大维数
+const maxDims = 2
+
+// 生成的代码中使用的名称都以harness开头，参数和函数名称不能使用该前缀
+const reservedPrefix = "harness"
+
+var identifierRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// 所有支持的语言的关键字以及生成的代码中使用的名称，参数和函数名称不能使用
+var reservedWords = map[string]bool{}
+
+func init() {
+	words := "auto break case char const continue default do double else enum extern float for goto if inline int " +
+		"long register restrict return short signed sizeof static struct switch typedef union unsigned void volatile " +
+		"bool true false class public private protected new delete this namespace using template typename virtual " +
+		"operator friend try catch throw nullptr string vector main solution std " +
+		"abstract assert boolean byte extends final finally implements import instanceof interface native package " +
+		"strictfp super synchronized throws transient var null " +
+		"and as async await def del elif except from global in is lambda nonlocal not or pass raise while with " +
+		"yield none " +
+		"arguments eval function let of typeof undefined require process console json math " +
+		"chan defer fallthrough func go map range select type " +
+		"returnsize returncolumnsizes"
+	for _, word := range strings.Fields(words) {
+		reservedWords[word] = true
+	}
+}
+
+// Signature 题目的函数签名，用户只需要实现该函数。
+// 输入中每个参数占一行，格式和json相同，输出为返回值，数组中的元素用,分隔，浮点数保留5位小数
+type Signature struct {
+	// 函数名称
+	Name string `json:"name"`
+	// 参数列表
+	Params []*Param `json:"params"`
+	// 返回值类型
+	ReturnType string `json:"returnType"`
+}
+
+// Param 函数的参数
+type Param struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// valueType 解析后的类型
+type valueType struct {
+	base string // 基本类型
+	dims int    // 数组维数，为0时不是数组
+}
+
+func parseType(t string) (valueType, error) {
+	vt := valueType{base: strings.TrimSpace(t)}
+	for strings.HasSuffix(vt.base, "[]") {
+		vt.base = strings.TrimSpace(strings.TrimSuffix(vt.base, "[]"))
+		vt.dims++
+	}
+	switch vt.base {
+	case TypeInt, TypeLong, TypeDouble, TypeBool, TypeString:
+	default:
+		return vt, fmt.Errorf("不支持的类型%s", t)
+	}
+	if vt.dims > maxDims {
+		return vt, fmt.Errorf("数组最多为%d维：%s", maxDims, t)
+	}
+	return vt, nil
+}
+
+// String 和签名中的写法相同，比如int[][]
+func (t valueType) String() string {
+	return t.base + strings.Repeat("[]", t.dims)
+}
+
+// ParseSignature 解析并检查题目的函数签名
+func ParseSignature(data string) (*Signature, error) {
+	signature := &Signature{}
+	if err := json.Unmarshal([]byte(data), signature); err != nil {
+		return nil, err
+	}
+	if err := checkName(signature.Name); err != nil {
+		return nil, err
+	}
+	if _, err := parseType(signature.ReturnType); err != nil {
+		return nil, err
+	}
+	// c语言中数组参数还有表示长度的参数，比如nums的长度为numsSize，也不能重复
+	names := map[string]bool{signature.Name: true}
+	for _, param := range signature.Params {
+		if param == nil {
+			return nil, fmt.Errorf("参数不能为空")
+		}
+		if err := checkName(param.Name); err != nil {
+			return nil, err
+		}
+		t, err := parseType(param.Type)
+		if err != nil {
+			return nil, err
+		}
+		declared := []string{param.Name}
+		if t.dims != 0 {
+			declared = append(declared, param.Name+"Size")
+		}
+		if t.dims == 2 {
+			declared = append(declared, param.Name+"ColSize")
+		}
+		for _, name := range declared {
+			if names[name] {
+				return nil, fmt.Errorf("参数名称%s重复", name)
+			}
+			names[name] = true
+		}
+	}
+	return signature, nil
+}
+
+// checkName 名称必须是所有语言中合法的标识符，并且不能和生成的代码冲突
+func checkName(name string) error {
+	if !identifierRegexp.MatchString(name) {
+		return fmt.Errorf("名称%s不合法", name)
+	}
+	lower := strings.ToLower(name)
+	if reservedWords[lower] || strings.HasPrefix(lower, reservedPrefix) {
+		return fmt.Errorf("名称%s是保留的名称", name)
+	}
+	return nil
+}
+
+// params 解析后的参数类型
+func (s *Signature) params() []valueType {
+	types := make([]valueType, len(s.Params))
+	for i, param := range s.Params {
+		types[i], _ = parseType(param.Type)
+	}
+	return types
+}
+
+func (s *Signature) returnType() valueType {
+	t, _ := parseType(s.ReturnType)
+	return t
+}
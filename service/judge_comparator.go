@@ -0,0 +1,404 @@
+package service
+
+import (
+	"FanCode/constants"
+	"bufio"
+	"bytes"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 浮点数比较的默认误差
+const defaultCompareEpsilon = 1e-6
+
+// 流式比较时每次读取的长度
+const compareBufferSize = 64 * 1024
+
+// compareOutput 根据题目的比较方式比较用户输出和期望输出，
+// 返回Accepted、WrongAnswer，逐行比较的方式在去除所有空白字符后一致时返回PresentationError
+func compareOutput(comparator constants.ComparatorType, epsilon float64, output string, expected string) int {
+	// 内存中的数据读取不会出错
+	status, _ := compareOutputStream(comparator, epsilon, strings.NewReader(output), strings.NewReader(expected))
+	return status
+}
+
+// compareOutputStream 和compareOutput相同，但是按块、按行或者按单词读取输出进行比较，
+// 期望输出可以是很大的文件，不需要全部读入内存。判断格式错误时需要从头重新读取
+func compareOutputStream(comparator constants.ComparatorType, epsilon float64, output io.ReadSeeker,
+	expected io.ReadSeeker) (int, error) {
+	var same bool
+	var err error
+	switch comparator {
+	case constants.ComparatorExact:
+		same, err = equalStream(output, expected)
+	case constants.ComparatorLine:
+		same, err = equalLineStream(newLineReader(output, false), newLineReader(expected, false))
+	case constants.ComparatorToken:
+		same, err = equalTokenStream(newTokenReader(output), newTokenReader(expected), func(a, b string) bool {
+			return a == b
+		})
+	case constants.ComparatorCaseInsensitive:
+		same, err = equalLineStream(newLineReader(output, true), newLineReader(expected, true))
+	case constants.ComparatorFloat:
+		if epsilon <= 0 {
+			epsilon = defaultCompareEpsilon
+		}
+		same, err = equalTokenStream(newTokenReader(output), newTokenReader(expected), func(a, b string) bool {
+			return equalFloat(a, b, epsilon)
+		})
+	case constants.ComparatorUnordered:
+		// 需要排序，只能读取所有行
+		var outputLines, expectedLines []string
+		if outputLines, err = newLineReader(output, false).readAll(); err != nil {
+			return 0, err
+		}
+		if expectedLines, err = newLineReader(expected, false).readAll(); err != nil {
+			return 0, err
+		}
+		sort.Strings(outputLines)
+		sort.Strings(expectedLines)
+		same = equalLines(outputLines, expectedLines)
+	default:
+		same, err = equalStream(newTrimReader(output), newTrimReader(expected))
+	}
+	if err != nil {
+		return 0, err
+	}
+	if same {
+		return constants.Accepted, nil
+	}
+	// 按空白字符分割的比较方式已经忽略了格式
+	if comparator == constants.ComparatorToken || comparator == constants.ComparatorFloat ||
+		comparator == constants.ComparatorUnordered {
+		return constants.WrongAnswer, nil
+	}
+	if _, err = output.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	if _, err = expected.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	presentation, err := isPresentationError(comparator, output, expected)
+	if err != nil {
+		return 0, err
+	}
+	if presentation {
+		return constants.PresentationError, nil
+	}
+	return constants.WrongAnswer, nil
+}
+
+// isPresentationError 答案不一致，但是去除所有空白字符后一致，则为格式错误
+func isPresentationError(comparator constants.ComparatorType, output io.Reader, expected io.Reader) (bool, error) {
+	lower := comparator == constants.ComparatorCaseInsensitive
+	a, b := bufio.NewReaderSize(output, compareBufferSize), bufio.NewReaderSize(expected, compareBufferSize)
+	for {
+		r1, err := nextNonSpace(a, lower)
+		if err != nil && err != io.EOF {
+			return false, err
+		}
+		r2, err2 := nextNonSpace(b, lower)
+		if err2 != nil && err2 != io.EOF {
+			return false, err2
+		}
+		if err == io.EOF || err2 == io.EOF {
+			return err == err2, nil
+		}
+		if r1 != r2 {
+			return false, nil
+		}
+	}
+}
+
+// nextNonSpace 读取下一个非空白字符，不是合法utf8编码的字节返回负数，保证不同的字节不会相等
+func nextNonSpace(r *bufio.Reader, lower bool) (rune, error) {
+	for {
+		c, size, err := r.ReadRune()
+		if err != nil {
+			return 0, err
+		}
+		if c == utf8.RuneError && size == 1 {
+			_ = r.UnreadRune()
+			b, _ := r.ReadByte()
+			return -rune(b) - 1, nil
+		}
+		if unicode.IsSpace(c) {
+			continue
+		}
+		if lower {
+			c = unicode.ToLower(c)
+		}
+		return c, nil
+	}
+}
+
+// equalStream 按块比较两个输入是否完全一致
+func equalStream(a io.Reader, b io.Reader) (bool, error) {
+	buf1, buf2 := make([]byte, compareBufferSize), make([]byte, compareBufferSize)
+	for {
+		n1, err1 := io.ReadFull(a, buf1)
+		if err1 != nil && err1 != io.EOF && err1 != io.ErrUnexpectedEOF {
+			return false, err1
+		}
+		n2, err2 := io.ReadFull(b, buf2)
+		if err2 != nil && err2 != io.EOF && err2 != io.ErrUnexpectedEOF {
+			return false, err2
+		}
+		if !bytes.Equal(buf1[:n1], buf2[:n2]) {
+			return false, nil
+		}
+		// 长度相同时两个输入同时结束
+		if err1 != nil {
+			return true, nil
+		}
+	}
+}
+
+// trimReader 去除首尾的空格和换行，和先去除首尾空格再去除首尾换行的结果一致。
+// 连续的空格和换行暂存在pending中，读到其他字符时输出，读到结尾时按照规则去除
+type trimReader struct {
+	r       *bufio.Reader
+	started bool // 是否已经读到空格和换行以外的字符
+	eof     bool
+	pending []byte
+	out     []byte
+}
+
+func newTrimReader(r io.Reader) *trimReader {
+	return &trimReader{r: bufio.NewReaderSize(r, compareBufferSize)}
+}
+
+func (t *trimReader) Read(p []byte) (int, error) {
+	for len(t.out) < len(p) && !t.eof {
+		c, err := t.r.ReadByte()
+		if err == io.EOF {
+			t.eof = true
+			if t.started {
+				t.out = append(t.out, bytes.TrimRight(bytes.TrimRight(t.pending, " "), "\n")...)
+			} else {
+				t.out = append(t.out, bytes.Trim(bytes.Trim(t.pending, " "), "\n")...)
+			}
+			t.pending = nil
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		if c == ' ' || c == '\n' {
+			t.pending = append(t.pending, c)
+			continue
+		}
+		if t.started {
+			t.out = append(t.out, t.pending...)
+		} else {
+			t.out = append(t.out, bytes.TrimLeft(bytes.TrimLeft(t.pending, " "), "\n")...)
+			t.started = true
+		}
+		t.pending = t.pending[:0]
+		t.out = append(t.out, c)
+	}
+	if len(t.out) == 0 && t.eof {
+		return 0, io.EOF
+	}
+	n := copy(p, t.out)
+	t.out = t.out[:copy(t.out, t.out[n:])]
+	return n, nil
+}
+
+// lineReader 按行读取，去除每行末尾的空白字符以及末尾的空行。
+// 只在内存中保存当前行，遇到空行时需要向后读取判断是否为末尾的空行
+type lineReader struct {
+	r       *bufio.Reader
+	lower   bool
+	empty   int // 已经读取但是还没有返回的空行数量
+	next    string
+	hasNext bool
+}
+
+func newLineReader(r io.Reader, lower bool) *lineReader {
+	return &lineReader{r: bufio.NewReaderSize(r, compareBufferSize), lower: lower}
+}
+
+// readLine 读取原始的一行，去除末尾的空白字符
+func (l *lineReader) readLine() (string, bool, error) {
+	line, err := l.r.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", false, err
+	}
+	if err == io.EOF && line == "" {
+		return "", false, nil
+	}
+	line = strings.TrimRight(line, " \t\r\n")
+	if l.lower {
+		line = strings.ToLower(line)
+	}
+	return line, true, nil
+}
+
+// nextLine 读取下一行，没有更多的行时返回false
+func (l *lineReader) nextLine() (string, bool, error) {
+	if l.empty > 0 {
+		l.empty--
+		return "", true, nil
+	}
+	if l.hasNext {
+		l.hasNext = false
+		return l.next, true, nil
+	}
+	line, ok, err := l.readLine()
+	if !ok || err != nil || line != "" {
+		return line, ok, err
+	}
+	// 空行后面还有非空行时才返回空行
+	for count := 1; ; count++ {
+		line, ok, err = l.readLine()
+		if !ok || err != nil {
+			return "", false, err
+		}
+		if line != "" {
+			l.next, l.hasNext, l.empty = line, true, count-1
+			return "", true, nil
+		}
+	}
+}
+
+// readAll 读取所有行
+func (l *lineReader) readAll() ([]string, error) {
+	var lines []string
+	for {
+		line, ok, err := l.nextLine()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return lines, nil
+		}
+		lines = append(lines, line)
+	}
+}
+
+func equalLineStream(a *lineReader, b *lineReader) (bool, error) {
+	for {
+		line1, ok1, err := a.nextLine()
+		if err != nil {
+			return false, err
+		}
+		line2, ok2, err := b.nextLine()
+		if err != nil {
+			return false, err
+		}
+		if ok1 != ok2 || line1 != line2 {
+			return false, nil
+		}
+		if !ok1 {
+			return true, nil
+		}
+	}
+}
+
+// tokenReader 按空白字符分割读取单词，和strings.Fields的结果一致
+type tokenReader struct {
+	r *bufio.Reader
+}
+
+func newTokenReader(r io.Reader) *tokenReader {
+	return &tokenReader{r: bufio.NewReaderSize(r, compareBufferSize)}
+}
+
+// nextToken 读取下一个单词，没有更多的单词时返回false
+func (t *tokenReader) nextToken() (string, bool, error) {
+	var token strings.Builder
+	for {
+		c, size, err := t.r.ReadRune()
+		if err == io.EOF {
+			return token.String(), token.Len() != 0, nil
+		}
+		if err != nil {
+			return "", false, err
+		}
+		if unicode.IsSpace(c) {
+			if token.Len() != 0 {
+				return token.String(), true, nil
+			}
+			continue
+		}
+		if c == utf8.RuneError && size == 1 {
+			// 保留不是合法utf8编码的原始字节
+			_ = t.r.UnreadRune()
+			b, _ := t.r.ReadByte()
+			token.WriteByte(b)
+			continue
+		}
+		token.WriteRune(c)
+	}
+}
+
+func equalTokenStream(a *tokenReader, b *tokenReader, equal func(a, b string) bool) (bool, error) {
+	for {
+		token1, ok1, err := a.nextToken()
+		if err != nil {
+			return false, err
+		}
+		token2, ok2, err := b.nextToken()
+		if err != nil {
+			return false, err
+		}
+		if ok1 != ok2 || ok1 && !equal(token1, token2) {
+			return false, nil
+		}
+		if !ok1 {
+			return true, nil
+		}
+	}
+}
+
+func equalLines(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// equalFloat 都是数字时绝对误差或者相对误差不超过epsilon即相同，否则要求完全一致
+func equalFloat(output string, expected string, epsilon float64) bool {
+	if output == expected {
+		return true
+	}
+	a, err1 := strconv.ParseFloat(output, 64)
+	b, err2 := strconv.ParseFloat(expected, 64)
+	if err1 != nil || err2 != nil || math.IsNaN(a) || math.IsNaN(b) {
+		return false
+	}
+	diff := math.Abs(a - b)
+	return !(diff > epsilon && diff > epsilon*math.Abs(b))
+}
+
+// firstDiffLine 逐行比较用户输出和期望输出，忽略行尾的\r，返回第一个不同的行号（从1开始）以及该行的内容，
+// 一方没有该行时内容为空字符串。所有行都相同时返回0
+func firstDiffLine(output string, expected string) (int, string, string) {
+	outputLines := strings.Split(output, "\n")
+	expectedLines := strings.Split(expected, "\n")
+	for i := 0; i < len(outputLines) || i < len(expectedLines); i++ {
+		var a, b string
+		if i < len(outputLines) {
+			a = strings.TrimSuffix(outputLines[i], "\r")
+		}
+		if i < len(expectedLines) {
+			b = strings.TrimSuffix(expectedLines[i], "\r")
+		}
+		if a != b || (i < len(outputLines)) != (i < len(expectedLines)) {
+			return i + 1, a, b
+		}
+	}
+	return 0, "", ""
+}
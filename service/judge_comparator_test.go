@@ -0,0 +1,159 @@
+package service
+
+import (
+	"FanCode/constants"
+	e "FanCode/error"
+	"FanCode/models/dto"
+	"FanCode/models/po"
+	"FanCode/service/judger"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompareOutput(t *testing.T) {
+	cases := []struct {
+		name       string
+		comparator constants.ComparatorType
+		epsilon    float64
+		output     string
+		expected   string
+		status     int
+	}{
+		// 默认忽略首尾的空格和换行
+		{"default", constants.ComparatorDefault, 0, "1 2\n\n", "1 2", constants.Accepted},
+		{"default trailing newline", constants.ComparatorDefault, 0, "3\n ", "3", constants.Accepted},
+		{"default presentation", constants.ComparatorDefault, 0, "1  2", "1 2", constants.PresentationError},
+		{"default wrong", constants.ComparatorDefault, 0, "1 3", "1 2", constants.WrongAnswer},
+		{"default leading", constants.ComparatorDefault, 0, " \n\n1 2 \n", "\n1 2 \n", constants.Accepted},
+		{"default inner space", constants.ComparatorDefault, 0, "1 2 \n", "1 2", constants.PresentationError},
+		{"default blank", constants.ComparatorDefault, 0, " \n \n", "", constants.PresentationError},
+
+		{"exact", constants.ComparatorExact, 0, "1 2\n", "1 2\n", constants.Accepted},
+		{"exact newline", constants.ComparatorExact, 0, "1 2", "1 2\n", constants.PresentationError},
+		{"exact wrong", constants.ComparatorExact, 0, "1 3\n", "1 2\n", constants.WrongAnswer},
+
+		{"line", constants.ComparatorLine, 0, "1 2  \r\n3\t\n\n", "1 2\n3\n", constants.Accepted},
+		{"line leading space", constants.ComparatorLine, 0, " 1 2\n3\n", "1 2\n3\n", constants.PresentationError},
+		{"line join", constants.ComparatorLine, 0, "1 2 3\n", "1 2\n3\n", constants.PresentationError},
+		{"line wrong", constants.ComparatorLine, 0, "1 2\n4\n", "1 2\n3\n", constants.WrongAnswer},
+		{"line inner empty", constants.ComparatorLine, 0, "1\n\n\n2\n\n", "1\n\n\n2", constants.Accepted},
+		{"line missing empty", constants.ComparatorLine, 0, "1\n\n2\n", "1\n\n\n2\n", constants.PresentationError},
+
+		{"token", constants.ComparatorToken, 0, " 1\n2   3\n", "1 2 3", constants.Accepted},
+		{"token wrong", constants.ComparatorToken, 0, "12 3", "1 2 3", constants.WrongAnswer},
+		{"token missing", constants.ComparatorToken, 0, "1 2", "1 2 3", constants.WrongAnswer},
+
+		{"case", constants.ComparatorCaseInsensitive, 0, "YES\nno \n", "yes\nNO\n", constants.Accepted},
+		{"case presentation", constants.ComparatorCaseInsensitive, 0, "YES NO\n", "yes\nno\n", constants.PresentationError},
+		{"case wrong", constants.ComparatorCaseInsensitive, 0, "YES\n", "no\n", constants.WrongAnswer},
+
+		{"float default epsilon", constants.ComparatorFloat, 0, "0.3333333 1\n", "0.333333 1.000000", constants.Accepted},
+		{"float absolute", constants.ComparatorFloat, 1e-2, "3.14", "3.1416", constants.Accepted},
+		{"float relative", constants.ComparatorFloat, 1e-6, "1000000.5", "1000000", constants.Accepted},
+		{"float wrong", constants.ComparatorFloat, 1e-6, "0.3334", "0.3333", constants.WrongAnswer},
+		{"float text", constants.ComparatorFloat, 1e-6, "answer 1.0", "answer 1", constants.Accepted},
+		{"float text wrong", constants.ComparatorFloat, 1e-6, "Answer 1", "answer 1", constants.WrongAnswer},
+		{"float nan", constants.ComparatorFloat, 1e-6, "nan", "nan", constants.Accepted},
+		{"float not number", constants.ComparatorFloat, 1e-6, "nan", "1", constants.WrongAnswer},
+
+		{"unordered", constants.ComparatorUnordered, 0, "b 2\na 1  \n\n", "a 1\nb 2\n", constants.Accepted},
+		{"unordered duplicate", constants.ComparatorUnordered, 0, "a 1\na 1\n", "a 1\nb 2\n", constants.WrongAnswer},
+		{"unordered missing", constants.ComparatorUnordered, 0, "a 1\n", "a 1\nb 2\n", constants.WrongAnswer},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.status, compareOutput(c.comparator, c.epsilon, c.output, c.expected))
+		})
+	}
+}
+
+func TestCompareOutputStream(t *testing.T) {
+	// 期望输出比读取的缓冲区大
+	var expected strings.Builder
+	for i := 0; i < 50000; i++ {
+		expected.WriteString(strconv.Itoa(i) + " 0.5\n")
+	}
+	name := filepath.Join(t.TempDir(), "answer.txt")
+	assert.Nil(t, os.WriteFile(name, []byte(expected.String()), 0644))
+	compare := func(comparator constants.ComparatorType, output string) int {
+		file, err := os.Open(name)
+		assert.Nil(t, err)
+		defer file.Close()
+		status, err := compareOutputStream(comparator, 0, strings.NewReader(output), file)
+		assert.Nil(t, err)
+		return status
+	}
+	for _, comparator := range []constants.ComparatorType{constants.ComparatorDefault, constants.ComparatorExact,
+		constants.ComparatorLine, constants.ComparatorToken, constants.ComparatorFloat, constants.ComparatorUnordered} {
+		assert.Equal(t, constants.Accepted, compare(comparator, expected.String()), comparator)
+	}
+	changed := strings.Replace(expected.String(), "49999 0.5", "49999 0.6", 1)
+	assert.Equal(t, constants.WrongAnswer, compare(constants.ComparatorLine, changed))
+	assert.Equal(t, constants.WrongAnswer, compare(constants.ComparatorFloat, changed))
+	assert.Equal(t, constants.PresentationError, compare(constants.ComparatorLine,
+		strings.ReplaceAll(expected.String(), " ", "  ")))
+}
+
+func TestCheckComparator(t *testing.T) {
+	assert.Nil(t, checkComparator(&po.Problem{Comparator: "float"}))
+	assert.Nil(t, checkComparator(&po.Problem{}))
+	assert.Equal(t, e.ErrBadRequest, checkComparator(&po.Problem{Comparator: "regex"}))
+}
+
+func TestTruncateOutput(t *testing.T) {
+	assert.Equal(t, "abc", truncateOutput([]byte("abc"), 3))
+	assert.Equal(t, "ab", truncateOutput([]byte("abc"), 2))
+	// 不截断utf8字符
+	assert.Equal(t, "a", truncateOutput([]byte("a中文"), 3))
+	assert.Equal(t, "a中", truncateOutput([]byte("a中文"), 4))
+}
+
+func TestFirstDiffLine(t *testing.T) {
+	line, output, expected := firstDiffLine("1\r\n2\n3\n", "1\n2\n3\n")
+	assert.Equal(t, 0, line)
+	line, output, expected = firstDiffLine("1\n5\n3\n", "1\n2\n3\n")
+	assert.Equal(t, 2, line)
+	assert.Equal(t, "5", output)
+	assert.Equal(t, "2", expected)
+	// 用户输出缺少行
+	line, output, expected = firstDiffLine("1", "1\n2")
+	assert.Equal(t, 2, line)
+	assert.Equal(t, "", output)
+	assert.Equal(t, "2", expected)
+}
+
+func TestNewExecuteCaseResult(t *testing.T) {
+	expected := "1 2\n3\n"
+	execute := &judger.ExecuteResult{Executed: true, Output: []byte("1 2\n4\n"), UsedCpuTime: int64(time.Millisecond),
+		UsedMemory: 1024, ErrorOutput: "debug"}
+	result := newExecuteCaseResult(nil, &dto.ExecuteInputDto{Input: "1", ExpectedOutput: &expected}, execute)
+	assert.Equal(t, uint(constants.RunSuccess), result.Status)
+	assert.Equal(t, "debug", result.ErrorOutput)
+	assert.Equal(t, time.Millisecond, result.TimeUsed)
+	assert.Equal(t, int64(1024), result.MemoryUsed)
+	assert.Equal(t, constants.WrongAnswer, result.Verdict)
+	assert.Equal(t, &dto.ExecuteDiffDto{Line: 2, Output: "4", Expected: "3"}, result.Diff)
+
+	// 使用题目的比较方式
+	problem := &po.Problem{Comparator: string(constants.ComparatorToken)}
+	expected = "1\n2\n4\n"
+	result = newExecuteCaseResult(problem, &dto.ExecuteInputDto{ExpectedOutput: &expected}, execute)
+	assert.Equal(t, constants.Accepted, result.Verdict)
+	assert.Nil(t, result.Diff)
+
+	// 没有期望输出时不比较
+	result = newExecuteCaseResult(nil, &dto.ExecuteInputDto{}, execute)
+	assert.Equal(t, 0, result.Verdict)
+
+	// 运行失败时不比较
+	execute = &judger.ExecuteResult{Verdict: constants.TimeLimitExceeded, ExitCode: -1, Signal: "SIGKILL"}
+	result = newExecuteCaseResult(nil, &dto.ExecuteInputDto{ExpectedOutput: &expected}, execute)
+	assert.Equal(t, uint(constants.TimeLimitExceeded), result.Status)
+	assert.Equal(t, "SIGKILL", result.Signal)
+	assert.Equal(t, 0, result.Verdict)
+}
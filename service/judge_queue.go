@@ -0,0 +1,153 @@
+package service
+
+import (
+	"FanCode/models/dto"
+	"FanCode/models/po"
+	"sync"
+)
+
+// 订阅判题进度的channel长度，订阅者处理不及时时丢弃中间的进度
+const progressBufferSize = 16
+
+// judgeTask 判题队列中的一次提交
+type judgeTask struct {
+	submission *po.Submission
+	problem    *po.Problem
+	request    *dto.SubmitRequestDto
+	// 是否是重判，重判完成后重新统计用户的做题情况
+	rejudge bool
+}
+
+// judgeQueue 判题队列，把等待判题的提交分发给worker
+type judgeQueue interface {
+	// push 加入判题队列，队列已满或者加入失败时返回false
+	push(task *judgeTask) bool
+	// pushWait 加入判题队列，队列已满时等待，用于批量重判
+	pushWait(task *judgeTask) bool
+}
+
+// localJudgeQueue 本地判题队列，固定数量的worker依次从队列中取出提交进行判题
+type localJudgeQueue struct {
+	tasks chan *judgeTask
+}
+
+func newLocalJudgeQueue(size int, workers int, handle func(task *judgeTask)) *localJudgeQueue {
+	q := &localJudgeQueue{
+		tasks: make(chan *judgeTask, size),
+	}
+	for i := 0; i < workers; i++ {
+		go func() {
+			for task := range q.tasks {
+				handle(task)
+			}
+		}()
+	}
+	return q
+}
+
+func (q *localJudgeQueue) push(task *judgeTask) bool {
+	select {
+	case q.tasks <- task:
+		return true
+	default:
+		return false
+	}
+}
+
+func (q *localJudgeQueue) pushWait(task *judgeTask) bool {
+	q.tasks <- task
+	return true
+}
+
+// judgeProgress 记录正在判题的提交的最新进度，并通知订阅者
+type judgeProgress struct {
+	mu          sync.Mutex
+	latest      map[uint]*dto.JudgeProgressDto
+	subscribers map[uint]map[chan *dto.JudgeProgressDto]struct{}
+}
+
+func newJudgeProgress() *judgeProgress {
+	return &judgeProgress{
+		latest:      make(map[uint]*dto.JudgeProgressDto),
+		subscribers: make(map[uint]map[chan *dto.JudgeProgressDto]struct{}),
+	}
+}
+
+// start 开始记录提交的判题进度
+func (p *judgeProgress) start(progress *dto.JudgeProgressDto) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.latest[progress.SubmissionID] = progress
+}
+
+// publish 更新判题进度并通知订阅者，返回状态是否发生变化
+func (p *judgeProgress) publish(progress *dto.JudgeProgressDto) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	last, ok := p.latest[progress.SubmissionID]
+	if !ok {
+		return false
+	}
+	p.latest[progress.SubmissionID] = progress
+	for ch := range p.subscribers[progress.SubmissionID] {
+		select {
+		case ch <- progress:
+		default:
+		}
+	}
+	return last.Status != progress.Status
+}
+
+// update 更新判题进度，没有记录时开始记录，用于接收其他节点上的worker的判题进度
+func (p *judgeProgress) update(progress *dto.JudgeProgressDto) {
+	p.mu.Lock()
+	if _, ok := p.latest[progress.SubmissionID]; !ok {
+		p.latest[progress.SubmissionID] = progress
+	}
+	p.mu.Unlock()
+	p.publish(progress)
+}
+
+// finish 判题完成，向订阅者发送最终结果后关闭channel
+func (p *judgeProgress) finish(progress *dto.JudgeProgressDto) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.latest, progress.SubmissionID)
+	for ch := range p.subscribers[progress.SubmissionID] {
+		// 丢弃一个中间进度保证最终结果能够发送
+		select {
+		case ch <- progress:
+		default:
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- progress
+		}
+		close(ch)
+	}
+	delete(p.subscribers, progress.SubmissionID)
+}
+
+// subscribe 订阅提交的判题进度，channel中首先是当前的进度，判题完成后关闭。
+// 提交不在判题中时返回false
+func (p *judgeProgress) subscribe(submissionID uint) (<-chan *dto.JudgeProgressDto, func(), bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	latest, ok := p.latest[submissionID]
+	if !ok {
+		return nil, nil, false
+	}
+	ch := make(chan *dto.JudgeProgressDto, progressBufferSize)
+	ch <- latest
+	if p.subscribers[submissionID] == nil {
+		p.subscribers[submissionID] = make(map[chan *dto.JudgeProgressDto]struct{})
+	}
+	p.subscribers[submissionID][ch] = struct{}{}
+	cancel := func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		delete(p.subscribers[submissionID], ch)
+	}
+	return ch, cancel, true
+}
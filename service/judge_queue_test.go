@@ -0,0 +1,77 @@
+package service
+
+import (
+	"FanCode/constants"
+	"FanCode/models/dto"
+	"FanCode/models/po"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+func TestJudgeQueue(t *testing.T) {
+	var mu sync.Mutex
+	judged := make(map[uint]bool)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+	q := newLocalJudgeQueue(2, 1, func(task *judgeTask) {
+		if task.submission.ID == 1 {
+			started <- struct{}{}
+			<-release
+		}
+		mu.Lock()
+		judged[task.submission.ID] = true
+		mu.Unlock()
+		done <- struct{}{}
+	})
+	newTask := func(id uint) *judgeTask {
+		submission := &po.Submission{}
+		submission.ID = id
+		return &judgeTask{submission: submission}
+	}
+	// worker取出第一个提交后阻塞，队列中还能放入两个提交
+	assert.True(t, q.push(newTask(1)))
+	<-started
+	assert.True(t, q.push(newTask(2)))
+	assert.True(t, q.push(newTask(3)))
+	assert.False(t, q.push(newTask(4)))
+	close(release)
+	for i := 0; i < 3; i++ {
+		<-done
+	}
+	assert.Equal(t, map[uint]bool{1: true, 2: true, 3: true}, judged)
+}
+
+func TestJudgeProgress(t *testing.T) {
+	p := newJudgeProgress()
+	// 不在判题中
+	_, _, ok := p.subscribe(1)
+	assert.False(t, ok)
+	assert.False(t, p.publish(&dto.JudgeProgressDto{SubmissionID: 1, Status: constants.Compiling}))
+
+	p.start(&dto.JudgeProgressDto{SubmissionID: 1, Status: constants.Pending})
+	assert.True(t, p.publish(&dto.JudgeProgressDto{SubmissionID: 1, Status: constants.Compiling}))
+	ch, cancel, ok := p.subscribe(1)
+	assert.True(t, ok)
+	defer cancel()
+	assert.Equal(t, constants.Compiling, (<-ch).Status)
+
+	// 状态不变时返回false
+	assert.True(t, p.publish(&dto.JudgeProgressDto{SubmissionID: 1, Status: constants.Running, Case: 1, CaseCount: 30}))
+	for i := 2; i <= 30; i++ {
+		assert.False(t, p.publish(&dto.JudgeProgressDto{SubmissionID: 1, Status: constants.Running, Case: i, CaseCount: 30}))
+	}
+	// 订阅者处理不及时时丢弃进度，但是最终结果一定能收到
+	p.finish(&dto.JudgeProgressDto{SubmissionID: 1, Status: constants.Accepted})
+	var events []*dto.JudgeProgressDto
+	for event := range ch {
+		events = append(events, event)
+	}
+	assert.Equal(t, progressBufferSize, len(events))
+	// 丢弃最早的进度为最终结果腾出位置
+	assert.Equal(t, 2, events[0].Case)
+	assert.Equal(t, constants.Accepted, events[len(events)-1].Status)
+	_, _, ok = p.subscribe(1)
+	assert.False(t, ok)
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"FanCode/constants"
+	e "FanCode/error"
+	"FanCode/global"
+	"FanCode/models/dto"
+	"FanCode/models/po"
+	"errors"
+	"gorm.io/gorm"
+	"log"
+	"time"
+)
+
+func (j *judgeService) Rejudge(query *dto.RejudgeRequestDto) (int, *e.Error) {
+	// 必须指定题目、用户或者完整的时间范围，避免误操作重判所有提交
+	if query.ProblemID == 0 && query.UserID == 0 && (query.Begin.IsZero() || query.End.IsZero()) {
+		return 0, e.ErrBadRequest
+	}
+	submissions, err := j.submissionDao.GetRejudgeSubmissions(global.Mysql, query)
+	if err != nil {
+		log.Printf("GetRejudgeSubmissions error: %v\n", err)
+		return 0, e.ErrMysql
+	}
+	// 正在判题的提交已经在判题队列中，不需要重判
+	rejudgeSubmissions := make([]*po.Submission, 0, len(submissions))
+	for _, submission := range submissions {
+		if !isJudging(submission.Status) {
+			rejudgeSubmissions = append(rejudgeSubmissions, submission)
+		}
+	}
+	if len(rejudgeSubmissions) == 0 {
+		return 0, nil
+	}
+
+	// 记录重判前的结果，并删除原来的用例判题结果
+	now := time.Now()
+	tx := global.Mysql.Begin()
+	for _, submission := range rejudgeSubmissions {
+		resetSubmission(submission, now)
+		if err = j.submissionDao.UpdateSubmission(tx, submission); err != nil {
+			tx.Rollback()
+			log.Printf("UpdateSubmission error: %v\n", err)
+			return 0, e.ErrMysql
+		}
+		if err = j.submissionCaseDao.DeleteSubmissionCases(tx, submission.ID); err != nil {
+			tx.Rollback()
+			log.Printf("DeleteSubmissionCases error: %v\n", err)
+			return 0, e.ErrMysql
+		}
+	}
+	if err = tx.Commit().Error; err != nil {
+		log.Printf("Commit error: %v\n", err)
+		return 0, e.ErrMysql
+	}
+
+	// 重判的提交可能很多，在后台等待加入判题队列
+	for _, submission := range rejudgeSubmissions {
+		j.progress.start(&dto.JudgeProgressDto{
+			SubmissionID: submission.ID,
+			Status:       constants.Pending,
+		})
+	}
+	go j.requeueSubmissions(rejudgeSubmissions)
+	return len(rejudgeSubmissions), nil
+}
+
+// requeueSubmissions 按照重判把提交加入判题队列，队列已满时等待，判题完成后根据所有提交重新统计做题情况。
+// 题目不存在或者加入队列失败时判为系统错误
+func (j *judgeService) requeueSubmissions(submissions []*po.Submission) {
+	problems := make(map[uint]*po.Problem)
+	for _, submission := range submissions {
+		problem, ok := problems[submission.ProblemID]
+		if !ok {
+			var err error
+			if problem, err = j.problemDao.GetProblemByID(global.Mysql, submission.ProblemID); err != nil {
+				log.Printf("GetProblemByID error: %v\n", err)
+				problem = nil
+			}
+			problems[submission.ProblemID] = problem
+		}
+		if problem == nil || !j.queue.pushWait(&judgeTask{
+			submission: submission,
+			problem:    problem,
+			request:    newSubmitRequest(submission),
+			rejudge:    true,
+		}) {
+			submission.Status = constants.SystemError
+			submission.ErrorMessage = "加入判题队列失败"
+			if err := j.saveSubmission(submission, true); err != nil {
+				log.Printf("saveSubmission error: %v\n", err)
+			}
+			j.finishProgress(submission)
+		}
+	}
+}
+
+// recoverJudgingSubmissions 本地判题队列只保存在内存中，服务重启后重新判题上次没有完成的提交。
+// 用例结果和判题结果在同一个事务中保存，没有完成的提交不会有用例结果，直接重新加入判题队列
+func (j *judgeService) recoverJudgingSubmissions() {
+	submissions, err := j.submissionDao.GetSubmissionsByStatus(global.Mysql,
+		[]int{constants.Pending, constants.Compiling, constants.Running})
+	if err != nil {
+		log.Printf("GetSubmissionsByStatus error: %v\n", err)
+		return
+	}
+	if len(submissions) == 0 {
+		return
+	}
+	log.Printf("requeue %d unfinished submissions\n", len(submissions))
+	for _, submission := range submissions {
+		submission.Status = constants.Pending
+		j.progress.start(&dto.JudgeProgressDto{
+			SubmissionID: submission.ID,
+			Status:       constants.Pending,
+		})
+	}
+	j.requeueSubmissions(submissions)
+}
+
+// resetSubmission 记录提交重判前的状态和得分，并清除原来的判题结果
+func resetSubmission(submission *po.Submission, rejudgedAt time.Time) {
+	submission.PreviousStatus = submission.Status
+	submission.PreviousScore = submission.Score
+	submission.RejudgedAt = &rejudgedAt
+	submission.Status = constants.Pending
+	submission.ErrorMessage = ""
+	submission.ExitCode = 0
+	submission.Signal = ""
+	submission.CaseName = ""
+	submission.CaseData = ""
+	submission.ExpectedOutput = ""
+	submission.UserOutput = ""
+	submission.TimeUsed = 0
+	submission.MemoryUsed = 0
+	submission.CheckerMessage = ""
+	submission.Score = 0
+	submission.Cases = nil
+}
+
+// rebuildProblemAttempt 根据用户在题目中的所有提交重新统计做题情况。
+// 多个worker同时重判同一个用户的提交时，先锁住做题情况再读取提交，保证读取到其他事务已经提交的判题结果
+func (j *judgeService) rebuildProblemAttempt(tx *gorm.DB, userID uint, problemID uint) *e.Error {
+	problemAttempt, err := j.problemAttemptDao.GetProblemAttemptForUpdate(tx, userID, problemID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("GetProblemAttemptForUpdate error: %v\n", err)
+		return e.ErrSubmitFailed
+	}
+	exist := err == nil
+	submissions, err := j.submissionDao.GetUserProblemSubmissions(tx, userID, problemID)
+	if err != nil {
+		log.Printf("GetUserProblemSubmissions error: %v\n", err)
+		return e.ErrSubmitFailed
+	}
+	if !exist {
+		problemAttempt = &po.ProblemAttempt{
+			UserID:    userID,
+			ProblemID: problemID,
+		}
+	}
+	countProblemAttempt(problemAttempt, submissions)
+	if exist {
+		problemAttempt.UpdatedAt = time.Now()
+		err = j.problemAttemptDao.UpdateProblemAttempt(tx, problemAttempt)
+	} else {
+		err = j.problemAttemptDao.InsertProblemAttempt(tx, problemAttempt)
+	}
+	if err != nil {
+		log.Printf("save ProblemAttempt error: %v\n", err)
+		return e.ErrSubmitFailed
+	}
+	return nil
+}
+
+// countProblemAttempt 统计提交次数、通过次数和最高得分，代码和语言使用最近一次提交。
+// 等待判题和正在判题的提交不统计，判题完成后会再次统计
+func countProblemAttempt(problemAttempt *po.ProblemAttempt, submissions []*po.Submission) {
+	problemAttempt.SubmissionCount = 0
+	problemAttempt.SuccessCount = 0
+	problemAttempt.ErrCount = 0
+	problemAttempt.BestScore = 0
+	problemAttempt.Status = constants.InProgress
+	for _, submission := range submissions {
+		if isJudging(submission.Status) {
+			continue
+		}
+		problemAttempt.SubmissionCount++
+		if submission.Status == constants.Accepted {
+			problemAttempt.SuccessCount++
+			problemAttempt.Status = constants.Success
+		} else {
+			problemAttempt.ErrCount++
+		}
+		if submission.Score > problemAttempt.BestScore {
+			problemAttempt.BestScore = submission.Score
+		}
+	}
+	if len(submissions) != 0 {
+		last := submissions[len(submissions)-1]
+		problemAttempt.Code = last.Code
+		problemAttempt.Language = last.Language
+	}
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"FanCode/constants"
+	"FanCode/dao/mock"
+	"FanCode/models/dto"
+	"FanCode/models/po"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestResetSubmission(t *testing.T) {
+	submission := &po.Submission{
+		Status:       constants.WrongAnswer,
+		Score:        40,
+		ErrorMessage: "wrong answer",
+		CaseName:     "case2",
+		Cases:        []*po.SubmissionCase{{CaseName: "case1"}},
+	}
+	now := time.Now()
+	resetSubmission(submission, now)
+	assert.Equal(t, constants.WrongAnswer, submission.PreviousStatus)
+	assert.Equal(t, 40, submission.PreviousScore)
+	assert.Equal(t, now, *submission.RejudgedAt)
+	assert.Equal(t, constants.Pending, submission.Status)
+	assert.Equal(t, 0, submission.Score)
+	assert.Empty(t, submission.ErrorMessage)
+	assert.Empty(t, submission.CaseName)
+	assert.Nil(t, submission.Cases)
+}
+
+func TestCountProblemAttempt(t *testing.T) {
+	problemAttempt := &po.ProblemAttempt{
+		SubmissionCount: 5,
+		SuccessCount:    3,
+		ErrCount:        2,
+		Status:          constants.Success,
+		BestScore:       100,
+	}
+	// 重判后原来通过的提交不再通过
+	submissions := []*po.Submission{
+		{Status: constants.WrongAnswer, Score: 30, Code: "a", Language: "c"},
+		{Status: constants.TimeLimitExceeded, Score: 60, Code: "b", Language: "cpp"},
+		{Status: constants.WrongAnswer, Score: 0, Code: "c", Language: "go"},
+	}
+	countProblemAttempt(problemAttempt, submissions)
+	assert.Equal(t, 3, problemAttempt.SubmissionCount)
+	assert.Equal(t, 0, problemAttempt.SuccessCount)
+	assert.Equal(t, 3, problemAttempt.ErrCount)
+	assert.Equal(t, constants.InProgress, problemAttempt.Status)
+	assert.Equal(t, 60, problemAttempt.BestScore)
+	assert.Equal(t, "c", problemAttempt.Code)
+	assert.Equal(t, "go", problemAttempt.Language)
+
+	submissions[1].Status = constants.Accepted
+	submissions[1].Score = FullScore
+	countProblemAttempt(problemAttempt, submissions)
+	assert.Equal(t, 1, problemAttempt.SuccessCount)
+	assert.Equal(t, 2, problemAttempt.ErrCount)
+	assert.Equal(t, constants.Success, problemAttempt.Status)
+	assert.Equal(t, FullScore, problemAttempt.BestScore)
+
+	// 其他worker还没有判完的提交不统计
+	submissions = append(submissions, &po.Submission{Status: constants.Pending, Code: "d", Language: "java"},
+		&po.Submission{Status: constants.Running, Code: "e", Language: "python"})
+	countProblemAttempt(problemAttempt, submissions)
+	assert.Equal(t, 3, problemAttempt.SubmissionCount)
+	assert.Equal(t, 1, problemAttempt.SuccessCount)
+	assert.Equal(t, 2, problemAttempt.ErrCount)
+	assert.Equal(t, FullScore, problemAttempt.BestScore)
+}
+
+func TestRecoverJudgingSubmissions(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+	submissionDao := mock.NewMockSubmissionDao(mockCtl)
+	problemDao := mock.NewMockProblemDao(mockCtl)
+
+	// 服务重启前正在编译和运行的提交
+	submissions := make([]*po.Submission, 2)
+	for i, status := range []int{constants.Compiling, constants.Running} {
+		submissions[i] = &po.Submission{ProblemID: 1, Language: string(constants.LanguageC), Code: "int main() {}",
+			Status: status}
+		submissions[i].ID = uint(i + 1)
+	}
+	submissionDao.EXPECT().GetSubmissionsByStatus(gomock.Any(),
+		[]int{constants.Pending, constants.Compiling, constants.Running}).Return(submissions, nil)
+	problem := &po.Problem{}
+	problem.ID = 1
+	problemDao.EXPECT().GetProblemByID(gomock.Any(), uint(1)).Return(problem, nil)
+
+	tasks := make(chan *judgeTask, len(submissions))
+	j := &judgeService{
+		submissionDao: submissionDao,
+		problemDao:    problemDao,
+		progress:      newJudgeProgress(),
+	}
+	j.queue = newLocalJudgeQueue(len(submissions), 1, func(task *judgeTask) {
+		tasks <- task
+	})
+	j.recoverJudgingSubmissions()
+
+	// 重新加入判题队列，并且按照重判重新统计做题情况
+	for _, submission := range submissions {
+		task := <-tasks
+		assert.Equal(t, submission, task.submission)
+		assert.Equal(t, constants.Pending, task.submission.Status)
+		assert.Equal(t, problem, task.problem)
+		assert.Equal(t, "int main() {}", task.request.Code)
+		assert.True(t, task.rejudge)
+		// 订阅进度时能收到等待判题的状态，而不是直接结束
+		progressCh, cancel, ok := j.progress.subscribe(submission.ID)
+		assert.True(t, ok)
+		assert.Equal(t, &dto.JudgeProgressDto{SubmissionID: submission.ID, Status: constants.Pending}, <-progressCh)
+		cancel()
+	}
+}
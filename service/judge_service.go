@@ -9,32 +9,57 @@ import (
 	"FanCode/global"
 	"FanCode/models/dto"
 	"FanCode/models/po"
+	"FanCode/service/harness"
 	"FanCode/service/judger"
-	"FanCode/utils"
+	"bytes"
 	"errors"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"io"
 	"log"
 	"os"
 	"path"
-	"strings"
+	"sync/atomic"
 	time "time"
 )
 
 const (
-	// 限制时间和内存
+	// 限制时间和内存，题目没有设置限制时使用
 	LimitExecuteTime   = int64(15 * time.Second)
 	LimitExecuteMemory = 100 * 1024 * 1024
 	QuotaExecuteCpu    = 100000
+	// 题目可以设置的最大时间限制（毫秒）和内存限制（MB）
+	MaxProblemTimeLimit   = 60 * 1000
+	MaxProblemMemoryLimit = 2048
+	// 限制输出长度
+	LimitExecuteOutput = 16 * 1024 * 1024
 	// 限制编译时间
 	LimitCompileTime = int64(10 * time.Second)
+	// 限制特判程序的时间和内存
+	LimitCheckerTime   = int64(5 * time.Second)
+	LimitCheckerMemory = 256 * 1024 * 1024
+	// 保存每个用例的用户输出的最大长度
+	LimitCaseOutput = 4 * 1024
+	// 用例数据保存在对象存储中时，数据库中保存的预览的最大长度
+	LimitCasePreview = 4 * 1024
+	// 提交记录中保存的第一个不通过用例的输入和期望输出的最大长度
+	LimitSubmissionCaseData = 64 * 1024
+	// 多文件提交时文件数量和所有文件的总大小
+	MaxSourceFileCount = 64
+	MaxSourceFileSize  = 1024 * 1024
+	// 自测时一次最多运行的用例数量
+	MaxExecuteInputCount = 20
 )
 
 type JudgeService interface {
-	// Submit 答案提交
+	// Submit 答案提交，加入判题队列后立即返回等待判题的提交
 	Submit(ctx *gin.Context, judgeRequest *dto.SubmitRequestDto) (*dto.SubmitResultDto, *e.Error)
+	// SubscribeProgress 订阅提交的判题进度，判题完成后channel关闭，返回的函数用于取消订阅
+	SubscribeProgress(ctx *gin.Context, submissionID uint) (<-chan *dto.JudgeProgressDto, func(), *e.Error)
 	// Execute 执行
 	Execute(judgeRequest *dto.ExecuteRequestDto) (*dto.ExecuteResultDto, *e.Error)
+	// Rejudge 重判满足条件的所有提交，返回重判的提交数量
+	Rejudge(query *dto.RejudgeRequestDto) (int, *e.Error)
 }
 
 type judgeService struct {
@@ -43,51 +68,230 @@ type judgeService struct {
 	problemService    ProblemService
 	problemCaseDao    dao.ProblemCaseDao
 	submissionDao     dao.SubmissionDao
+	submissionCaseDao dao.SubmissionCaseDao
 	problemAttemptDao dao.ProblemAttemptDao
 	problemDao        dao.ProblemDao
+	queue             judgeQueue
+	progress          *judgeProgress
+	// 分布式判题时由worker通知api节点判题进度
+	notifier func(progress *dto.JudgeProgressDto)
 }
 
-func NewJudgeService(config *conf.AppConfig, ps ProblemService, sd dao.SubmissionDao,
+func NewJudgeService(config *conf.AppConfig, ps ProblemService, sd dao.SubmissionDao, scd dao.SubmissionCaseDao,
 	ad dao.ProblemAttemptDao, pd dao.ProblemDao, pcd dao.ProblemCaseDao) JudgeService {
+	j := newJudgeService(config, ps, sd, scd, ad, pd, pcd)
+	// 分布式判题时提交加入redis stream，并接收worker的判题进度
+	if config.JudgeConfig != nil && config.JudgeConfig.Distributed {
+		j.queue = newRedisJudgeQueue(global.Redis)
+		go subscribeJudgeProgress(global.Redis, j.progress)
+		return j
+	}
+	queueSize, workers := getJudgeQueueConfig(config)
+	j.queue = newLocalJudgeQueue(queueSize, workers, j.judge)
+	// 重新判题服务重启前没有完成的提交
+	go j.recoverJudgingSubmissions()
+	return j
+}
+
+func newJudgeService(config *conf.AppConfig, ps ProblemService, sd dao.SubmissionDao, scd dao.SubmissionCaseDao,
+	ad dao.ProblemAttemptDao, pd dao.ProblemDao, pcd dao.ProblemCaseDao) *judgeService {
 	return &judgeService{
 		config:            config,
-		judgeCore:         judger.NewJudgeCore(),
+		judgeCore:         judger.NewPooledJudgeCore(getCompileCache(config), getWorkerPool(config), getCPUSlots(config)),
 		problemService:    ps,
 		problemCaseDao:    pcd,
 		submissionDao:     sd,
+		submissionCaseDao: scd,
 		problemAttemptDao: ad,
 		problemDao:        pd,
+		progress:          newJudgeProgress(),
 	}
 }
 
 func (j *judgeService) Submit(ctx *gin.Context, judgeRequest *dto.SubmitRequestDto) (*dto.SubmitResultDto, *e.Error) {
-	// 提交获取结果
-	submission, err := j.submit(ctx, judgeRequest)
+	// 检测题目是否支持该语言
+	problem, err := j.problemDao.GetProblemByID(global.Mysql, judgeRequest.ProblemID)
 	if err != nil {
-		// Add logging for error
+		log.Printf("GetProblemByID error: %v\n", err)
+		return nil, e.ErrProblemNotExist
+	}
+	if !supportLanguage(problem, judgeRequest.Language) {
+		return nil, e.ErrLanguageNotSupported
+	}
+	// 检查多文件提交的文件列表
+	sources, checkErr := newSourceFiles(judgeRequest.Language, judgeRequest.Code, judgeRequest.Files,
+		judgeRequest.EntryFile)
+	if checkErr != nil {
+		return nil, checkErr
+	}
+	files, err := sources.marshal()
+	if err != nil {
+		log.Printf("marshal source files error: %v\n", err)
+		return nil, e.ErrUnknown
+	}
+
+	// 插入等待判题的提交，由判题队列中的worker完成判题
+	submission := &po.Submission{
+		Language:  string(judgeRequest.Language),
+		Code:      sources.code(),
+		ProblemID: judgeRequest.ProblemID,
+		UserID:    ctx.Keys["user"].(*dto.UserInfo).ID,
+		Status:    constants.Pending,
+	}
+	if sources.multiple {
+		submission.Files = files
+		submission.EntryFile = sources.entry
+	}
+	if err = j.submissionDao.InsertSubmission(global.Mysql, submission); err != nil {
+		log.Printf("InsertSubmission error: %v\n", err)
+		return nil, e.ErrSubmitFailed
+	}
+	// 加入队列后submission由worker修改，需要提前生成返回结果
+	result := dto.NewSubmitResultDto(submission)
+	j.progress.start(&dto.JudgeProgressDto{
+		SubmissionID: submission.ID,
+		Status:       constants.Pending,
+	})
+	if !j.queue.push(&judgeTask{submission: submission, problem: problem, request: judgeRequest}) {
+		submission.Status = constants.SystemError
+		submission.ErrorMessage = "加入判题队列失败"
+		if err = j.submissionDao.UpdateSubmission(global.Mysql, submission); err != nil {
+			log.Printf("UpdateSubmission error: %v\n", err)
+		}
+		j.progress.finish(&dto.JudgeProgressDto{
+			SubmissionID: submission.ID,
+			Status:       submission.Status,
+			Result:       dto.NewSubmitResultDto(submission),
+		})
+		return nil, e.ErrJudgeQueueFull
+	}
+	return result, nil
+}
+
+func (j *judgeService) SubscribeProgress(ctx *gin.Context, submissionID uint) (<-chan *dto.JudgeProgressDto, func(), *e.Error) {
+	user := ctx.Keys["user"].(*dto.UserInfo)
+	submission, err := j.submissionDao.GetSubmissionByID(global.Mysql, submissionID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, e.ErrSubmissionNotExist
+	}
+	if err != nil {
+		log.Printf("GetSubmissionByID error: %v\n", err)
+		return nil, nil, e.ErrMysql
+	}
+	// 只能查看自己的提交
+	if submission.UserID != user.ID {
+		return nil, nil, e.ErrSubmissionNotExist
+	}
+	if progressCh, cancel, ok := j.progress.subscribe(submissionID); ok {
+		return progressCh, cancel, nil
+	}
+
+	// 不在判题中，重新读取提交，避免读取后判题刚好完成
+	if submission, err = j.submissionDao.GetSubmissionByID(global.Mysql, submissionID); err != nil {
+		log.Printf("GetSubmissionByID error: %v\n", err)
+		return nil, nil, e.ErrMysql
+	}
+	progress := &dto.JudgeProgressDto{
+		SubmissionID: submissionID,
+		Status:       submission.Status,
+	}
+	if !isJudging(submission.Status) {
+		progress.Result = dto.NewSubmitResultDto(submission)
+	}
+	progressCh := make(chan *dto.JudgeProgressDto, 1)
+	progressCh <- progress
+	close(progressCh)
+	return progressCh, func() {}, nil
+}
+
+// judge 判题队列的worker完成判题，保存判题结果并通知订阅者
+func (j *judgeService) judge(task *judgeTask) {
+	submission := task.submission
+	if _, err := j.submit(submission, task.problem, task.request); err != nil {
 		log.Printf("Submit error: %v\n", err)
-		return nil, err
+		submission.Status = constants.SystemError
+		submission.ErrorMessage = err.Message
 	}
+	if err := j.saveSubmission(submission, task.rejudge); err != nil {
+		log.Printf("saveSubmission error: %v\n", err)
+	}
+	j.finishProgress(submission)
+}
+
+// finishProgress 判题完成，发送最终结果
+func (j *judgeService) finishProgress(submission *po.Submission) {
+	progress := &dto.JudgeProgressDto{
+		SubmissionID: submission.ID,
+		Status:       submission.Status,
+		Result:       dto.NewSubmitResultDto(submission),
+	}
+	j.progress.finish(progress)
+	if j.notifier != nil {
+		j.notifier(progress)
+	}
+}
 
-	// 插入提交数据
+// updateProgress 更新判题进度，状态变化时保存到数据库
+func (j *judgeService) updateProgress(submission *po.Submission, status int, caseIndex int, caseCount int) {
+	progress := &dto.JudgeProgressDto{
+		SubmissionID: submission.ID,
+		Status:       status,
+		Case:         caseIndex,
+		CaseCount:    caseCount,
+	}
+	changed := j.progress.publish(progress)
+	if j.notifier != nil {
+		j.notifier(progress)
+	}
+	if changed {
+		if err := j.submissionDao.UpdateSubmissionStatus(global.Mysql, submission.ID, status); err != nil {
+			log.Printf("UpdateSubmissionStatus error: %v\n", err)
+		}
+	}
+}
+
+// saveSubmission 保存判题结果以及每个用例的判题结果，并更新用户的做题情况。重判时根据所有提交重新统计做题情况
+func (j *judgeService) saveSubmission(submission *po.Submission, rejudge bool) *e.Error {
 	tx := global.Mysql.Begin()
-	_ = j.submissionDao.InsertSubmission(tx, submission)
+	if err := j.submissionDao.UpdateSubmission(tx, submission); err != nil {
+		tx.Rollback()
+		log.Printf("UpdateSubmission error: %v\n", err)
+		return e.ErrSubmitFailed
+	}
+	// 插入每个用例的判题结果
+	for _, submissionCase := range submission.Cases {
+		submissionCase.SubmissionID = submission.ID
+	}
+	if err := j.submissionCaseDao.InsertSubmissionCases(tx, submission.Cases); err != nil {
+		tx.Rollback()
+		log.Printf("InsertSubmissionCases error: %v\n", err)
+		return e.ErrSubmitFailed
+	}
+	if rejudge {
+		if err := j.rebuildProblemAttempt(tx, submission.UserID, submission.ProblemID); err != nil {
+			tx.Rollback()
+			return err
+		}
+		tx.Commit()
+		return nil
+	}
 
 	// 检测用户是否保存了attempt
-	userId := ctx.Keys["user"].(*dto.UserInfo).ID
-	problemAttempt, err2 := j.problemAttemptDao.GetProblemAttemptByID(tx, userId, judgeRequest.ProblemID)
+	problemAttempt, err2 := j.problemAttemptDao.GetProblemAttemptByID(tx, submission.UserID, submission.ProblemID)
 	if err2 != nil && !errors.Is(err2, gorm.ErrRecordNotFound) {
-		return nil, e.ErrSubmitFailed
+		tx.Rollback()
+		return e.ErrSubmitFailed
 	}
 
 	// 如果本身就没有记录，就添加
 	if errors.Is(err2, gorm.ErrRecordNotFound) {
 		problemAttempt = &po.ProblemAttempt{
-			UserID:    userId,
-			ProblemID: judgeRequest.ProblemID,
-			Code:      judgeRequest.Code,
-			Language:  string(judgeRequest.Language),
+			UserID:    submission.UserID,
+			ProblemID: submission.ProblemID,
+			Code:      submission.Code,
+			Language:  submission.Language,
 			Status:    constants.InProgress,
+			BestScore: submission.Score,
 		}
 		problemAttempt.SubmissionCount++
 		if submission.Status == constants.Accepted {
@@ -95,7 +299,7 @@ func (j *judgeService) Submit(ctx *gin.Context, judgeRequest *dto.SubmitRequestD
 		} else {
 			problemAttempt.ErrCount++
 		}
-		problemAttempt.Code = judgeRequest.Code
+		problemAttempt.Code = submission.Code
 		if problemAttempt.Status == 0 && submission.Status == constants.Accepted {
 			problemAttempt.Status = 1
 		}
@@ -104,14 +308,14 @@ func (j *judgeService) Submit(ctx *gin.Context, judgeRequest *dto.SubmitRequestD
 			tx.Rollback()
 			// Add logging for error
 			log.Printf("InsertProblemAttempt error: %v\n", err2)
-			return nil, e.ErrSubmitFailed
+			return e.ErrSubmitFailed
 		}
 		tx.Commit()
-		return dto.NewSubmitResultDto(submission), nil
+		return nil
 	}
 
-	problemAttempt.Code = judgeRequest.Code
-	problemAttempt.Language = string(judgeRequest.Language)
+	problemAttempt.Code = submission.Code
+	problemAttempt.Language = submission.Language
 	// 有记录则更新
 	problemAttempt.SubmissionCount++
 	if submission.Status == constants.Accepted {
@@ -123,23 +327,27 @@ func (j *judgeService) Submit(ctx *gin.Context, judgeRequest *dto.SubmitRequestD
 		}
 		problemAttempt.ErrCount++
 	}
+	// 记录最高得分
+	if submission.Score > problemAttempt.BestScore {
+		problemAttempt.BestScore = submission.Score
+	}
 	if err2 = j.problemAttemptDao.UpdateProblemAttempt(tx, problemAttempt); err2 != nil {
 		tx.Rollback()
 		// Add logging for error
 		log.Printf("UpdateProblemAttempt error: %v\n", err2)
-		return nil, e.ErrSubmitFailed
+		return e.ErrSubmitFailed
 	}
 	tx.Commit()
-	return dto.NewSubmitResultDto(submission), nil
+	return nil
 }
 
-func (j *judgeService) submit(ctx *gin.Context, judgeRequest *dto.SubmitRequestDto) (*po.Submission, *e.Error) {
-	// 提交结果对象
-	submission := &po.Submission{
-		Language:  string(judgeRequest.Language),
-		Code:      judgeRequest.Code,
-		ProblemID: judgeRequest.ProblemID,
-		UserID:    ctx.Keys["user"].(*dto.UserInfo).ID,
+// submit 编译并运行所有用例，判题结果保存在submission中
+func (j *judgeService) submit(submission *po.Submission, problem *po.Problem,
+	judgeRequest *dto.SubmitRequestDto) (*po.Submission, *e.Error) {
+	subtasks, err4 := parseSubtasks(problem.Subtasks)
+	if err4 != nil {
+		log.Printf("parseSubtasks error: %v\n", err4)
+		return nil, e.ErrUnknown
 	}
 
 	// executePath 执行路径，用户的临时文件
@@ -152,10 +360,16 @@ func (j *judgeService) submit(ctx *gin.Context, judgeRequest *dto.SubmitRequestD
 	defer os.RemoveAll(executePath)
 
 	// 保存用户代码到用户的执行路径，并获取编译文件列表
-	var compileFiles []string
+	var sources *sourceFiles
+	var compileFiles, includeFiles []string
 	var err2 *e.Error
-	if compileFiles, err2 = j.saveUserCode(judgeRequest.Language,
-		judgeRequest.Code, executePath); err2 != nil {
+	if sources, err2 = newSourceFiles(judgeRequest.Language, judgeRequest.Code, judgeRequest.Files,
+		judgeRequest.EntryFile); err2 != nil {
+		log.Printf("newSourceFiles error: %v\n", err2)
+		return nil, err2
+	}
+	if compileFiles, includeFiles, err2 = j.saveUserCode(problem, judgeRequest.Language, sources,
+		executePath); err2 != nil {
 		// Add logging for error
 		log.Printf("SaveUserCode error: %v\n", err2)
 		return nil, err2
@@ -165,10 +379,12 @@ func (j *judgeService) submit(ctx *gin.Context, judgeRequest *dto.SubmitRequestD
 	executeFilePath := path.Join(executePath, "main")
 
 	// 执行编译
+	j.updateProgress(submission, constants.Compiling, 0, 0)
 	compileOptions := &judger.CompileOptions{
 		ExcludedPaths: []string{executePath},
 		Language:      judgeRequest.Language,
 		LimitTime:     LimitCompileTime,
+		IncludeFiles:  includeFiles,
 	}
 	var compileResult *judger.CompileResult
 	var err error
@@ -183,6 +399,68 @@ func (j *judgeService) submit(ctx *gin.Context, judgeRequest *dto.SubmitRequestD
 		return submission, nil
 	}
 
+	// 编译特判程序，特判程序放在单独的目录中，用户程序无法读取
+	var checkerResult *judger.CompileResult
+	var checkOptions *judger.ExecuteOptions
+	if problem.Checker != "" {
+		checkerPath := getExecutePath(j.config)
+		if err = os.MkdirAll(checkerPath, os.ModePerm); err != nil {
+			log.Printf("MkdirAll error: %v\n", err)
+			return nil, e.ErrExecuteFailed
+		}
+		defer os.RemoveAll(checkerPath)
+		if checkerResult, err2 = j.compileChecker(constants.LanguageType(problem.CheckerLanguage), problem.Checker,
+			checkerPath); err2 != nil {
+			return nil, err2
+		}
+		if !checkerResult.Compiled {
+			submission.Status = constants.SystemError
+			submission.ErrorMessage = "特判程序编译失败\n" + checkerResult.ErrorMessage
+			return submission, nil
+		}
+		checkOptions = &judger.ExecuteOptions{
+			Language:      constants.LanguageType(problem.CheckerLanguage),
+			LimitTime:     LimitCheckerTime,
+			MemoryLimit:   LimitCheckerMemory,
+			CPUQuota:      QuotaExecuteCpu,
+			OutputLimit:   LimitExecuteOutput,
+			ExcludedPaths: []string{checkerPath},
+			Sandbox:       getSandboxOptions(j.config),
+			Seccomp:       isSeccompEnabled(j.config),
+		}
+	}
+
+	// 编译交互程序，和特判程序一样放在单独的目录中
+	var interactorResult *judger.CompileResult
+	var interactorOptions *judger.ExecuteOptions
+	if problem.Interactor != "" {
+		interactorPath := getExecutePath(j.config)
+		if err = os.MkdirAll(interactorPath, os.ModePerm); err != nil {
+			log.Printf("MkdirAll error: %v\n", err)
+			return nil, e.ErrExecuteFailed
+		}
+		defer os.RemoveAll(interactorPath)
+		if interactorResult, err2 = j.compileChecker(constants.LanguageType(problem.InteractorLanguage), problem.Interactor,
+			interactorPath); err2 != nil {
+			return nil, err2
+		}
+		if !interactorResult.Compiled {
+			submission.Status = constants.SystemError
+			submission.ErrorMessage = "交互程序编译失败\n" + interactorResult.ErrorMessage
+			return submission, nil
+		}
+		interactorOptions = &judger.ExecuteOptions{
+			Language:      constants.LanguageType(problem.InteractorLanguage),
+			LimitTime:     LimitCheckerTime,
+			MemoryLimit:   LimitCheckerMemory,
+			CPUQuota:      QuotaExecuteCpu,
+			OutputLimit:   LimitExecuteOutput,
+			ExcludedPaths: []string{interactorPath},
+			Sandbox:       getSandboxOptions(j.config),
+			Seccomp:       isSeccompEnabled(j.config),
+		}
+	}
+
 	// 运行
 	caseList, err := j.problemCaseDao.GetProblemCaseList2(global.Mysql, judgeRequest.ProblemID)
 	if err != nil {
@@ -190,97 +468,317 @@ func (j *judgeService) submit(ctx *gin.Context, judgeRequest *dto.SubmitRequestD
 		log.Printf("GetProblemCaseList2 error: %v\n", err)
 		return nil, e.ErrUnknown
 	}
-	inputCh := make(chan []byte)
-	outputCh := make(chan judger.ExecuteResult)
-	exitCh := make(chan string)
-	defer func() {
-		exitCh <- "exit"
-	}()
+	// 用例数据下载到本地缓存，运行时直接作为标准输入
+	cases, err := loadCaseData(j.config, caseList)
+	if err != nil {
+		log.Printf("loadCaseData error: %v\n", err)
+		return nil, e.ErrUnknown
+	}
+	// 有子任务时需要所有用例的结果计算得分
+	runAllCases := problem.RunAllCases || judgeRequest.RunAllCases || len(subtasks) != 0
+	limitTime, memoryLimit, stackLimit := getExecuteLimit(problem, judgeRequest.Language)
 	executeOption := &judger.ExecuteOptions{
 		Language:      judgeRequest.Language,
-		LimitTime:     LimitExecuteTime,
-		MemoryLimit:   LimitExecuteMemory,
+		LimitTime:     limitTime,
+		MemoryLimit:   memoryLimit,
+		StackLimit:    stackLimit,
 		CPUQuota:      QuotaExecuteCpu,
+		OutputLimit:   LimitExecuteOutput,
 		ExcludedPaths: []string{executePath},
-	}
-	// 运行可执行文件
-	if err = j.judgeCore.Execute(executeFilePath, inputCh, outputCh, exitCh, executeOption); err != nil {
+		Sandbox:       getSandboxOptions(j.config),
+		Seccomp:       isSeccompEnabled(j.config),
+	}
+	// 交互题每个用例都需要重新启动用户程序和交互程序
+	if interactorResult != nil {
+		return j.interact(submission, compileResult.CompiledFilePath, interactorResult.CompiledFilePath, cases,
+			executeOption, interactorOptions, runAllCases, subtasks)
+	}
+	// 并发运行所有用例，运行可执行文件，解释型语言运行main文件
+	inputs := make([]judger.CaseInput, len(cases))
+	for i, c := range cases {
+		inputs[i] = c.input()
+	}
+	results := make([]*caseResult, len(caseList))
+	var completed int32
+	j.updateProgress(submission, constants.Running, 0, len(caseList))
+	err = j.judgeCore.ExecuteCases(compileResult.CompiledFilePath, inputs, getCaseParallelism(j.config), executeOption,
+		func(i int, executeResult judger.ExecuteResult) bool {
+			result := j.judgeCase(problem, cases[i], executeResult, checkerResult, checkOptions)
+			results[i] = result
+			j.updateProgress(submission, constants.Running, int(atomic.AddInt32(&completed, 1)), len(caseList))
+			// 结果不正确并且不需要运行所有用例时不再运行后面的用例
+			return runAllCases || (result.err == nil && result.status == constants.Accepted)
+		})
+	if err != nil {
 		// Add logging for error
-		log.Printf("Execute error: %v\n", err)
+		log.Printf("ExecuteCases error: %v\n", err)
 		return nil, e.ErrUnknown
 	}
 
-	beginTime := time.Now()
-	for _, c := range caseList {
-		// 输入数据
-		inputCh <- []byte(c.Input)
+	// 按照用例顺序记录结果，保证第一个不通过的用例和顺序运行时相同
+	for i, c := range cases {
+		result := results[i]
+		if result.err != nil {
+			log.Printf("Check error: %v\n", result.err)
+			return nil, e.ErrUnknown
+		}
+		if checkerResult != nil && isJudging(submission.Status) {
+			submission.CheckerMessage = result.message
+		}
+		// 结果不正确并且不需要运行所有用例则结束
+		if !recordCase(submission, c, result.status, &result.execute, result.message) && !runAllCases {
+			return submission, nil
+		}
+	}
+	finishSubmission(submission, subtasks)
+	return submission, nil
+}
 
-		// 读取输出数据
-		select {
-		case executeResult := <-outputCh:
-			// 运行出错
-			if !executeResult.Executed {
-				submission.Status = constants.RuntimeError
-				submission.ErrorMessage = executeResult.ErrorMessage
-				return submission, nil
-			}
+// caseResult 一个用例的判题结果
+type caseResult struct {
+	status  int
+	message string
+	execute judger.ExecuteResult
+	err     error
+}
 
-			// 结果不正确则结束
-			if !j.compareAnswer(string(executeResult.Output), c.Output) {
-				submission.Status = constants.WrongAnswer
-				submission.CaseName = c.Name
-				submission.CaseData = c.Input
-				submission.ExpectedOutput = c.Output
-				submission.UserOutput = string(executeResult.Output)
-				return submission, nil
-			}
+// judgeCase 判断一个用例的运行结果，有特判程序时由特判程序检查，否则按照题目的比较方式比较
+func (j *judgeService) judgeCase(problem *po.Problem, c *caseData, executeResult judger.ExecuteResult,
+	checkerResult *judger.CompileResult, checkOptions *judger.ExecuteOptions) *caseResult {
+	result := &caseResult{execute: executeResult}
+	if !executeResult.Executed {
+		// 运行出错，包括超时、内存超限、运行时错误等
+		result.status, result.message = executeResult.Verdict, executeResult.ErrorMessage
+		return result
+	}
+	expected, err := c.openOutput()
+	if err != nil {
+		result.err = err
+		return result
+	}
+	defer expected.Close()
+	if checkerResult != nil {
+		var input io.ReadCloser
+		if input, err = c.openInput(); err != nil {
+			result.err = err
+			return result
+		}
+		defer input.Close()
+		checkResult, err := j.judgeCore.Check(checkerResult.CompiledFilePath, input, executeResult.Output, expected,
+			checkOptions)
+		if err != nil {
+			result.err = err
+			return result
+		}
+		result.status, result.message = checkResult.Verdict, checkResult.Message
+	} else {
+		// 期望输出可能很大，按照题目的比较方式流式比较
+		if result.status, err = compareOutputStream(constants.ComparatorType(problem.Comparator), problem.Epsilon,
+			bytes.NewReader(executeResult.Output), expected); err != nil {
+			result.err = err
+			return result
 		}
 	}
-	endTime := time.Now()
-	submission.Status = constants.Accepted
-	submission.TimeUsed = endTime.Sub(beginTime)
+	// 通过的用例只保存截断后的输出，减少并发运行时占用的内存
+	if result.status == constants.Accepted && len(executeResult.Output) > LimitCaseOutput {
+		result.execute.Output = []byte(truncateOutput(executeResult.Output, LimitCaseOutput))
+	}
+	return result
+}
+
+// isJudging 是否为等待判题或者判题中的状态
+func isJudging(status int) bool {
+	return status == constants.Pending || status == constants.Compiling || status == constants.Running
+}
+
+// finishSubmission 用例运行完成后设置判题结果和得分，没有不通过的用例时为Accepted
+func finishSubmission(submission *po.Submission, subtasks []*po.Subtask) {
+	if isJudging(submission.Status) {
+		submission.Status = constants.Accepted
+	}
+	submission.Score = computeScore(subtasks, submission.Cases, submission.Status)
+}
+
+// recordCase 记录用例的判题结果以及所有用例中cpu时间和内存的最大值，
+// 第一个不通过的用例的详细信息保存在submission中，返回用例是否通过
+func recordCase(submission *po.Submission, c *caseData, status int, executeResult *judger.ExecuteResult,
+	message string) bool {
+	usedTime := time.Duration(executeResult.UsedCpuTime)
+	if usedTime > submission.TimeUsed {
+		submission.TimeUsed = usedTime
+	}
+	if executeResult.UsedMemory > submission.MemoryUsed {
+		submission.MemoryUsed = executeResult.UsedMemory
+	}
+	submission.Cases = append(submission.Cases, &po.SubmissionCase{
+		CaseID:     c.ID,
+		CaseName:   c.Name,
+		Subtask:    c.Subtask,
+		Status:     status,
+		TimeUsed:   usedTime,
+		MemoryUsed: executeResult.UsedMemory,
+		UserOutput: truncateOutput(executeResult.Output, LimitCaseOutput),
+		Message:    message,
+	})
+	if status == constants.Accepted {
+		return true
+	}
+	// 已经有不通过的用例
+	if !isJudging(submission.Status) {
+		return false
+	}
+	submission.Status = status
+	submission.ExitCode = executeResult.ExitCode
+	submission.Signal = executeResult.Signal
+	submission.CaseName = c.Name
+	submission.CaseData = readCaseData(c.openInput, c.Input)
+	if status == constants.WrongAnswer || status == constants.PresentationError {
+		submission.ExpectedOutput = readCaseData(c.openOutput, c.Output)
+		submission.UserOutput = string(executeResult.Output)
+	} else {
+		submission.ErrorMessage = message
+	}
+	return false
+}
+
+// interact 运行交互题的用例，runAllCases为false时遇到不通过的用例就结束
+func (j *judgeService) interact(submission *po.Submission, execFile string, interactorFile string,
+	caseList []*caseData, executeOption *judger.ExecuteOptions, interactorOptions *judger.ExecuteOptions,
+	runAllCases bool, subtasks []*po.Subtask) (*po.Submission, *e.Error) {
+	for i, c := range caseList {
+		j.updateProgress(submission, constants.Running, i, len(caseList))
+		interactResult, err := j.interactCase(execFile, interactorFile, c, executeOption, interactorOptions)
+		if err != nil {
+			log.Printf("Interact error: %v\n", err)
+			return nil, e.ErrUnknown
+		}
+		if isJudging(submission.Status) {
+			submission.CheckerMessage = interactResult.Message
+		}
+		if !recordCase(submission, c, interactResult.Verdict, &interactResult.Execute,
+			interactResult.Message) && !runAllCases {
+			return submission, nil
+		}
+	}
+	finishSubmission(submission, subtasks)
 	return submission, nil
 }
 
-// saveUserCode
-// 保存用户代码到用户的executePath，并返回需要编译的文件列表
-func (j *judgeService) saveUserCode(language constants.LanguageType, codeStr string, executePath string) ([]string, *e.Error) {
-	var compileFiles []string
-	var mainFile string
-	var err2 *e.Error
+// interactCase 运行交互题的一个用例，输入和期望输出传递给交互程序
+func (j *judgeService) interactCase(execFile string, interactorFile string, c *caseData,
+	executeOption *judger.ExecuteOptions, interactorOptions *judger.ExecuteOptions) (*judger.InteractResult, error) {
+	input, err := c.openInput()
+	if err != nil {
+		return nil, err
+	}
+	defer input.Close()
+	expected, err := c.openOutput()
+	if err != nil {
+		return nil, err
+	}
+	defer expected.Close()
+	return j.judgeCore.Interact(execFile, interactorFile, input, expected, executeOption, interactorOptions)
+}
 
-	if mainFile, err2 = getMainFileNameByLanguage(language); err2 != nil {
-		log.Println(err2)
-		return nil, err2
+// compileChecker 编译题目的特判程序或者交互程序，使用编译缓存时相同的程序只编译一次
+func (j *judgeService) compileChecker(language constants.LanguageType, code string, checkerPath string) (*judger.CompileResult, *e.Error) {
+	mainFile, err := getMainFileNameByLanguage(language)
+	if err != nil {
+		return nil, err
 	}
-	if err := os.WriteFile(path.Join(executePath, mainFile), []byte(codeStr), 0644); err != nil {
+	checkerFile := path.Join(checkerPath, mainFile)
+	if err := os.WriteFile(checkerFile, []byte(code), 0644); err != nil {
 		log.Println(err)
 		return nil, e.ErrServer
 	}
-	// 将main文件进行编译即可
-	compileFiles = []string{path.Join(executePath, mainFile)}
+	// 将testlib.h等头文件复制到特判程序所在目录
+	if j.config.JudgeConfig != nil && j.config.JudgeConfig.CheckerHeader != "" {
+		header, err := os.ReadFile(j.config.JudgeConfig.CheckerHeader)
+		if err != nil {
+			log.Println(err)
+			return nil, e.ErrServer
+		}
+		if err = os.WriteFile(path.Join(checkerPath, path.Base(j.config.JudgeConfig.CheckerHeader)), header, 0644); err != nil {
+			log.Println(err)
+			return nil, e.ErrServer
+		}
+	}
+	compileOptions := &judger.CompileOptions{
+		Language:      language,
+		LimitTime:     LimitCompileTime,
+		ExcludedPaths: []string{checkerPath},
+	}
+	compileResult, err2 := j.judgeCore.Compile([]string{checkerFile}, path.Join(checkerPath, "checker"), compileOptions)
+	if err2 != nil {
+		log.Printf("Compile checker error: %v\n", err2)
+		return nil, e.ErrUnknown
+	}
+	return compileResult, nil
+}
 
-	return compileFiles, nil
+// saveUserCode
+// 保存用户代码到用户的executePath，并返回需要编译的文件列表以及不需要编译的文件列表。
+// 核心代码模式的题目根据函数签名和入口文件生成完整的程序，problem为nil时按照acm模式保存
+func (j *judgeService) saveUserCode(problem *po.Problem, language constants.LanguageType, sources *sourceFiles,
+	executePath string) ([]string, []string, *e.Error) {
+	signature, err := parseFunctionSignature(problem)
+	if err != nil {
+		log.Printf("parseFunctionSignature error: %v\n", err)
+		return nil, nil, e.ErrUnknown
+	}
+	if signature != nil {
+		var generated []*harness.SourceFile
+		if generated, err = harness.Generate(language, signature, sources.entry, sources.code()); err != nil {
+			log.Println(err)
+			return nil, nil, e.ErrLanguageNotSupported
+		}
+		files := make(map[string]string, len(sources.files)+len(generated))
+		for name, code := range sources.files {
+			files[name] = code
+		}
+		for _, file := range generated {
+			// 生成的文件不能覆盖用户的其他文件
+			if _, ok := files[file.Name]; ok && file.Name != sources.entry {
+				return nil, nil, e.ErrBadRequest
+			}
+			files[file.Name] = file.Code
+		}
+		sources = &sourceFiles{entry: sources.entry, files: files, multiple: true}
+	}
+	compileFiles, includeFiles, err := sources.save(executePath)
+	if err != nil {
+		log.Println(err)
+		return nil, nil, e.ErrServer
+	}
+	return compileFiles, includeFiles, nil
 }
 
 // 根据编程语言获取该编程语言的Main文件名称
 func getMainFileNameByLanguage(language constants.LanguageType) (string, *e.Error) {
-	switch language {
-	case constants.LanguageC:
-		return "main.c", nil
-	case constants.LanguageJava:
-		return "Main.java", nil
-	case constants.LanguageGo:
-		return "main.go", nil
-	default:
+	spec, ok := judger.GetLanguageSpec(language)
+	if !ok {
 		return "", e.ErrLanguageNotSupported
 	}
+	return spec.SourceFile, nil
 }
 
 func (j *judgeService) Execute(judgeRequest *dto.ExecuteRequestDto) (*dto.ExecuteResultDto, *e.Error) {
 	executeResult := &dto.ExecuteResultDto{
 		ProblemID: judgeRequest.ProblemID,
 	}
+	// 没有设置多个用例时只运行Input
+	executeInputs := judgeRequest.Inputs
+	if len(executeInputs) == 0 {
+		executeInputs = []*dto.ExecuteInputDto{{Input: judgeRequest.Input}}
+	}
+	if len(executeInputs) > MaxExecuteInputCount {
+		return nil, e.ErrBadRequest
+	}
+	for _, input := range executeInputs {
+		if input == nil {
+			return nil, e.ErrBadRequest
+		}
+	}
 
 	// executePath 用户执行目录
 	executePath := getExecutePath(j.config)
@@ -290,10 +788,26 @@ func (j *judgeService) Execute(judgeRequest *dto.ExecuteRequestDto) (*dto.Execut
 	}
 	defer os.RemoveAll(executePath)
 
+	// 运行题目时使用题目的限制，核心代码模式的题目需要生成完整的程序
+	var problem *po.Problem
+	var err2 error
+	if judgeRequest.ProblemID != 0 {
+		if problem, err2 = j.problemDao.GetProblemByID(global.Mysql, judgeRequest.ProblemID); err2 != nil {
+			log.Printf("GetProblemByID error: %v\n", err2)
+			problem = nil
+		}
+	}
+
 	// 保存用户代码到用户的执行路径，并获取编译文件列表
-	var compileFiles []string
+	var sources *sourceFiles
+	var compileFiles, includeFiles []string
 	var err *e.Error
-	if compileFiles, err = j.saveUserCode(judgeRequest.Language, judgeRequest.Code, executePath); err != nil {
+	if sources, err = newSourceFiles(judgeRequest.Language, judgeRequest.Code, judgeRequest.Files,
+		judgeRequest.EntryFile); err != nil {
+		return nil, err
+	}
+	if compileFiles, includeFiles, err = j.saveUserCode(problem, judgeRequest.Language, sources,
+		executePath); err != nil {
 		log.Println(err)
 		return nil, err
 	}
@@ -306,9 +820,9 @@ func (j *judgeService) Execute(judgeRequest *dto.ExecuteRequestDto) (*dto.Execut
 		Language:      judgeRequest.Language,
 		LimitTime:     LimitCompileTime,
 		ExcludedPaths: []string{executePath},
+		IncludeFiles:  includeFiles,
 	}
 	var compileResult *judger.CompileResult
-	var err2 error
 	if compileResult, err2 = j.judgeCore.Compile(compileFiles, executeFilePath, compileOptions); err2 != nil {
 		log.Println(err2)
 		return nil, e.ErrUnknown
@@ -319,37 +833,83 @@ func (j *judgeService) Execute(judgeRequest *dto.ExecuteRequestDto) (*dto.Execut
 		return executeResult, nil
 	}
 
-	//执行
-	inputCh := make(chan []byte)
-	outputCh := make(chan judger.ExecuteResult)
-	exitCh := make(chan string)
-	defer func() {
-		exitCh <- "exit"
-	}()
+	// 并发运行所有用例
+	limitTime, memoryLimit, stackLimit := getExecuteLimit(problem, judgeRequest.Language)
 	executeOptions := &judger.ExecuteOptions{
 		Language:      judgeRequest.Language,
-		LimitTime:     LimitExecuteTime,
-		MemoryLimit:   LimitExecuteMemory,
+		LimitTime:     limitTime,
+		MemoryLimit:   memoryLimit,
+		StackLimit:    stackLimit,
 		CPUQuota:      QuotaExecuteCpu,
+		OutputLimit:   LimitExecuteOutput,
 		ExcludedPaths: []string{executePath},
-	}
-	if err2 = j.judgeCore.Execute(executeFilePath, inputCh, outputCh, exitCh, executeOptions); err2 != nil {
+		Sandbox:       getSandboxOptions(j.config),
+		Seccomp:       isSeccompEnabled(j.config),
+	}
+	inputs := make([]judger.CaseInput, len(executeInputs))
+	for i, input := range executeInputs {
+		inputs[i] = judger.BytesInput([]byte(input.Input))
+	}
+	executeResult.Results = make([]*dto.ExecuteCaseResultDto, len(executeInputs))
+	err2 = j.judgeCore.ExecuteCases(compileResult.CompiledFilePath, inputs, getCaseParallelism(j.config), executeOptions,
+		func(i int, output judger.ExecuteResult) bool {
+			executeResult.Results[i] = newExecuteCaseResult(problem, executeInputs[i], &output)
+			return true
+		})
+	if err2 != nil {
+		log.Printf("ExecuteCases error: %v\n", err2)
 		return nil, e.ErrUnknown
 	}
 
-	// 输入输入用例
-	inputCh <- []byte(judgeRequest.Input)
-	output := <-outputCh
+	// 兼容只有一个用例的请求，返回第一个用例的结果
+	first := executeResult.Results[0]
+	executeResult.Status = first.Status
+	executeResult.ErrorMessage = first.ErrorMessage
+	executeResult.ExitCode = first.ExitCode
+	executeResult.Signal = first.Signal
+	executeResult.UserOutput = first.UserOutput
+	executeResult.OutputTruncated = first.OutputTruncated
+	return executeResult, nil
+}
 
-	if !output.Executed {
-		executeResult.Status = constants.RuntimeError
-		executeResult.ErrorMessage = output.ErrorMessage
-		return executeResult, nil
+// newExecuteCaseResult 生成一个自测用例的运行结果，设置了期望输出时按照题目的比较方式比较，没有题目时使用默认的比较方式
+func newExecuteCaseResult(problem *po.Problem, input *dto.ExecuteInputDto,
+	output *judger.ExecuteResult) *dto.ExecuteCaseResultDto {
+	result := &dto.ExecuteCaseResultDto{
+		Status:       uint(output.Verdict),
+		ErrorMessage: output.ErrorMessage,
+		ExitCode:     output.ExitCode,
+		Signal:       output.Signal,
+		ErrorOutput:  output.ErrorOutput,
+		TimeUsed:     time.Duration(output.UsedCpuTime),
+		MemoryUsed:   output.UsedMemory,
 	}
-
-	executeResult.Status = constants.RunSuccess
-	executeResult.UserOutput = string(output.Output)
-	return executeResult, nil
+	if !output.Executed {
+		// 输出超限时返回截断后的输出
+		if output.OutputTruncated {
+			result.UserOutput = string(output.Output)
+			result.OutputTruncated = true
+		}
+		return result
+	}
+	result.Status = constants.RunSuccess
+	result.UserOutput = string(output.Output)
+	if input.ExpectedOutput == nil {
+		return result
+	}
+	var comparator constants.ComparatorType
+	var epsilon float64
+	if problem != nil {
+		comparator = constants.ComparatorType(problem.Comparator)
+		epsilon = problem.Epsilon
+	}
+	result.Verdict = compareOutput(comparator, epsilon, result.UserOutput, *input.ExpectedOutput)
+	if result.Verdict != constants.Accepted {
+		diff := &dto.ExecuteDiffDto{}
+		diff.Line, diff.Output, diff.Expected = firstDiffLine(result.UserOutput, *input.ExpectedOutput)
+		result.Diff = diff
+	}
+	return result
 }
 
 func (j *judgeService) SaveCode(ctx *gin.Context, problemID uint, language string, code string) *e.Error {
@@ -395,24 +955,18 @@ func (j *judgeService) SaveCode(ctx *gin.Context, problemID uint, language strin
 	return nil
 }
 
-// 比较用户的答案，忽略\n和空格
-func (j *judgeService) compareAnswer(data1 string, data2 string) bool {
-	data1 = strings.Trim(data1, " ")
-	data1 = strings.Trim(data1, "\n")
-	data2 = strings.Trim(data2, " ")
-	data2 = strings.Trim(data2, "\n")
-	return data1 == data2
-}
-
 func checkAndDownloadQuestionFile(config *conf.AppConfig, questionPath string) error {
 	localPath := path.Join(config.FilePathConfig.ProblemFileDir, questionPath)
-	if !utils.CheckFolderExists(localPath) {
-		// 拉取文件
-		store := file_store.NewProblemCOS(config.COSConfig)
-		if err := store.DownloadFolder(questionPath, localPath); err != nil {
-			log.Println(err)
+	// 拉取文件
+	err := checkAndDownload(localPath, func(tmpPath string) error {
+		if err := os.MkdirAll(tmpPath, os.ModePerm); err != nil {
 			return err
 		}
+		store := file_store.NewProblemCOS(config.COSConfig)
+		return store.DownloadFolder(questionPath, tmpPath)
+	})
+	if err != nil {
+		log.Println(err)
 	}
-	return nil
+	return err
 }
@@ -0,0 +1,399 @@
+package service
+
+import (
+	conf "FanCode/config"
+	"FanCode/constants"
+	"FanCode/dao"
+	"FanCode/global"
+	"FanCode/models/dto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis"
+	"gorm.io/gorm"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// 分发提交的stream和worker组
+	judgeStreamKey   = "fancode:judge:stream"
+	judgeStreamGroup = "judge-workers"
+	// stream的近似最大长度，已经确认的消息超出长度后会被删除
+	judgeStreamMaxLen = 100000
+	// 判题进度的发布订阅频道
+	judgeProgressChannel = "fancode:judge:progress"
+	// worker心跳的key前缀，key的过期时间为3个心跳间隔
+	judgeWorkerKeyPrefix = "fancode:judge:worker:"
+	// 一个提交最多投递的次数，超出后认为提交会导致worker异常退出，直接判为系统错误
+	judgeMaxDeliveries = 3
+	// 读取stream的阻塞时间，worker停止时最多等待这么久
+	judgeReadBlock = 5 * time.Second
+)
+
+// redisJudgeQueue 基于redis stream的判题队列，提交由单独部署的判题worker消费
+type redisJudgeQueue struct {
+	client *redis.Client
+}
+
+func newRedisJudgeQueue(client *redis.Client) *redisJudgeQueue {
+	return &redisJudgeQueue{client: client}
+}
+
+func (q *redisJudgeQueue) push(task *judgeTask) bool {
+	err := q.client.XAdd(&redis.XAddArgs{
+		Stream:       judgeStreamKey,
+		MaxLenApprox: judgeStreamMaxLen,
+		Values: map[string]interface{}{
+			"submissionID": strconv.FormatUint(uint64(task.submission.ID), 10),
+			"runAllCases":  strconv.FormatBool(task.request.RunAllCases),
+			"rejudge":      strconv.FormatBool(task.rejudge),
+		},
+	}).Err()
+	if err != nil {
+		log.Printf("XAdd error: %v\n", err)
+		return false
+	}
+	return true
+}
+
+func (q *redisJudgeQueue) pushWait(task *judgeTask) bool {
+	return q.push(task)
+}
+
+// publishJudgeProgress 发布判题进度，由api节点转发给订阅者
+func publishJudgeProgress(client *redis.Client, progress *dto.JudgeProgressDto) {
+	message, err := json.Marshal(progress)
+	if err != nil {
+		log.Printf("Marshal error: %v\n", err)
+		return
+	}
+	if err = client.Publish(judgeProgressChannel, message).Err(); err != nil {
+		log.Printf("Publish error: %v\n", err)
+	}
+}
+
+// subscribeJudgeProgress 接收worker发布的判题进度，更新本地的判题进度
+func subscribeJudgeProgress(client *redis.Client, progress *judgeProgress) {
+	pubsub := client.Subscribe(judgeProgressChannel)
+	defer pubsub.Close()
+	for message := range pubsub.Channel() {
+		var p dto.JudgeProgressDto
+		if err := json.Unmarshal([]byte(message.Payload), &p); err != nil {
+			log.Printf("Unmarshal error: %v\n", err)
+			continue
+		}
+		if p.Result != nil {
+			progress.finish(&p)
+		} else {
+			progress.update(&p)
+		}
+	}
+}
+
+// JudgeWorker 判题worker，从redis stream中读取提交进行判题，判题结果写回数据库。
+// 判题完成后才确认消息，worker异常退出时，其他worker在它的心跳过期后重新投递它没有确认的消息
+type JudgeWorker struct {
+	judgeService *judgeService
+	client       *redis.Client
+	consumer     string
+	workers      int
+	heartbeat    time.Duration
+
+	mu        sync.Mutex
+	inFlight  map[string]struct{}
+	lastClaim time.Time
+	stop      chan struct{}
+	stopOnce  sync.Once
+}
+
+func NewJudgeWorker(config *conf.AppConfig, ps ProblemService, sd dao.SubmissionDao, scd dao.SubmissionCaseDao,
+	ad dao.ProblemAttemptDao, pd dao.ProblemDao, pcd dao.ProblemCaseDao) *JudgeWorker {
+	_, workers := getJudgeQueueConfig(config)
+	heartbeat := 10 * time.Second
+	if config.JudgeConfig != nil && config.JudgeConfig.JudgeHeartbeat > 0 {
+		heartbeat = time.Duration(config.JudgeConfig.JudgeHeartbeat) * time.Second
+	}
+	hostname, _ := os.Hostname()
+	w := &JudgeWorker{
+		judgeService: newJudgeService(config, ps, sd, scd, ad, pd, pcd),
+		client:       global.Redis,
+		consumer:     fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		workers:      workers,
+		heartbeat:    heartbeat,
+		inFlight:     make(map[string]struct{}),
+		stop:         make(chan struct{}),
+	}
+	w.judgeService.notifier = func(progress *dto.JudgeProgressDto) {
+		publishJudgeProgress(w.client, progress)
+	}
+	return w
+}
+
+// Run 开始判题，直到调用Stop并且正在判题的提交全部完成
+func (w *JudgeWorker) Run() error {
+	err := w.client.XGroupCreateMkStream(judgeStreamKey, judgeStreamGroup, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+	if err = w.beat(); err != nil {
+		return err
+	}
+	defer w.client.Del(judgeWorkerKeyPrefix + w.consumer)
+	go w.keepAlive()
+
+	var wg sync.WaitGroup
+	for i := 0; i < w.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !w.stopped() {
+				message, ok := w.claim()
+				if !ok {
+					message, ok = w.read()
+				}
+				if ok {
+					w.process(message)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// Stop 停止读取新的提交
+func (w *JudgeWorker) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+}
+
+func (w *JudgeWorker) stopped() bool {
+	select {
+	case <-w.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// beat 刷新心跳
+func (w *JudgeWorker) beat() error {
+	return w.client.Set(judgeWorkerKeyPrefix+w.consumer, time.Now().Unix(), 3*w.heartbeat).Err()
+}
+
+func (w *JudgeWorker) keepAlive() {
+	ticker := time.NewTicker(w.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			if err := w.beat(); err != nil {
+				log.Printf("heartbeat error: %v\n", err)
+			}
+		}
+	}
+}
+
+// read 读取一个新的提交
+func (w *JudgeWorker) read() (*redis.XMessage, bool) {
+	streams, err := w.client.XReadGroup(&redis.XReadGroupArgs{
+		Group:    judgeStreamGroup,
+		Consumer: w.consumer,
+		Streams:  []string{judgeStreamKey, ">"},
+		Count:    1,
+		Block:    judgeReadBlock,
+	}).Result()
+	if err != nil {
+		if err != redis.Nil {
+			log.Printf("XReadGroup error: %v\n", err)
+			// redis不可用时避免空转
+			time.Sleep(time.Second)
+		}
+		return nil, false
+	}
+	for _, stream := range streams {
+		if len(stream.Messages) != 0 {
+			return &stream.Messages[0], true
+		}
+	}
+	return nil, false
+}
+
+// claim 每个心跳间隔检查一次没有确认的消息，接管心跳已经过期的worker的一个消息。
+// 超出最大投递次数的消息直接判为系统错误
+func (w *JudgeWorker) claim() (*redis.XMessage, bool) {
+	w.mu.Lock()
+	if time.Since(w.lastClaim) < w.heartbeat {
+		w.mu.Unlock()
+		return nil, false
+	}
+	w.lastClaim = time.Now()
+	w.mu.Unlock()
+
+	claimIdle := 3 * w.heartbeat
+	pending, err := w.client.XPendingExt(&redis.XPendingExtArgs{
+		Stream: judgeStreamKey,
+		Group:  judgeStreamGroup,
+		Start:  "-",
+		End:    "+",
+		Count:  100,
+	}).Result()
+	if err != nil {
+		log.Printf("XPendingExt error: %v\n", err)
+		return nil, false
+	}
+	for _, p := range pending {
+		if p.Idle < claimIdle || w.isInFlight(p.Id) {
+			continue
+		}
+		// 心跳还在的worker可能正在判题，不接管
+		if p.Consumer != w.consumer {
+			alive, err := w.client.Exists(judgeWorkerKeyPrefix + p.Consumer).Result()
+			if err != nil {
+				log.Printf("Exists error: %v\n", err)
+				return nil, false
+			}
+			if alive != 0 {
+				continue
+			}
+		}
+		if p.RetryCount >= judgeMaxDeliveries {
+			w.abandon(p.Id)
+			continue
+		}
+		messages, err := w.client.XClaim(&redis.XClaimArgs{
+			Stream:   judgeStreamKey,
+			Group:    judgeStreamGroup,
+			Consumer: w.consumer,
+			MinIdle:  claimIdle,
+			Messages: []string{p.Id},
+		}).Result()
+		if err != nil {
+			log.Printf("XClaim error: %v\n", err)
+			continue
+		}
+		if len(messages) != 0 {
+			return &messages[0], true
+		}
+	}
+	return nil, false
+}
+
+func (w *JudgeWorker) isInFlight(id string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, ok := w.inFlight[id]
+	return ok
+}
+
+// process 判题，完成后确认消息
+func (w *JudgeWorker) process(message *redis.XMessage) {
+	w.mu.Lock()
+	w.inFlight[message.ID] = struct{}{}
+	w.mu.Unlock()
+	defer func() {
+		w.mu.Lock()
+		delete(w.inFlight, message.ID)
+		w.mu.Unlock()
+	}()
+	if w.handle(message) {
+		w.ack(message.ID)
+	}
+}
+
+// handle 读取提交和题目进行判题，返回消息是否可以确认。提交已经判题完成时说明是重复投递，直接确认
+func (w *JudgeWorker) handle(message *redis.XMessage) bool {
+	submissionID, err := strconv.ParseUint(fmt.Sprint(message.Values["submissionID"]), 10, 64)
+	if err != nil {
+		log.Printf("invalid judge message %s: %v\n", message.ID, err)
+		return true
+	}
+	j := w.judgeService
+	submission, err := j.submissionDao.GetSubmissionByID(global.Mysql, uint(submissionID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true
+	}
+	if err != nil {
+		log.Printf("GetSubmissionByID error: %v\n", err)
+		return false
+	}
+	if !isJudging(submission.Status) {
+		return true
+	}
+	problem, err := j.problemDao.GetProblemByID(global.Mysql, submission.ProblemID)
+	if err != nil {
+		log.Printf("GetProblemByID error: %v\n", err)
+		return false
+	}
+	runAllCases, _ := strconv.ParseBool(fmt.Sprint(message.Values["runAllCases"]))
+	rejudge, _ := strconv.ParseBool(fmt.Sprint(message.Values["rejudge"]))
+	j.progress.start(&dto.JudgeProgressDto{
+		SubmissionID: submission.ID,
+		Status:       submission.Status,
+	})
+	request := newSubmitRequest(submission)
+	request.RunAllCases = runAllCases
+	j.judge(&judgeTask{
+		submission: submission,
+		problem:    problem,
+		request:    request,
+		rejudge:    rejudge,
+	})
+	return true
+}
+
+// abandon 提交多次投递都没有完成判题，判为系统错误
+func (w *JudgeWorker) abandon(id string) {
+	messages, err := w.client.XRangeN(judgeStreamKey, id, id, 1).Result()
+	if err != nil {
+		log.Printf("XRange error: %v\n", err)
+		return
+	}
+	if len(messages) != 0 {
+		if ok := w.fail(&messages[0]); !ok {
+			return
+		}
+	}
+	w.ack(id)
+}
+
+func (w *JudgeWorker) fail(message *redis.XMessage) bool {
+	submissionID, err := strconv.ParseUint(fmt.Sprint(message.Values["submissionID"]), 10, 64)
+	if err != nil {
+		return true
+	}
+	j := w.judgeService
+	submission, err := j.submissionDao.GetSubmissionByID(global.Mysql, uint(submissionID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true
+	}
+	if err != nil {
+		log.Printf("GetSubmissionByID error: %v\n", err)
+		return false
+	}
+	if !isJudging(submission.Status) {
+		return true
+	}
+	submission.Status = constants.SystemError
+	submission.ErrorMessage = "判题多次异常中断"
+	if err = j.submissionDao.UpdateSubmission(global.Mysql, submission); err != nil {
+		log.Printf("UpdateSubmission error: %v\n", err)
+		return false
+	}
+	j.finishProgress(submission)
+	return true
+}
+
+func (w *JudgeWorker) ack(id string) {
+	if err := w.client.XAck(judgeStreamKey, judgeStreamGroup, id).Err(); err != nil {
+		log.Printf("XAck error: %v\n", err)
+	}
+}
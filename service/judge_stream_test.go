@@ -0,0 +1,107 @@
+package service
+
+import (
+	"FanCode/models/dto"
+	"FanCode/models/po"
+	"github.com/go-redis/redis"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestRedis 连接测试用的redis，地址通过FANCODE_TEST_REDIS设置，默认为本地redis，连接失败时跳过测试
+func newTestRedis(t *testing.T) *redis.Client {
+	addr := os.Getenv("FANCODE_TEST_REDIS")
+	if addr == "" {
+		addr = "127.0.0.1:6379"
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr, DB: 15})
+	if err := client.Ping().Err(); err != nil {
+		t.Skipf("redis is not available: %v", err)
+	}
+	client.Del(judgeStreamKey)
+	t.Cleanup(func() {
+		client.Del(judgeStreamKey)
+		client.Close()
+	})
+	return client
+}
+
+func newTestJudgeWorker(client *redis.Client, consumer string) *JudgeWorker {
+	return &JudgeWorker{
+		client:    client,
+		consumer:  consumer,
+		workers:   1,
+		heartbeat: 100 * time.Millisecond,
+		inFlight:  make(map[string]struct{}),
+		stop:      make(chan struct{}),
+	}
+}
+
+func TestRedisJudgeQueue(t *testing.T) {
+	client := newTestRedis(t)
+	assert.Nil(t, client.XGroupCreateMkStream(judgeStreamKey, judgeStreamGroup, "0").Err())
+	submission := &po.Submission{}
+	submission.ID = 7
+	q := newRedisJudgeQueue(client)
+	assert.True(t, q.push(&judgeTask{submission: submission, request: &dto.SubmitRequestDto{RunAllCases: true}}))
+
+	// 第一个worker读取后没有确认就退出
+	dead := newTestJudgeWorker(client, "dead")
+	message, ok := dead.read()
+	assert.True(t, ok)
+	assert.Equal(t, "7", message.Values["submissionID"])
+	assert.Equal(t, "true", message.Values["runAllCases"])
+
+	// 心跳过期后由其他worker接管
+	alive := newTestJudgeWorker(client, "alive")
+	assert.Nil(t, alive.beat())
+	_, ok = alive.claim()
+	assert.False(t, ok)
+	time.Sleep(3*alive.heartbeat + 50*time.Millisecond)
+	alive.lastClaim = time.Time{}
+	claimed, ok := alive.claim()
+	assert.True(t, ok)
+	assert.Equal(t, message.ID, claimed.ID)
+
+	// 确认后不会再次投递
+	alive.ack(claimed.ID)
+	time.Sleep(3*alive.heartbeat + 50*time.Millisecond)
+	alive.lastClaim = time.Time{}
+	_, ok = alive.claim()
+	assert.False(t, ok)
+	client.Del(judgeWorkerKeyPrefix + alive.consumer)
+}
+
+func TestSubscribeJudgeProgress(t *testing.T) {
+	client := newTestRedis(t)
+	progress := newJudgeProgress()
+	go subscribeJudgeProgress(client, progress)
+	// 等待订阅完成
+	time.Sleep(100 * time.Millisecond)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			if ch, cancel, ok := progress.subscribe(3); ok {
+				defer cancel()
+				var last *dto.JudgeProgressDto
+				for p := range ch {
+					last = p
+				}
+				assert.NotNil(t, last.Result)
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Error("progress is not received")
+	}()
+	publishJudgeProgress(client, &dto.JudgeProgressDto{SubmissionID: 3, Case: 1, CaseCount: 2})
+	time.Sleep(100 * time.Millisecond)
+	publishJudgeProgress(client, &dto.JudgeProgressDto{SubmissionID: 3, Result: &dto.SubmitResultDto{}})
+	wg.Wait()
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"FanCode/constants"
+	"FanCode/models/po"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 没有设置子任务的题目通过时的得分
+const FullScore = 100
+
+// parseSubtasks 解析题目的子任务列表并按照编号排序，检查编号不重复、分值不为负数以及只依赖编号更小的子任务
+func parseSubtasks(subtasksStr string) ([]*po.Subtask, error) {
+	if strings.TrimSpace(subtasksStr) == "" {
+		return nil, nil
+	}
+	var subtasks []*po.Subtask
+	if err := json.Unmarshal([]byte(subtasksStr), &subtasks); err != nil {
+		return nil, err
+	}
+	sort.Slice(subtasks, func(i, j int) bool {
+		return subtasks[i].ID < subtasks[j].ID
+	})
+	ids := make(map[int]bool, len(subtasks))
+	for _, subtask := range subtasks {
+		if subtask == nil || subtask.ID <= 0 || ids[subtask.ID] {
+			return nil, errors.New("子任务编号必须为正数并且不能重复")
+		}
+		if subtask.Score < 0 {
+			return nil, fmt.Errorf("子任务%d的分值不能为负数", subtask.ID)
+		}
+		// 只依赖编号更小的子任务，保证依赖关系中没有环
+		for _, dependency := range subtask.Dependencies {
+			if !ids[dependency] {
+				return nil, fmt.Errorf("子任务%d依赖的子任务%d不存在或者编号不小于当前子任务", subtask.ID, dependency)
+			}
+		}
+		ids[subtask.ID] = true
+	}
+	return subtasks, nil
+}
+
+// computeScore 根据用例的判题结果计算得分。没有子任务时通过得满分，
+// 否则子任务中有用例、所有用例都通过并且依赖的子任务都通过时获得该子任务的分值
+func computeScore(subtasks []*po.Subtask, cases []*po.SubmissionCase, status int) int {
+	if len(subtasks) == 0 {
+		if status == constants.Accepted {
+			return FullScore
+		}
+		return 0
+	}
+	// 统计每个子任务是否有用例以及是否有不通过的用例
+	hasCase := make(map[int]bool, len(subtasks))
+	failed := make(map[int]bool, len(subtasks))
+	for _, c := range cases {
+		hasCase[c.Subtask] = true
+		if c.Status != constants.Accepted {
+			failed[c.Subtask] = true
+		}
+	}
+	// 子任务按照编号排序，依赖的子任务已经计算过
+	passed := make(map[int]bool, len(subtasks))
+	score := 0
+	for _, subtask := range subtasks {
+		if !hasCase[subtask.ID] || failed[subtask.ID] {
+			continue
+		}
+		dependenciesPassed := true
+		for _, dependency := range subtask.Dependencies {
+			if !passed[dependency] {
+				dependenciesPassed = false
+				break
+			}
+		}
+		if dependenciesPassed {
+			passed[subtask.ID] = true
+			score += subtask.Score
+		}
+	}
+	return score
+}
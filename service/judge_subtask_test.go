@@ -0,0 +1,63 @@
+package service
+
+import (
+	"FanCode/constants"
+	"FanCode/models/po"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseSubtasks(t *testing.T) {
+	subtasks, err := parseSubtasks("")
+	assert.Nil(t, err)
+	assert.Nil(t, subtasks)
+
+	// 按照编号排序
+	subtasks, err = parseSubtasks(`[{"id":2,"score":60,"dependencies":[1]},{"id":1,"score":40}]`)
+	assert.Nil(t, err)
+	assert.Equal(t, []*po.Subtask{
+		{ID: 1, Score: 40},
+		{ID: 2, Score: 60, Dependencies: []int{1}},
+	}, subtasks)
+
+	invalid := []string{
+		`{"id":1}`,
+		`[{"id":0,"score":10}]`,
+		`[{"id":1,"score":10},{"id":1,"score":10}]`,
+		`[{"id":1,"score":-10}]`,
+		`[{"id":1,"score":10,"dependencies":[1]}]`,
+		`[{"id":1,"score":10,"dependencies":[2]},{"id":2,"score":10}]`,
+		`[{"id":1,"score":10,"dependencies":[3]}]`,
+	}
+	for _, s := range invalid {
+		_, err = parseSubtasks(s)
+		assert.NotNil(t, err, s)
+	}
+}
+
+func TestComputeScore(t *testing.T) {
+	// 没有子任务时通过得满分
+	assert.Equal(t, FullScore, computeScore(nil, nil, constants.Accepted))
+	assert.Equal(t, 0, computeScore(nil, []*po.SubmissionCase{{Status: constants.Accepted}}, constants.WrongAnswer))
+
+	subtasks, err := parseSubtasks(`[{"id":1,"score":20},{"id":2,"score":30,"dependencies":[1]},
+		{"id":3,"score":50},{"id":4,"score":10}]`)
+	assert.Nil(t, err)
+	newCases := func(statuses ...int) []*po.SubmissionCase {
+		cases := make([]*po.SubmissionCase, len(statuses))
+		for i, status := range statuses {
+			// 每个子任务两个用例，子任务4没有用例
+			cases[i] = &po.SubmissionCase{Subtask: i/2 + 1, Status: status}
+		}
+		return cases
+	}
+	ac, wa := constants.Accepted, constants.WrongAnswer
+	assert.Equal(t, 100, computeScore(subtasks, newCases(ac, ac, ac, ac, ac, ac), ac))
+	// 子任务1不通过时子任务2也不得分
+	assert.Equal(t, 50, computeScore(subtasks, newCases(ac, wa, ac, ac, ac, ac), wa))
+	assert.Equal(t, 50, computeScore(subtasks, newCases(ac, ac, ac, ac, wa, ac), wa))
+	assert.Equal(t, 20, computeScore(subtasks, newCases(ac, ac, constants.TimeLimitExceeded, ac, ac, wa), wa))
+	// 不属于任何子任务的用例不影响得分
+	cases := append(newCases(ac, ac, ac, ac, ac, ac), &po.SubmissionCase{Status: wa})
+	assert.Equal(t, 100, computeScore(subtasks, cases, wa))
+}
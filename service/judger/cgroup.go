@@ -1,76 +1,216 @@
 package judger
 
 import (
+	"bufio"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 )
 
+// cgroup版本
 const (
-	cgCPUPathPrefix    = "/sys/fs/cgroup/cpu/"
-	cgMemoryPathPrefix = "/sys/fs/cgroup/memory/"
+	CGroupV1 = 1
+	CGroupV2 = 2
 )
 
+const (
+	cgRootPath = "/sys/fs/cgroup/"
+	// cgroup v2 下存在该文件
+	cgV2ControllersFile = "cgroup.controllers"
+)
+
+var (
+	cgVersion     int
+	cgVersionOnce sync.Once
+)
+
+// CGroupVersion 检测当前系统挂载的cgroup版本，只检测一次。
+// 统一层级（unified hierarchy）挂载在/sys/fs/cgroup下时认为是v2，否则使用v1
+func CGroupVersion() int {
+	cgVersionOnce.Do(func() {
+		cgVersion = CGroupV1
+		if _, err := os.Stat(filepath.Join(cgRootPath, cgV2ControllersFile)); err == nil {
+			cgVersion = CGroupV2
+		}
+		log.Printf("cgroup version: v%d\n", cgVersion)
+	})
+	return cgVersion
+}
+
+// CGroupStats cgroup中统计的资源使用情况
+type CGroupStats struct {
+	MemoryPeak int64 // 内存使用峰值（以字节为单位）
+	CPUUsage   int64 // cpu总使用时间，用户态+内核态（以纳秒为单位）
+	UserCPU    int64 // 用户态cpu时间（以纳秒为单位）
+	SystemCPU  int64 // 内核态cpu时间（以纳秒为单位）
+	OOMKills   int64 // 因内存超出被kill的次数
+}
+
+// cgroupDriver 不同版本cgroup的具体实现
+type cgroupDriver interface {
+	create() error
+	addPID(pid int) error
+	setCPUQuota(quota int64) error
+	setMemoryLimit(limit int64) error
+	setPidsLimit(limit int64) error
+	stats() (*CGroupStats, error)
+	// resetPeak 重置内存使用峰值，不支持时返回错误，此时cgroup不能重复使用
+	resetPeak() error
+	procs() ([]int, error)
+	release() error
+}
+
 type CGroup struct {
 	containerID string
+	driver      cgroupDriver
+	// 重置时的累计统计，重复使用cgroup时减去上一次执行的cpu时间和oom次数
+	baseline *CGroupStats
 }
 
 func NewCGroup(containerID string) (*CGroup, error) {
-	preFixs := []string{cgCPUPathPrefix, cgMemoryPathPrefix}
-	for _, prefix := range preFixs {
-		cgroupDir := filepath.Join(prefix, containerID)
-		if err := os.MkdirAll(cgroupDir, os.ModePerm); err != nil {
-			return nil, err
-		}
+	var driver cgroupDriver
+	if CGroupVersion() == CGroupV2 {
+		driver = newCGroupV2(containerID)
+	} else {
+		driver = newCGroupV1(containerID)
+	}
+	if err := driver.create(); err != nil {
+		return nil, err
 	}
 	return &CGroup{
 		containerID: containerID,
+		driver:      driver,
 	}, nil
 }
 
 // 添加进程到cgroup组
 func (c *CGroup) AddPID(pid int) error {
-	preFixs := []string{cgCPUPathPrefix, cgMemoryPathPrefix}
-	for _, prefix := range preFixs {
-		cgroupDir := filepath.Join(prefix, c.containerID)
-		path := filepath.Join(cgroupDir, "tasks")
-		if err := os.WriteFile(path, []byte(fmt.Sprintf("%d", pid)), 0644); err != nil {
-			c.Release()
-			fmt.Println(err)
-			return err
-		}
+	if err := c.driver.addPID(pid); err != nil {
+		c.Release()
+		log.Println(err)
+		return err
 	}
 	return nil
 }
 
-// 设置CPU配额
+// 设置CPU配额，quota为每100ms周期内可以使用的cpu时间（微秒）
 func (c *CGroup) SetCPUQuota(quota int64) error {
-	cgroupDir := filepath.Join(cgCPUPathPrefix, c.containerID)
-	cpuQuotaFile := filepath.Join(cgroupDir, "cpu.cfs_quota_us")
-	if err := os.WriteFile(cpuQuotaFile, []byte(fmt.Sprintf("%d", quota)), 0644); err != nil {
-		return err
-	}
-	return nil
+	return c.driver.setCPUQuota(quota)
 }
 
-// 设置内存限制
+// 设置内存限制，同时禁止使用swap
 func (c *CGroup) SetMemoryLimit(limit int64) error {
-	cgroupDir := filepath.Join(cgMemoryPathPrefix, c.containerID)
-	memoryLimitFile := filepath.Join(cgroupDir, "memory.limit_in_bytes")
-	if err := os.WriteFile(memoryLimitFile, []byte(fmt.Sprintf("%d", limit)), 0644); err != nil {
+	return c.driver.setMemoryLimit(limit)
+}
+
+// SetPidsLimit 设置最大进程（线程）数，防止fork炸弹
+func (c *CGroup) SetPidsLimit(limit int64) error {
+	return c.driver.setPidsLimit(limit)
+}
+
+// Stats 读取cgroup中的资源使用统计，重置过的cgroup只包含重置后的使用情况
+func (c *CGroup) Stats() (*CGroupStats, error) {
+	stats, err := c.driver.stats()
+	if err != nil || c.baseline == nil {
+		return stats, err
+	}
+	stats.CPUUsage -= c.baseline.CPUUsage
+	stats.UserCPU -= c.baseline.UserCPU
+	stats.SystemCPU -= c.baseline.SystemCPU
+	stats.OOMKills -= c.baseline.OOMKills
+	return stats, nil
+}
+
+// Reset 重置资源使用统计，用于重复使用cgroup，cgroup中存在进程时返回错误
+func (c *CGroup) Reset() error {
+	procs, err := c.driver.procs()
+	if err != nil {
 		return err
 	}
+	if len(procs) != 0 {
+		return fmt.Errorf("cgroup %s still has %d processes", c.containerID, len(procs))
+	}
+	if err = c.driver.resetPeak(); err != nil {
+		return err
+	}
+	stats, err := c.driver.stats()
+	if err != nil {
+		return err
+	}
+	c.baseline = stats
 	return nil
 }
 
+// Procs cgroup中的进程
+func (c *CGroup) Procs() ([]int, error) {
+	return c.driver.procs()
+}
+
 func (c *CGroup) Release() error {
-	preFixs := []string{cgCPUPathPrefix, cgMemoryPathPrefix}
-	for _, prefix := range preFixs {
-		cgroupDir := filepath.Join(prefix, c.containerID)
-		err := os.Remove(cgroupDir)
+	return c.driver.release()
+}
+
+// writeCGroupFile 写入cgroup控制文件
+func writeCGroupFile(dir string, file string, value string) error {
+	return os.WriteFile(filepath.Join(dir, file), []byte(value), 0644)
+}
+
+// readCGroupInt 读取只有一个数字的cgroup文件，比如memory.peak
+func readCGroupInt(dir string, file string) (int64, error) {
+	data, err := os.ReadFile(filepath.Join(dir, file))
+	if err != nil {
+		return 0, err
+	}
+	str := strings.TrimSpace(string(data))
+	if str == "max" {
+		return -1, nil
+	}
+	return strconv.ParseInt(str, 10, 64)
+}
+
+// readCGroupProcs 读取cgroup.procs中的进程id
+func readCGroupProcs(dir string) ([]int, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "cgroup.procs"))
+	if err != nil {
+		return nil, err
+	}
+	var procs []int
+	for _, field := range strings.Fields(string(data)) {
+		pid, err := strconv.Atoi(field)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		procs = append(procs, pid)
 	}
-	return nil
+	return procs, nil
+}
+
+// readCGroupKeyedFile 读取 "key value" 格式的cgroup文件，比如cpu.stat、memory.events
+func readCGroupKeyedFile(dir string, file string) (map[string]int64, error) {
+	f, err := os.Open(filepath.Join(dir, file))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseCGroupKeyedFile(bufio.NewScanner(f))
+}
+
+func parseCGroupKeyedFile(scanner *bufio.Scanner) (map[string]int64, error) {
+	answer := make(map[string]int64, 8)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		value, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse cgroup value %s: %w", fields[1], err)
+		}
+		answer[fields[0]] = value
+	}
+	return answer, scanner.Err()
 }
@@ -0,0 +1,44 @@
+package judger
+
+import (
+	"FanCode/utils"
+	"bufio"
+	"gotest.tools/v3/assert"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestCGroup_Stats(t *testing.T) {
+	cgroup, err := NewCGroup(utils.GetUUID())
+	assert.NilError(t, err)
+	assert.NilError(t, cgroup.SetMemoryLimit(100*1024*1024))
+	assert.NilError(t, cgroup.SetCPUQuota(100000))
+	assert.NilError(t, cgroup.SetPidsLimit(16))
+
+	// 运行一个程序并加入cgroup
+	cmd := exec.Command("sh", "-c", "read a; echo $a")
+	cmd.Stdin = strings.NewReader("1\n")
+	assert.NilError(t, cmd.Start())
+	assert.NilError(t, cgroup.AddPID(cmd.Process.Pid))
+	assert.NilError(t, cmd.Wait())
+
+	stats, err := cgroup.Stats()
+	assert.NilError(t, err)
+	assert.Assert(t, stats.MemoryPeak >= 0)
+	assert.Assert(t, stats.CPUUsage >= 0)
+	assert.Equal(t, int64(0), stats.OOMKills)
+	assert.NilError(t, cgroup.Release())
+}
+
+func TestParseCGroupKeyedFile(t *testing.T) {
+	content := "usage_usec 1500\nuser_usec 1000\nsystem_usec 500\n"
+	m, err := parseCGroupKeyedFile(bufio.NewScanner(strings.NewReader(content)))
+	assert.NilError(t, err)
+	assert.Equal(t, int64(1500), m["usage_usec"])
+	assert.Equal(t, int64(1000), m["user_usec"])
+	assert.Equal(t, int64(500), m["system_usec"])
+
+	_, err = parseCGroupKeyedFile(bufio.NewScanner(strings.NewReader("oom_kill x\n")))
+	assert.Assert(t, err != nil)
+}
@@ -0,0 +1,178 @@
+package judger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	cgCPUPathPrefix     = "/sys/fs/cgroup/cpu/"
+	cgCPUAcctPathPrefix = "/sys/fs/cgroup/cpuacct/"
+	cgMemoryPathPrefix  = "/sys/fs/cgroup/memory/"
+	cgPidsPathPrefix    = "/sys/fs/cgroup/pids/"
+
+	// cpuacct.stat中的单位为USER_HZ，linux上一般为100
+	cgV1UserHZ = 100
+)
+
+// cgroupV1 每个子系统分别挂载在不同的目录下
+type cgroupV1 struct {
+	cpuDir     string
+	cpuAcctDir string
+	memoryDir  string
+	pidsDir    string
+}
+
+func newCGroupV1(containerID string) *cgroupV1 {
+	c := &cgroupV1{
+		cpuDir:    filepath.Join(cgCPUPathPrefix, containerID),
+		memoryDir: filepath.Join(cgMemoryPathPrefix, containerID),
+	}
+	// cpuacct和pids子系统不一定挂载，不存在则不使用
+	if _, err := os.Stat(cgCPUAcctPathPrefix); err == nil {
+		c.cpuAcctDir = filepath.Join(cgCPUAcctPathPrefix, containerID)
+	}
+	if _, err := os.Stat(cgPidsPathPrefix); err == nil {
+		c.pidsDir = filepath.Join(cgPidsPathPrefix, containerID)
+	}
+	return c
+}
+
+// dirs 所有使用的子系统目录，cpu和cpuacct可能挂载在同一个目录下
+func (c *cgroupV1) dirs() []string {
+	dirs := []string{c.cpuDir, c.memoryDir}
+	for _, dir := range []string{c.cpuAcctDir, c.pidsDir} {
+		if dir == "" {
+			continue
+		}
+		exist := false
+		for _, d := range dirs {
+			if sameDir(d, dir) {
+				exist = true
+			}
+		}
+		if !exist {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+func (c *cgroupV1) create() error {
+	for _, dir := range c.dirs() {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *cgroupV1) addPID(pid int) error {
+	for _, dir := range c.dirs() {
+		if err := writeCGroupFile(dir, "tasks", fmt.Sprintf("%d", pid)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setCPUQuota quota小于等于0时不限制
+func (c *cgroupV1) setCPUQuota(quota int64) error {
+	if quota <= 0 {
+		quota = -1
+	}
+	return writeCGroupFile(c.cpuDir, "cpu.cfs_quota_us", fmt.Sprintf("%d", quota))
+}
+
+// setMemoryLimit limit小于等于0时不限制
+func (c *cgroupV1) setMemoryLimit(limit int64) error {
+	if limit <= 0 {
+		limit = -1
+	}
+	// 开启了swap统计才存在该文件，内存+swap的限制不能小于内存限制，
+	// 重复使用cgroup时可能需要调大限制，所以先取消内存+swap的限制
+	err := writeCGroupFile(c.memoryDir, "memory.memsw.limit_in_bytes", "-1")
+	swapAccount := !errors.Is(err, os.ErrNotExist)
+	if err != nil && swapAccount {
+		return err
+	}
+	if err = writeCGroupFile(c.memoryDir, "memory.limit_in_bytes", fmt.Sprintf("%d", limit)); err != nil {
+		return err
+	}
+	// 内存+swap的限制和内存限制一致，即禁止使用swap
+	if swapAccount {
+		return writeCGroupFile(c.memoryDir, "memory.memsw.limit_in_bytes", fmt.Sprintf("%d", limit))
+	}
+	return nil
+}
+
+// setPidsLimit limit小于等于0时不限制
+func (c *cgroupV1) setPidsLimit(limit int64) error {
+	if c.pidsDir == "" {
+		if limit <= 0 {
+			return nil
+		}
+		return errors.New("cgroup pids subsystem is not mounted")
+	}
+	value := "max"
+	if limit > 0 {
+		value = fmt.Sprintf("%d", limit)
+	}
+	return writeCGroupFile(c.pidsDir, "pids.max", value)
+}
+
+func (c *cgroupV1) stats() (*CGroupStats, error) {
+	stats := &CGroupStats{}
+	var err error
+	if stats.MemoryPeak, err = readCGroupInt(c.memoryDir, "memory.max_usage_in_bytes"); err != nil {
+		return nil, err
+	}
+	// 低版本内核的memory.oom_control中没有oom_kill
+	if oomControl, err := readCGroupKeyedFile(c.memoryDir, "memory.oom_control"); err == nil {
+		stats.OOMKills = oomControl["oom_kill"]
+	}
+	if c.cpuAcctDir == "" {
+		return stats, nil
+	}
+	if stats.CPUUsage, err = readCGroupInt(c.cpuAcctDir, "cpuacct.usage"); err != nil {
+		return nil, err
+	}
+	cpuStat, err := readCGroupKeyedFile(c.cpuAcctDir, "cpuacct.stat")
+	if err != nil {
+		return nil, err
+	}
+	stats.UserCPU = cpuStat["user"] * int64(time.Second) / cgV1UserHZ
+	stats.SystemCPU = cpuStat["system"] * int64(time.Second) / cgV1UserHZ
+	return stats, nil
+}
+
+// resetPeak 写入0重置内存使用峰值，cpu时间通过CGroup中的baseline扣除
+func (c *cgroupV1) resetPeak() error {
+	return writeCGroupFile(c.memoryDir, "memory.max_usage_in_bytes", "0")
+}
+
+func (c *cgroupV1) procs() ([]int, error) {
+	return readCGroupProcs(c.memoryDir)
+}
+
+func (c *cgroupV1) release() error {
+	for _, dir := range c.dirs() {
+		if err := os.Remove(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sameDir 判断两个路径是否指向同一个目录，比如cpu,cpuacct联合挂载后的软链接
+func sameDir(a string, b string) bool {
+	aa, err1 := filepath.EvalSymlinks(filepath.Dir(a))
+	bb, err2 := filepath.EvalSymlinks(filepath.Dir(b))
+	if err1 != nil || err2 != nil {
+		return a == b
+	}
+	return aa == bb
+}
@@ -0,0 +1,209 @@
+package judger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// 所有判题用的cgroup都创建在该目录下
+	cgV2ParentName = "fancode"
+	// 根cgroup存在进程时无法开启子树控制器，需要把进程移动到该叶子节点下
+	cgV2InitLeafName = "fancode.init"
+	// cpu.max的默认周期（微秒）
+	cgV2CPUPeriod = 100000
+)
+
+var (
+	cgV2Controllers   = []string{"cpu", "memory", "pids"}
+	cgV2ParentOnce    sync.Once
+	cgV2ParentInitErr error
+)
+
+// cgroupV2 统一层级，所有控制器都在同一个目录下
+type cgroupV2 struct {
+	dir string
+	// memory.peak的重置只对写入的文件描述符有效，重置后需要通过该描述符读取峰值
+	peakFile *os.File
+}
+
+func newCGroupV2(containerID string) *cgroupV2 {
+	return &cgroupV2{
+		dir: filepath.Join(cgRootPath, cgV2ParentName, containerID),
+	}
+}
+
+// initCGroupV2Parent 创建父cgroup并开启需要的控制器，只执行一次
+func initCGroupV2Parent() error {
+	cgV2ParentOnce.Do(func() {
+		parent := filepath.Join(cgRootPath, cgV2ParentName)
+		if cgV2ParentInitErr = os.MkdirAll(parent, os.ModePerm); cgV2ParentInitErr != nil {
+			return
+		}
+		if cgV2ParentInitErr = enableCGroupV2Controllers(cgRootPath); cgV2ParentInitErr != nil {
+			return
+		}
+		cgV2ParentInitErr = enableCGroupV2Controllers(parent)
+	})
+	return cgV2ParentInitErr
+}
+
+// enableCGroupV2Controllers 在dir的cgroup.subtree_control中开启控制器
+func enableCGroupV2Controllers(dir string) error {
+	for _, controller := range cgV2Controllers {
+		err := writeCGroupFile(dir, "cgroup.subtree_control", "+"+controller)
+		// 容器中的根cgroup可能存在进程（no internal process规则），先将进程移动到叶子节点
+		if errors.Is(err, syscall.EBUSY) && dir == cgRootPath {
+			if err = moveCGroupV2Procs(dir, filepath.Join(dir, cgV2InitLeafName)); err != nil {
+				return err
+			}
+			err = writeCGroupFile(dir, "cgroup.subtree_control", "+"+controller)
+		}
+		if err != nil {
+			return fmt.Errorf("enable cgroup controller %s: %w", controller, err)
+		}
+	}
+	return nil
+}
+
+// moveCGroupV2Procs 把from中的所有进程移动到to
+func moveCGroupV2Procs(from string, to string) error {
+	if err := os.MkdirAll(to, os.ModePerm); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(filepath.Join(from, "cgroup.procs"))
+	if err != nil {
+		return err
+	}
+	log.Printf("move processes of %s into %s\n", from, to)
+	for _, pid := range strings.Fields(string(data)) {
+		// 内核线程等无法移动的进程直接忽略
+		_ = writeCGroupFile(to, "cgroup.procs", pid)
+	}
+	return nil
+}
+
+func (c *cgroupV2) create() error {
+	if err := initCGroupV2Parent(); err != nil {
+		return err
+	}
+	return os.MkdirAll(c.dir, os.ModePerm)
+}
+
+func (c *cgroupV2) addPID(pid int) error {
+	return writeCGroupFile(c.dir, "cgroup.procs", fmt.Sprintf("%d", pid))
+}
+
+// setCPUQuota 和v1保持一致，quota为每个周期（100ms）内可使用的cpu时间，-1表示不限制
+func (c *cgroupV2) setCPUQuota(quota int64) error {
+	value := "max"
+	if quota > 0 {
+		value = fmt.Sprintf("%d", quota)
+	}
+	return writeCGroupFile(c.dir, "cpu.max", fmt.Sprintf("%s %d", value, cgV2CPUPeriod))
+}
+
+// setMemoryLimit limit小于等于0时不限制
+func (c *cgroupV2) setMemoryLimit(limit int64) error {
+	value := "max"
+	if limit > 0 {
+		value = fmt.Sprintf("%d", limit)
+	}
+	if err := writeCGroupFile(c.dir, "memory.max", value); err != nil {
+		return err
+	}
+	// 没有开启swap的系统上不存在该文件
+	err := writeCGroupFile(c.dir, "memory.swap.max", "0")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// setPidsLimit limit小于等于0时不限制
+func (c *cgroupV2) setPidsLimit(limit int64) error {
+	value := "max"
+	if limit > 0 {
+		value = fmt.Sprintf("%d", limit)
+	}
+	return writeCGroupFile(c.dir, "pids.max", value)
+}
+
+func (c *cgroupV2) stats() (*CGroupStats, error) {
+	stats := &CGroupStats{}
+	var err error
+	// memory.peak需要5.19以上的内核，低版本退化为读取当前使用量
+	if c.peakFile != nil {
+		if stats.MemoryPeak, err = readCGroupPeakFile(c.peakFile); err != nil {
+			return nil, err
+		}
+	} else if stats.MemoryPeak, err = readCGroupInt(c.dir, "memory.peak"); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if stats.MemoryPeak, err = readCGroupInt(c.dir, "memory.current"); err != nil {
+			return nil, err
+		}
+	}
+	events, err := readCGroupKeyedFile(c.dir, "memory.events")
+	if err != nil {
+		return nil, err
+	}
+	stats.OOMKills = events["oom_kill"]
+	cpuStat, err := readCGroupKeyedFile(c.dir, "cpu.stat")
+	if err != nil {
+		return nil, err
+	}
+	stats.CPUUsage = cpuStat["usage_usec"] * int64(time.Microsecond)
+	stats.UserCPU = cpuStat["user_usec"] * int64(time.Microsecond)
+	stats.SystemCPU = cpuStat["system_usec"] * int64(time.Microsecond)
+	return stats, nil
+}
+
+// resetPeak 通过memory.peak的文件描述符重置内存峰值，需要6.12以上的内核
+func (c *cgroupV2) resetPeak() error {
+	if c.peakFile == nil {
+		f, err := os.OpenFile(filepath.Join(c.dir, "memory.peak"), os.O_RDWR, 0)
+		if err != nil {
+			return err
+		}
+		c.peakFile = f
+	}
+	if _, err := c.peakFile.WriteAt([]byte("reset\n"), 0); err != nil {
+		_ = c.peakFile.Close()
+		c.peakFile = nil
+		return fmt.Errorf("reset memory.peak: %w", err)
+	}
+	return nil
+}
+
+func (c *cgroupV2) procs() ([]int, error) {
+	return readCGroupProcs(c.dir)
+}
+
+func (c *cgroupV2) release() error {
+	if c.peakFile != nil {
+		_ = c.peakFile.Close()
+		c.peakFile = nil
+	}
+	return os.Remove(c.dir)
+}
+
+// readCGroupPeakFile 从已经打开的memory.peak读取峰值
+func readCGroupPeakFile(f *os.File) (int64, error) {
+	buf := make([]byte, 32)
+	n, err := f.ReadAt(buf, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(buf[:n])), 10, 64)
+}
@@ -0,0 +1,124 @@
+package judger
+
+import (
+	"FanCode/constants"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 特判程序的退出码，和testlib保持一致
+const (
+	checkerExitAccepted          = 0
+	checkerExitWrongAnswer       = 1
+	checkerExitPresentationError = 2
+)
+
+// 传递给特判程序的文件名称
+const (
+	checkerInputFile    = "input.txt"
+	checkerOutputFile   = "output.txt"
+	checkerExpectedFile = "answer.txt"
+)
+
+// Check 运行特判程序检查用户的输出，和testlib一致，特判程序的参数依次为输入文件、用户输出文件和期望输出文件，
+// 通过退出码返回检查结果，通过标准错误输出返回信息。options中的Language为特判程序的语言
+func (j *JudgeCore) Check(checkerFile string, input io.Reader, userOutput []byte, expectedOutput io.Reader,
+	options *ExecuteOptions) (*CheckResult, error) {
+	checkerFile, err := filepath.Abs(checkerFile)
+	if err != nil {
+		return nil, err
+	}
+	dataDir, err := newCheckerDataDir(checkerFile, map[string]io.Reader{
+		checkerInputFile:    input,
+		checkerOutputFile:   bytes.NewReader(userOutput),
+		checkerExpectedFile: expectedOutput,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dataDir)
+
+	checkOptions := *options
+	checkOptions.Args = append(append([]string{}, options.Args...), filepath.Join(dataDir, checkerInputFile),
+		filepath.Join(dataDir, checkerOutputFile), filepath.Join(dataDir, checkerExpectedFile))
+	execDir, cmdName, cmdArg, seccomp, err := j.command(checkerFile, &checkOptions)
+	if err != nil {
+		return nil, err
+	}
+	result := j.run(execDir, cmdName, cmdArg, seccomp, nil, &checkOptions)
+	if result.Verdict == constants.SystemError {
+		return nil, errors.New(strings.TrimSpace(result.ErrorMessage))
+	}
+
+	checkResult := &CheckResult{}
+	checkResult.Verdict, checkResult.Message = checkerVerdict(result)
+	if checkResult.Verdict == constants.SystemError && !isCheckerExit(result) {
+		checkResult.Message = "特判程序运行出错：" + checkResult.Message
+	}
+	return checkResult, nil
+}
+
+// newCheckerDataDir 在特判程序所在目录下创建临时目录保存传递给特判程序的文件，沙箱中只有该目录可见。
+// 文件内容为nil时创建空文件
+func newCheckerDataDir(checkerFile string, files map[string]io.Reader) (string, error) {
+	dataDir, err := os.MkdirTemp(filepath.Dir(checkerFile), "check")
+	if err != nil {
+		return "", err
+	}
+	if err = os.Chmod(dataDir, 0755); err != nil {
+		_ = os.RemoveAll(dataDir)
+		return "", err
+	}
+	for name, data := range files {
+		if err = writeCheckerFile(filepath.Join(dataDir, name), data); err != nil {
+			_ = os.RemoveAll(dataDir)
+			return "", err
+		}
+	}
+	return dataDir, nil
+}
+
+func writeCheckerFile(name string, data io.Reader) error {
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if data != nil {
+		if _, err = io.Copy(file, data); err != nil {
+			_ = file.Close()
+			return err
+		}
+	}
+	return file.Close()
+}
+
+// isCheckerExit 特判程序是否正常退出，超时、内存超限或者被信号终止都属于特判程序出错
+func isCheckerExit(result ExecuteResult) bool {
+	return result.Verdict == constants.RunSuccess || (result.Verdict == constants.RuntimeError && result.Signal == "")
+}
+
+// checkerVerdict 根据特判程序或者交互程序的执行结果获取检查结果和输出的信息，
+// 没有正常退出时返回SystemError和错误信息
+func checkerVerdict(result ExecuteResult) (int, string) {
+	if !isCheckerExit(result) {
+		return constants.SystemError, result.ErrorMessage
+	}
+	message := strings.TrimSpace(result.ErrorOutput)
+	if message == "" {
+		message = strings.TrimSpace(string(result.Output))
+	}
+	switch result.ExitCode {
+	case checkerExitAccepted:
+		return constants.Accepted, message
+	case checkerExitWrongAnswer:
+		return constants.WrongAnswer, message
+	case checkerExitPresentationError:
+		return constants.PresentationError, message
+	default:
+		return constants.SystemError, message
+	}
+}
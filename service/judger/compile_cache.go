@@ -0,0 +1,236 @@
+package judger
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 写入缓存时使用的临时文件前缀，启动时清理残留的临时文件
+const compileCacheTempPrefix = "tmp-"
+
+// CompileCache 编译结果缓存，以语言、编译器版本、编译命令以及源文件的sha256作为key，
+// 缓存文件保存在磁盘上，总大小超出限制时淘汰最久没有使用的文件
+type CompileCache struct {
+	mu      sync.Mutex
+	dir     string
+	maxSize int64
+	size    int64
+	lru     *list.List // 最近使用的在前面
+	entries map[string]*list.Element
+}
+
+type compileCacheEntry struct {
+	key  string
+	size int64
+}
+
+// NewCompileCache 创建编译缓存，加载目录中已有的缓存文件
+func NewCompileCache(dir string, maxSize int64) (*CompileCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	c := &CompileCache{
+		dir:     dir,
+		maxSize: maxSize,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+	}
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	// 按照修改时间排序，命中缓存时会更新修改时间
+	var infos []os.FileInfo
+	for _, dirEntry := range dirEntries {
+		info, err := dirEntry.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if strings.HasPrefix(info.Name(), compileCacheTempPrefix) {
+			_ = os.Remove(filepath.Join(dir, info.Name()))
+			continue
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, k int) bool {
+		return infos[i].ModTime().After(infos[k].ModTime())
+	})
+	for _, info := range infos {
+		c.entries[info.Name()] = c.lru.PushBack(&compileCacheEntry{key: info.Name(), size: info.Size()})
+		c.size += info.Size()
+	}
+	c.mu.Lock()
+	c.evict()
+	c.mu.Unlock()
+	return c, nil
+}
+
+// key 计算编译缓存的key，编译器的路径、大小和修改时间作为编译器版本，编译器升级后缓存自动失效。
+// includeFiles为不传给编译器的文件，比如头文件，也会影响编译结果
+func (c *CompileCache) key(spec *LanguageSpec, compileFiles []string, includeFiles []string) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "language:%s\ncompile:%s\nstd:%s\n", spec.Name, spec.CompileCmd, spec.Standard)
+	for _, command := range strings.Split(spec.CompileCmd, commandSeparator) {
+		fields := strings.Fields(command)
+		if len(fields) == 0 {
+			continue
+		}
+		compiler, err := exec.LookPath(fields[0])
+		if err != nil {
+			return "", err
+		}
+		if compiler, err = filepath.EvalSymlinks(compiler); err != nil {
+			return "", err
+		}
+		info, err := os.Stat(compiler)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "compiler:%s %d %d\n", compiler, info.Size(), info.ModTime().UnixNano())
+	}
+	// 文件名会影响编译结果，比如java的主类名，多个文件时使用相对于main文件所在目录的路径
+	var baseDir string
+	if len(compileFiles) != 0 {
+		baseDir = filepath.Dir(compileFiles[0])
+	}
+	if err := hashFiles(h, "source", baseDir, compileFiles); err != nil {
+		return "", err
+	}
+	if err := hashFiles(h, "include", baseDir, includeFiles); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashFiles 将文件的路径和内容的hash写入h
+func hashFiles(h io.Writer, kind string, baseDir string, files []string) error {
+	for _, file := range files {
+		name, err := filepath.Rel(baseDir, file)
+		if err != nil {
+			name = filepath.Base(file)
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		fileHash := sha256.New()
+		_, err = io.Copy(fileHash, f)
+		_ = f.Close()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s:%s %x\n", kind, filepath.ToSlash(name), fileHash.Sum(nil))
+	}
+	return nil
+}
+
+// load 将缓存的编译结果复制到outFilePath，不存在时返回false
+func (c *CompileCache) load(key string, outFilePath string) (bool, error) {
+	c.mu.Lock()
+	element, ok := c.entries[key]
+	if !ok {
+		c.mu.Unlock()
+		return false, nil
+	}
+	c.lru.MoveToFront(element)
+	// 在锁内打开文件，避免复制前被淘汰删除
+	cacheFile := filepath.Join(c.dir, key)
+	src, err := os.Open(cacheFile)
+	c.mu.Unlock()
+	if err != nil {
+		c.remove(key)
+		return false, nil
+	}
+	defer src.Close()
+	now := time.Now()
+	_ = os.Chtimes(cacheFile, now, now)
+
+	dst, err := os.OpenFile(outFilePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return false, err
+	}
+	if _, err = io.Copy(dst, src); err != nil {
+		_ = dst.Close()
+		_ = os.Remove(outFilePath)
+		return false, err
+	}
+	return true, dst.Close()
+}
+
+// store 保存编译结果，先写入临时文件再重命名，保证缓存文件是完整的
+func (c *CompileCache) store(key string, outFilePath string) error {
+	src, err := os.Open(outFilePath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	tmp, err := os.CreateTemp(c.dir, compileCacheTempPrefix)
+	if err != nil {
+		return err
+	}
+	size, err := io.Copy(tmp, src)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	// 单个文件超出缓存大小时不缓存
+	if size > c.maxSize {
+		_ = os.Remove(tmp.Name())
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err = os.Rename(tmp.Name(), filepath.Join(c.dir, key)); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*compileCacheEntry)
+		c.size += size - entry.size
+		entry.size = size
+		c.lru.MoveToFront(element)
+	} else {
+		c.entries[key] = c.lru.PushFront(&compileCacheEntry{key: key, size: size})
+		c.size += size
+	}
+	c.evict()
+	return nil
+}
+
+// remove 删除一个缓存
+func (c *CompileCache) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		c.removeElement(element)
+	}
+}
+
+// evict 淘汰最久没有使用的缓存，直到总大小不超过限制，调用时需要持有锁
+func (c *CompileCache) evict() {
+	for c.size > c.maxSize && c.lru.Len() != 0 {
+		c.removeElement(c.lru.Back())
+	}
+}
+
+func (c *CompileCache) removeElement(element *list.Element) {
+	entry := element.Value.(*compileCacheEntry)
+	c.lru.Remove(element)
+	delete(c.entries, entry.key)
+	c.size -= entry.size
+	_ = os.Remove(filepath.Join(c.dir, entry.key))
+}
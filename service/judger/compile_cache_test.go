@@ -0,0 +1,114 @@
+package judger
+
+import (
+	"FanCode/constants"
+	"gotest.tools/v3/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJudgeCore_CompileCache(t *testing.T) {
+	dir := t.TempDir()
+	compileCache, err := NewCompileCache(filepath.Join(dir, "cache"), 64*1024*1024)
+	assert.NilError(t, err)
+	judgeCore := NewPooledJudgeCore(compileCache, nil, 0)
+	options := &CompileOptions{
+		Language:  constants.LanguageC,
+		LimitTime: int64(10 * time.Second),
+	}
+
+	// 第一次编译没有命中缓存
+	result, err := judgeCore.Compile([]string{"./test_file/test_execute.c"}, filepath.Join(dir, "first"), options)
+	assert.NilError(t, err)
+	assert.Equal(t, true, result.Compiled)
+	assert.Equal(t, false, result.Cached)
+
+	// 相同的代码直接使用缓存的编译结果
+	result, err = judgeCore.Compile([]string{"./test_file/test_execute.c"}, filepath.Join(dir, "second"), options)
+	assert.NilError(t, err)
+	assert.Equal(t, true, result.Compiled)
+	assert.Equal(t, true, result.Cached)
+	assert.Equal(t, filepath.Join(dir, "second"), result.CompiledFilePath)
+	first, err := os.ReadFile(filepath.Join(dir, "first"))
+	assert.NilError(t, err)
+	second, err := os.ReadFile(filepath.Join(dir, "second"))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, first, second)
+	info, err := os.Stat(filepath.Join(dir, "second"))
+	assert.NilError(t, err)
+	assert.Assert(t, info.Mode()&0100 != 0)
+
+	// 代码不同时重新编译
+	result, err = judgeCore.Compile([]string{"./test_file/test_timeout.c"}, filepath.Join(dir, "third"), options)
+	assert.NilError(t, err)
+	assert.Equal(t, false, result.Cached)
+
+	// 重新加载缓存目录
+	compileCache, err = NewCompileCache(filepath.Join(dir, "cache"), 64*1024*1024)
+	assert.NilError(t, err)
+	assert.Equal(t, 2, compileCache.lru.Len())
+}
+
+func TestCompileCache_Evict(t *testing.T) {
+	dir := t.TempDir()
+	compileCache, err := NewCompileCache(filepath.Join(dir, "cache"), 10)
+	assert.NilError(t, err)
+	out := filepath.Join(dir, "out")
+	for _, key := range []string{"a", "b", "c"} {
+		assert.NilError(t, os.WriteFile(out, []byte(key+"1234"), 0755))
+		assert.NilError(t, compileCache.store(key, out))
+	}
+	// 超出大小限制时淘汰最久没有使用的a
+	ok, err := compileCache.load("a", out)
+	assert.NilError(t, err)
+	assert.Equal(t, false, ok)
+	assert.Equal(t, int64(10), compileCache.size)
+
+	// 使用b之后再写入d，淘汰c
+	ok, err = compileCache.load("b", out)
+	assert.NilError(t, err)
+	assert.Equal(t, true, ok)
+	assert.NilError(t, os.WriteFile(out, []byte("d1234"), 0755))
+	assert.NilError(t, compileCache.store("d", out))
+	_, err = os.Stat(filepath.Join(dir, "cache", "c"))
+	assert.Assert(t, os.IsNotExist(err))
+	ok, err = compileCache.load("b", out)
+	assert.NilError(t, err)
+	assert.Equal(t, true, ok)
+	content, err := os.ReadFile(out)
+	assert.NilError(t, err)
+	assert.Equal(t, "b1234", string(content))
+}
+
+func TestJudgeCore_CompileCacheIncludeFiles(t *testing.T) {
+	dir := t.TempDir()
+	compileCache, err := NewCompileCache(filepath.Join(dir, "cache"), 64*1024*1024)
+	assert.NilError(t, err)
+	judgeCore := NewPooledJudgeCore(compileCache, nil, 0)
+	src := filepath.Join(dir, "src")
+	assert.NilError(t, os.MkdirAll(filepath.Join(src, "lib"), 0755))
+	mainFile := filepath.Join(src, "main.c")
+	header := filepath.Join(src, "lib", "value.h")
+	assert.NilError(t, os.WriteFile(mainFile, []byte("#include <stdio.h>\n#include \"lib/value.h\"\n"+
+		"int main() { printf(\"%d\\n\", VALUE); return 0; }\n"), 0644))
+	assert.NilError(t, os.WriteFile(header, []byte("#define VALUE 1\n"), 0644))
+	options := &CompileOptions{
+		Language:     constants.LanguageC,
+		LimitTime:    int64(10 * time.Second),
+		IncludeFiles: []string{header},
+	}
+
+	result, err := judgeCore.Compile([]string{mainFile}, filepath.Join(dir, "first"), options)
+	assert.NilError(t, err)
+	assert.Equal(t, true, result.Compiled)
+	assert.Equal(t, false, result.Cached)
+
+	// 只修改头文件时也需要重新编译
+	assert.NilError(t, os.WriteFile(header, []byte("#define VALUE 2\n"), 0644))
+	result, err = judgeCore.Compile([]string{mainFile}, filepath.Join(dir, "second"), options)
+	assert.NilError(t, err)
+	assert.Equal(t, true, result.Compiled)
+	assert.Equal(t, false, result.Cached)
+}
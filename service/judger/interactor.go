@@ -0,0 +1,121 @@
+package judger
+
+import (
+	"FanCode/constants"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Interact 交互式判题，用户程序的输出通过管道连接到交互程序的输入，交互程序的输出连接到用户程序的输入，
+// 两个程序分别在各自的cgroup和沙箱中运行。和testlib一致，交互程序的参数依次为输入文件、输出文件和期望输出文件，
+// 通过退出码返回检查结果，通过标准错误输出返回信息。options为用户程序的执行选项，interactorOptions为交互程序的执行选项
+func (j *JudgeCore) Interact(execFile string, interactorFile string, input io.Reader, expectedOutput io.Reader,
+	options *ExecuteOptions, interactorOptions *ExecuteOptions) (*InteractResult, error) {
+	interactorFile, err := filepath.Abs(interactorFile)
+	if err != nil {
+		return nil, err
+	}
+	// 输入文件和期望输出文件只对交互程序可见，交互程序的输出文件不需要保存
+	dataDir, err := newCheckerDataDir(interactorFile, map[string]io.Reader{
+		checkerInputFile:    input,
+		checkerExpectedFile: expectedOutput,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dataDir)
+	interactOptions := *interactorOptions
+	interactOptions.Args = append(append([]string{}, interactorOptions.Args...), filepath.Join(dataDir, checkerInputFile),
+		os.DevNull, filepath.Join(dataDir, checkerExpectedFile))
+
+	execDir, cmdName, cmdArg, seccomp, err := j.command(execFile, options)
+	if err != nil {
+		return nil, err
+	}
+	interactorDir, interactorName, interactorArg, interactorSeccomp, err := j.command(interactorFile, &interactOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	// 创建两个管道，父进程在子进程启动后关闭自己持有的一端，保证一方退出后另一方能读到EOF
+	userReader, interactorWriter, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	interactorReader, userWriter, err := os.Pipe()
+	if err != nil {
+		_ = userReader.Close()
+		_ = interactorWriter.Close()
+		return nil, err
+	}
+	pipes := []*os.File{userReader, interactorWriter, interactorReader, userWriter}
+	closePipes := func() {
+		for _, pipe := range pipes {
+			_ = pipe.Close()
+		}
+	}
+
+	interactor, err := j.start(interactorDir, interactorName, interactorArg, interactorSeccomp, interactorReader,
+		interactorWriter, &interactOptions)
+	if err != nil {
+		closePipes()
+		return nil, err
+	}
+	user, err := j.start(execDir, cmdName, cmdArg, seccomp, userReader, userWriter, options)
+	closePipes()
+	if err != nil {
+		// 用户程序没有启动时交互程序会读到EOF并退出
+		interactor.wait()
+		return nil, err
+	}
+
+	var wg sync.WaitGroup
+	var interactorResult ExecuteResult
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		interactorResult = interactor.wait()
+	}()
+	userResult := user.wait()
+	wg.Wait()
+	if interactorResult.Verdict == constants.SystemError {
+		return nil, errors.New(strings.TrimSpace(interactorResult.ErrorMessage))
+	}
+	if userResult.Verdict == constants.SystemError {
+		return nil, errors.New(strings.TrimSpace(userResult.ErrorMessage))
+	}
+	return j.interactResult(userResult, interactorResult), nil
+}
+
+// interactResult 根据两个程序的执行结果判断判题结果。用户程序超出资源限制优先，
+// 交互程序判定答案错误时，用户程序可能因为管道关闭而异常退出，所以交互程序的结果优先于用户程序的运行错误
+func (j *JudgeCore) interactResult(userResult ExecuteResult, interactorResult ExecuteResult) *InteractResult {
+	result := &InteractResult{
+		Execute:    userResult,
+		Interactor: interactorResult,
+	}
+	verdict, message := checkerVerdict(interactorResult)
+	switch {
+	case userResult.Verdict == constants.TimeLimitExceeded || userResult.Verdict == constants.MemoryLimitExceeded ||
+		userResult.Verdict == constants.RestrictedFunction:
+		result.Verdict = userResult.Verdict
+		result.Message = userResult.ErrorMessage
+	case !isCheckerExit(interactorResult):
+		result.Verdict = constants.SystemError
+		result.Message = "交互程序运行出错：" + message
+	case verdict == constants.WrongAnswer || verdict == constants.PresentationError:
+		result.Verdict = verdict
+		result.Message = message
+	case userResult.Verdict == constants.RuntimeError:
+		result.Verdict = constants.RuntimeError
+		result.Message = userResult.ErrorMessage
+	default:
+		result.Verdict = verdict
+		result.Message = message
+	}
+	return result
+}
@@ -3,31 +3,51 @@ package judger
 import (
 	"FanCode/constants"
 	"FanCode/utils"
-	"bufio"
 	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"golang.org/x/sys/unix"
+	"io"
 	"log"
 	"os"
 	"os/exec"
-	"path"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 )
 
+// 墙上时间限制是cpu时间限制的倍数
+const wallTimeFactor = 1.5
+
 type JudgeCore struct {
+	compileCache *CompileCache // 编译结果缓存，为nil时每次都重新编译
+	workerPool   *WorkerPool   // 预先创建的cgroup池，为nil时每次执行都创建新的cgroup
+	cpuSlots     chan struct{} // 限制所有提交同时运行的用户程序数量，为nil时不限制
 }
 
 func NewJudgeCore() *JudgeCore {
 	return &JudgeCore{}
 }
 
+// NewPooledJudgeCore 创建使用编译缓存和worker池的JudgeCore，参数为nil时不使用，
+// cpuSlots为同时运行的用户程序数量上限，为0时不限制
+func NewPooledJudgeCore(compileCache *CompileCache, workerPool *WorkerPool, cpuSlots int) *JudgeCore {
+	j := &JudgeCore{
+		compileCache: compileCache,
+		workerPool:   workerPool,
+	}
+	if cpuSlots > 0 {
+		j.cpuSlots = make(chan struct{}, cpuSlots)
+	}
+	return j
+}
+
 // Compile 编译，编译时在容器外进行编译的
-// compileFiles第个文件是main文件
+// compileFiles第一个文件是main文件，编译命令由语言的LanguageSpec决定
 func (j *JudgeCore) Compile(compileFiles []string, outFilePath string, options *CompileOptions) (*CompileResult, error) {
 	result := &CompileResult{
 		Compiled:         false,
@@ -35,139 +55,89 @@ func (j *JudgeCore) Compile(compileFiles []string, outFilePath string, options *
 		CompiledFilePath: "",
 	}
 
-	var cmd *exec.Cmd
-	var ctx context.Context
-	language := constants.LanguageType(j.getLanguage(options))
-	switch language {
-	case constants.LanguageC:
-		compileFiles = append([]string{"-g", "-o", outFilePath}, compileFiles...)
-		// 创建一个带有超时时间的上下文
-		var cancel context.CancelFunc
-		if options != nil && options.LimitTime != 0 {
-			ctx, cancel = context.WithTimeout(context.Background(), time.Duration(options.LimitTime))
-			defer cancel()
-		} else {
-			ctx = context.Background()
-		}
-		cmd = exec.CommandContext(ctx, "gcc", compileFiles...)
-	case constants.LanguageJava:
-		return j.compileJava(compileFiles, outFilePath, options)
-	case constants.LanguageGo:
-		compileFiles = append([]string{"build", "-o", outFilePath}, compileFiles...)
-		var cancel context.CancelFunc
-		if options != nil && options.LimitTime != 0 {
-			ctx, cancel = context.WithTimeout(context.Background(), time.Duration(options.LimitTime))
-			defer cancel()
-		} else {
-			ctx = context.Background()
-		}
-		cmd = exec.CommandContext(ctx, "go", compileFiles...)
-	default:
+	spec, ok := GetLanguageSpec(j.getLanguage(options))
+	if !ok {
 		result.ErrorMessage = "不支持该语言\n"
 		return result, nil
 	}
-	cmd.Stdout = &bytes.Buffer{}
-	cmd.Stderr = &bytes.Buffer{}
-
-	var err error
-	if err = cmd.Start(); err == nil {
-		err = cmd.Wait()
-	}
-	if err != nil {
-		return j.setErrMessageForCompileResult(ctx, cmd, err, result, options)
+	// 不需要编译的语言直接运行main文件
+	if !spec.NeedCompile() {
+		result.Compiled = true
+		result.CompiledFilePath = compileFiles[0]
+		return result, nil
 	}
-	result.Compiled = true
-	result.CompiledFilePath = outFilePath
-	return result, nil
-}
 
-func (j *JudgeCore) getLanguage(options *CompileOptions) constants.LanguageType {
-	language := constants.LanguageC
-	if options != nil && options.Language != "" {
-		language = options.Language
-	}
-	return language
-}
-
-// 编译java语言会比较麻烦
-func (j *JudgeCore) compileJava(compileFiles []string, outFilePath string, options *CompileOptions) (*CompileResult, error) {
-	result := &CompileResult{
-		Compiled:         false,
-		ErrorMessage:     "",
-		CompiledFilePath: outFilePath,
+	// 解释型语言只做语法检查，不使用缓存
+	var cacheKey string
+	var err error
+	if j.compileCache != nil && !spec.Interpreted {
+		var includeFiles []string
+		if options != nil {
+			includeFiles = options.IncludeFiles
+		}
+		if cacheKey, err = j.compileCache.key(spec, compileFiles, includeFiles); err != nil {
+			log.Println(err)
+		} else if result.Compiled, err = j.compileCache.load(cacheKey, outFilePath); err != nil {
+			log.Println(err)
+		}
+		if result.Compiled {
+			result.CompiledFilePath = outFilePath
+			result.Cached = true
+			return result, nil
+		}
 	}
 
-	lastIndex := strings.LastIndex(outFilePath, "/")
-	// 读取main文件，规定第一个文件时main文件
-	mainClass := compileFiles[0][strings.LastIndex(compileFiles[0], "/")+1:]
-	mainClass = strings.Split(mainClass, ".")[0]
-
-	// 创建存放class文件的目录
-	classPath := path.Join(outFilePath[0:lastIndex], "classPath")
-	os.MkdirAll(classPath, os.ModePerm)
-	defer os.RemoveAll(classPath)
-
-	// 编译为class文件
-	compileFiles = append([]string{"-d", classPath}, compileFiles...)
-
-	// 设置超时
-	var cancel context.CancelFunc
-	var ctx context.Context
+	// 创建一个带有超时时间的上下文
+	ctx := context.Background()
 	if options != nil && options.LimitTime != 0 {
-		ctx, cancel = context.WithTimeout(context.Background(), time.Duration(options.LimitTime))
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(options.LimitTime))
 		defer cancel()
-	} else {
-		ctx = context.Background()
-	}
-	cmd := exec.CommandContext(ctx, "javac", compileFiles...)
-	cmd.Stdout = &bytes.Buffer{}
-	cmd.Stderr = &bytes.Buffer{}
-
-	var err error
-	if err = cmd.Start(); err == nil {
-		err = cmd.Wait()
-	}
-	if err != nil {
-		return j.setErrMessageForCompileResult(ctx, cmd, err, result, options)
 	}
 
-	// 添加一些jar包必要的文件
-	var file *os.File
-	if file, err = os.Create(path.Join(classPath, "MANIFEST.MF")); err != nil {
-		return nil, err
-	}
-	defer file.Close()
-	writer := bufio.NewWriter(file)
-	//将第一个文件当作main文件
-	if _, err = writer.WriteString("Manifest-Version: 1.0\nMain-Class: " + mainClass + "\nBuilt-By: fancode\n"); err != nil {
-		return nil, err
-	}
-	if err = writer.Flush(); err != nil {
-		return nil, err
-	}
-
-	// 打包成jar包
-	cmd = exec.Command("jar", "cvfm", outFilePath, path.Join(classPath, "MANIFEST.MF"),
-		"-C", classPath, ".")
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	if err = cmd.Start(); err != nil {
-		return nil, err
+	// 编译使用的临时目录，比如存放java的class文件
+	var buildDir string
+	if strings.Contains(spec.CompileCmd, placeholderBuildDir) {
+		if buildDir, err = os.MkdirTemp(filepath.Dir(outFilePath), "build"); err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(buildDir)
 	}
 
-	if err = cmd.Wait(); err != nil {
-		// 如果是由于超时导致的错误，则返回自定义错误
-		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
-			result.ErrorMessage = "编译超时\n"
-			return result, nil
+	// 依次执行编译命令
+	for _, command := range spec.compileCommands(compileFiles, outFilePath, buildDir) {
+		cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+		cmd.Stdout = &bytes.Buffer{}
+		cmd.Stderr = &bytes.Buffer{}
+		if err = cmd.Start(); err == nil {
+			err = cmd.Wait()
+		}
+		if err != nil {
+			return j.setErrMessageForCompileResult(ctx, cmd, err, result, options)
 		}
-		return nil, err
 	}
 	result.Compiled = true
 	result.CompiledFilePath = outFilePath
+	// 解释型语言的编译只检查语法，运行main文件
+	if spec.Interpreted {
+		result.CompiledFilePath = compileFiles[0]
+	}
+	if cacheKey != "" {
+		if err = j.compileCache.store(cacheKey, outFilePath); err != nil {
+			log.Println(err)
+		}
+	}
 	return result, nil
 }
 
+func (j *JudgeCore) getLanguage(options *CompileOptions) constants.LanguageType {
+	language := constants.LanguageC
+	if options != nil && options.Language != "" {
+		language = options.Language
+	}
+	return language
+}
+
 func (j *JudgeCore) setErrMessageForCompileResult(ctx context.Context, cmd *exec.Cmd, err error, result *CompileResult, options *CompileOptions) (*CompileResult, error) {
 	if err != nil {
 		errBytes := cmd.Stderr.(*bytes.Buffer).Bytes()
@@ -213,125 +183,359 @@ func (j *JudgeCore) maskPath(errorMessage string, excludedPaths []string, replac
 
 // Execute 运行
 func (j *JudgeCore) Execute(execFile string, inputCh <-chan []byte, outputCh chan<- ExecuteResult, exitCh <-chan string, options *ExecuteOptions) error {
+	if options == nil {
+		options = &ExecuteOptions{}
+	}
+	execDir, cmdName, cmdArg, seccomp, err := j.command(execFile, options)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for {
+			select {
+			case inputItem := <-inputCh:
+				outputCh <- j.run(execDir, cmdName, cmdArg, seccomp, bytes.NewReader(inputItem), options)
+			case <-exitCh:
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// ExecuteCases 并发运行多个输入，每个输入启动一次用户程序，最多同时运行parallelism个。
+// 每个输入运行结束后调用handle，handle可能被并发调用，返回false时不再启动下标更大的输入。
+// 输入按照下标顺序启动，所以下标更小的输入总会运行完成，调用方可以确定第一个失败的输入
+func (j *JudgeCore) ExecuteCases(execFile string, inputs []CaseInput, parallelism int, options *ExecuteOptions,
+	handle func(index int, result ExecuteResult) bool) error {
+	if options == nil {
+		options = &ExecuteOptions{}
+	}
+	execDir, cmdName, cmdArg, seccomp, err := j.command(execFile, options)
+	if err != nil {
+		return err
+	}
+	if parallelism <= 0 {
+		parallelism = 1
+	}
+
+	var mu sync.Mutex
+	next, stop := 0, len(inputs) // 下一个启动的输入，以及不再启动的第一个输入
+	var wg sync.WaitGroup
+	for i := 0; i < parallelism && i < len(inputs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				mu.Lock()
+				if next >= stop {
+					mu.Unlock()
+					return
+				}
+				index := next
+				next++
+				mu.Unlock()
+
+				result := j.runInput(execDir, cmdName, cmdArg, seccomp, inputs[index], options)
+				if !handle(index, result) {
+					mu.Lock()
+					if index+1 < stop {
+						stop = index + 1
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// command 根据语言生成运行命令，返回可执行文件所在目录、命令、参数以及允许的系统调用
+func (j *JudgeCore) command(execFile string, options *ExecuteOptions) (string, string, []string, []string, error) {
 	language := constants.LanguageC
-	if options != nil && options.Language != "" {
+	if options.Language != "" {
 		language = options.Language
 	}
-	// 根据扩展名设置执行命令
-	cmdName := ""
-	cmdArg := []string{}
-	switch language {
-	case constants.LanguageC:
-		cmdName = execFile
-	case constants.LanguageJava:
-		cmdName = "java"
-		cmdArg = []string{"-jar", execFile}
-	case constants.LanguageGo:
-		cmdName = execFile
-	default:
-		return fmt.Errorf("不支持该语言")
+	// 沙箱中会把可执行文件所在目录挂载到相同路径下，所以需要使用绝对路径
+	execFile, err := filepath.Abs(execFile)
+	if err != nil {
+		return "", "", nil, nil, err
+	}
+	// 根据语言设置执行命令
+	spec, ok := GetLanguageSpec(language)
+	if !ok {
+		return "", "", nil, nil, fmt.Errorf("不支持该语言")
+	}
+	command := spec.runCommand(execFile)
+	if len(command) == 0 {
+		return "", "", nil, nil, fmt.Errorf("语言%s的运行命令为空", language)
 	}
+	cmdName, cmdArg := command[0], append(command[1:], options.Args...)
+	// 根据语言获取允许的系统调用，当前架构不支持时不过滤
+	var seccomp []string
+	if options.Seccomp && !SeccompSupported() {
+		warnSeccompUnsupported()
+	} else if options.Seccomp {
+		if seccomp, err = GetSeccompProfile(spec.Seccomp); err != nil {
+			return "", "", nil, nil, err
+		}
+	}
+	return filepath.Dir(execFile), cmdName, cmdArg, seccomp, nil
+}
 
-	// 创建cgroup限制资源
-	cgroup, err := NewCGroup(utils.GetUUID())
+// runInput 打开输入并执行一次用户程序，输入打开失败属于系统错误
+func (j *JudgeCore) runInput(execDir string, cmdName string, cmdArg []string, seccomp []string, input CaseInput,
+	options *ExecuteOptions) ExecuteResult {
+	stdin, err := input()
 	if err != nil {
 		log.Println(err)
-		return err
-	}
-	if options != nil && options.MemoryLimit != 0 {
-		err = cgroup.SetMemoryLimit(options.MemoryLimit)
-		if err != nil {
-			log.Println(err)
-			return err
+		return ExecuteResult{
+			Verdict:      constants.SystemError,
+			ErrorMessage: err.Error() + "\n",
 		}
 	}
-	if options != nil && options.CPUQuota != 0 {
-		err = cgroup.SetCPUQuota(options.CPUQuota)
-		if err != nil {
-			return err
+	defer stdin.Close()
+	return j.run(execDir, cmdName, cmdArg, seccomp, stdin, options)
+}
+
+// run 执行一次用户程序，每次执行都使用一个新的或者重置过的cgroup，保证统计的资源只属于本次执行
+// execDir为可执行文件所在目录，开启沙箱时只有该目录对用户程序可见，seccomp为允许的系统调用，
+// stdin为nil时标准输入为空
+func (j *JudgeCore) run(execDir string, cmdName string, cmdArg []string, seccomp []string, stdin io.Reader,
+	options *ExecuteOptions) ExecuteResult {
+	if j.cpuSlots != nil {
+		j.cpuSlots <- struct{}{}
+		defer func() {
+			<-j.cpuSlots
+		}()
+	}
+	p, err := j.start(execDir, cmdName, cmdArg, seccomp, stdin, nil, options)
+	if err != nil {
+		log.Println(err)
+		return ExecuteResult{
+			Verdict:      constants.SystemError,
+			ErrorMessage: err.Error() + "\n",
 		}
 	}
+	return p.wait()
+}
 
-	go func() {
-		for {
-			select {
-			case inputItem := <-inputCh:
+// process 在cgroup和沙箱中运行的程序
+type process struct {
+	j             *JudgeCore
+	options       *ExecuteOptions
+	ctx           context.Context
+	cancel        context.CancelFunc
+	cgroup        *CGroup
+	releaseCGroup func()
+	cmd           *exec.Cmd
+	sandbox       *sandboxCmd
+	stdout        *limitedBuffer // 没有指定标准输出时保存程序的输出
+	stderr        *limitedBuffer
+	beginTime     time.Time
+}
 
-				// 设置超时上下文
-				var ctx context.Context
-				var cancel context.CancelFunc
-				if options != nil && options.LimitTime != 0 {
-					ctx, cancel = context.WithTimeout(context.Background(), time.Duration(options.LimitTime)+100)
-					defer cancel()
-				} else {
-					ctx = context.Background()
-				}
+// start 启动程序，stdout为nil时将输出保存在有长度限制的缓冲区中。
+// stdin和stdout为*os.File时直接传递给子进程，比如交互式判题中连接两个程序的管道
+func (j *JudgeCore) start(execDir string, cmdName string, cmdArg []string, seccomp []string, stdin io.Reader,
+	stdout io.Writer, options *ExecuteOptions) (*process, error) {
+	p := &process{
+		j:       j,
+		options: options,
+		cancel:  func() {},
+	}
+	// 创建cgroup限制资源
+	var err error
+	if p.cgroup, p.releaseCGroup, err = j.acquireCGroup(options); err != nil {
+		return nil, err
+	}
 
-				// 创建子进程，并将其加入cgroup
-				cmd2 := exec.CommandContext(ctx, cmdName, cmdArg...)
-				result := ExecuteResult{}
+	// 设置超时上下文，sleep等操作不占用cpu时间，所以还需要限制墙上时间
+	p.ctx = context.Background()
+	if options.LimitTime != 0 {
+		p.ctx, p.cancel = context.WithTimeout(p.ctx, time.Duration(float64(options.LimitTime)*wallTimeFactor))
+	}
 
-				cmd2.Stdin = bytes.NewReader(inputItem)
-				cmd2.Stdout = &bytes.Buffer{}
-				cmd2.Stderr = &bytes.Buffer{}
-				cmd2.SysProcAttr = &syscall.SysProcAttr{
-					Setpgid: true,
-				}
+	// 创建子进程，子进程为沙箱初始化进程，加入cgroup并完成初始化后再执行用户程序
+	if p.cmd, p.sandbox, err = newSandboxCommand(p.ctx, execDir, cmdName, cmdArg, options.Sandbox, seccomp,
+		options.StackLimit); err != nil {
+		p.cancel()
+		p.releaseCGroup()
+		return nil, err
+	}
+	// 输出超出限制时立即终止进程组，避免占用大量内存
+	if stdout == nil {
+		p.stdout = newLimitedBuffer(options.OutputLimit, func() {
+			_ = syscall.Kill(-p.cmd.Process.Pid, syscall.SIGKILL)
+		})
+		stdout = p.stdout
+	}
+	p.stderr = newLimitedBuffer(stderrLimit, nil)
+	p.cmd.Stdin = stdin
+	p.cmd.Stdout = stdout
+	p.cmd.Stderr = p.stderr
 
-				beginTime := time.Now()
-				if err = cmd2.Start(); err != nil {
-					result.Executed = false
-					result.ErrorMessage = err.Error() + "\n"
-					outputCh <- result
-					break
-				}
+	p.beginTime = time.Now()
+	if err = p.cmd.Start(); err != nil {
+		p.close()
+		return nil, err
+	}
+	p.sandbox.started(p.cmd.Process.Pid)
 
-				// 将进程写入cgroup组
-				if err = cgroup.AddPID(cmd2.Process.Pid); err != nil {
-					result.Executed = false
-					result.ErrorMessage = err.Error() + "\n"
-					outputCh <- result
-					break
-				}
+	// 将进程写入cgroup组
+	if err = p.cgroup.AddPID(p.cmd.Process.Pid); err != nil {
+		_ = p.cmd.Process.Kill()
+		_ = p.cmd.Wait()
+		p.close()
+		return nil, err
+	}
+	// 进程加入cgroup后再发送沙箱配置，保证用户程序的资源都被统计
+	if err = p.sandbox.run(); err != nil {
+		log.Println(err)
+	}
+	return p, nil
+}
 
-				// 等待程序执行
-				cmd2.Wait()
-				// 读取使用cpu和内存，以及执行时间
-				rusage := cmd2.ProcessState.SysUsage().(*syscall.Rusage)
-				result.UsedCpuTime = rusage.Utime.Sec*1000 + rusage.Utime.Usec/1000
-				result.UsedMemory = rusage.Maxrss * 1024
-				result.UsedTime = int64(time.Now().Sub(beginTime))
-
-				// 输出的错误信息
-				errMessage := string(cmd2.Stderr.(*bytes.Buffer).Bytes())
-				if options != nil && len(options.ExcludedPaths) != 0 {
-					errMessage = j.maskPath(errMessage, options.ExcludedPaths, options.ReplacementPath)
-				}
-				outMessage := cmd2.Stdout.(*bytes.Buffer).Bytes()
-				// 检测内存占用，cpu占用，以及执行时间
-				if options != nil && options.LimitTime < result.UsedTime {
-					result.Executed = false
-					result.ErrorMessage = "运行超时\n"
-				} else if options != nil && options.MemoryLimit < result.UsedMemory {
-					result.Executed = false
-					result.ErrorMessage = "内存超出限制\n"
-				} else if options != nil && options.CPUQuota < result.UsedCpuTime {
-					result.Executed = false
-					result.ErrorMessage = "cpu超出限制\n"
-				} else if len(errMessage) != 0 {
-					result.Executed = false
-					result.ErrorMessage = errMessage
-				} else {
-					result.Executed = true
-					result.Output = outMessage
-				}
-				outputCh <- result
-			case <-exitCh:
-				cgroup.Release()
-				return
-			}
+func (p *process) close() {
+	p.sandbox.close()
+	p.cancel()
+	p.releaseCGroup()
+}
+
+// wait 等待程序结束，读取资源使用情况并判断执行结果
+func (p *process) wait() ExecuteResult {
+	defer p.close()
+	result := ExecuteResult{}
+	options := p.options
+
+	// 等待程序执行，并清理进程组中残留的子进程，否则cgroup无法释放
+	_ = p.cmd.Wait()
+	result.UsedTime = int64(time.Since(p.beginTime))
+	_ = syscall.Kill(-p.cmd.Process.Pid, syscall.SIGKILL)
+	// 沙箱初始化失败属于系统错误
+	if err := p.sandbox.wait(); err != nil {
+		log.Println(err)
+		result.Verdict = constants.SystemError
+		result.ErrorMessage = err.Error() + "\n"
+		return result
+	}
+	result.ExitCode, result.Signal = p.j.getExitStatus(p.cmd.ProcessState)
+	result.RestrictedSyscall = p.sandbox.restrictedSyscall()
+
+	// 读取cgroup统计的cpu和内存，包含所有子进程和线程，cgroup统计不可用时使用rusage
+	if stats, err := p.cgroup.Stats(); err == nil {
+		result.UsedCpuTime = stats.CPUUsage
+		result.UsedMemory = stats.MemoryPeak
+		result.OOMKilled = stats.OOMKills > 0
+	} else {
+		log.Println(err)
+		rusage := p.cmd.ProcessState.SysUsage().(*syscall.Rusage)
+		result.UsedCpuTime = rusage.Utime.Nano() + rusage.Stime.Nano()
+		result.UsedMemory = rusage.Maxrss * 1024
+	}
+
+	// 输出的错误信息
+	errMessage := string(p.stderr.Bytes())
+	if len(options.ExcludedPaths) != 0 {
+		errMessage = p.j.maskPath(errMessage, options.ExcludedPaths, options.ReplacementPath)
+	}
+	var outMessage []byte
+	if p.stdout != nil {
+		outMessage = p.stdout.Bytes()
+		result.OutputSize = p.stdout.Size()
+		result.OutputTruncated = p.stdout.Overflowed()
+	}
+	result.ErrorOutput = errMessage
+	// 检测受限函数，cpu时间，内存占用，以及墙上时间
+	if result.RestrictedSyscall != "" {
+		result.Executed = false
+		result.Verdict = constants.RestrictedFunction
+		result.ErrorMessage = fmt.Sprintf("程序调用了受限函数%s\n", result.RestrictedSyscall)
+	} else if result.OutputTruncated {
+		// 保留截断后的输出，方便用户查看
+		result.Executed = false
+		result.Verdict = constants.OutputLimitExceeded
+		result.ErrorMessage = "输出超出限制\n"
+		result.Output = outMessage
+	} else if options.LimitTime != 0 && (options.LimitTime < result.UsedCpuTime || errors.Is(p.ctx.Err(), context.DeadlineExceeded)) {
+		result.Executed = false
+		result.Verdict = constants.TimeLimitExceeded
+		result.ErrorMessage = "运行超时\n"
+	} else if options.MemoryLimit != 0 && (result.OOMKilled || options.MemoryLimit < result.UsedMemory) {
+		result.Executed = false
+		result.Verdict = constants.MemoryLimitExceeded
+		result.ErrorMessage = "内存超出限制\n"
+	} else if len(errMessage) != 0 || result.ExitCode != 0 {
+		result.Executed = false
+		result.Verdict = constants.RuntimeError
+		result.ErrorMessage = errMessage
+		if result.Signal != "" {
+			result.ErrorMessage += fmt.Sprintf("程序被信号%s终止\n", result.Signal)
+		} else if result.ExitCode != 0 {
+			result.ErrorMessage += fmt.Sprintf("程序退出码为%d\n", result.ExitCode)
 		}
-	}()
+	} else {
+		result.Executed = true
+		result.Verdict = constants.RunSuccess
+		result.Output = outMessage
+	}
+	return result
+}
 
-	return nil
+// getExitStatus 读取程序的退出码，如果程序被信号终止，退出码为-1，并返回信号名称
+func (j *JudgeCore) getExitStatus(state *os.ProcessState) (int, string) {
+	status, ok := state.Sys().(syscall.WaitStatus)
+	if !ok {
+		return state.ExitCode(), ""
+	}
+	if status.Signaled() {
+		return -1, unix.SignalName(status.Signal())
+	}
+	return status.ExitStatus(), ""
+}
+
+// acquireCGroup 从worker池中借用cgroup，没有worker池时创建新的cgroup，返回的函数用于归还或者释放cgroup
+func (j *JudgeCore) acquireCGroup(options *ExecuteOptions) (*CGroup, func(), error) {
+	if j.workerPool == nil {
+		cgroup, err := j.newCGroup(options)
+		if err != nil {
+			return nil, nil, err
+		}
+		return cgroup, func() { _ = cgroup.Release() }, nil
+	}
+	w, err := j.workerPool.acquire(options)
+	if err != nil {
+		return nil, nil, err
+	}
+	return w.cgroup, func() { j.workerPool.release(w) }, nil
+}
+
+// newCGroup 根据执行选项创建一个设置好资源限制的cgroup
+func (j *JudgeCore) newCGroup(options *ExecuteOptions) (*CGroup, error) {
+	cgroup, err := NewCGroup(utils.GetUUID())
+	if err != nil {
+		return nil, err
+	}
+	if options.MemoryLimit != 0 {
+		if err = cgroup.SetMemoryLimit(options.MemoryLimit); err != nil {
+			cgroup.Release()
+			return nil, err
+		}
+	}
+	if options.CPUQuota != 0 {
+		if err = cgroup.SetCPUQuota(options.CPUQuota); err != nil {
+			cgroup.Release()
+			return nil, err
+		}
+	}
+	if options.PidsLimit != 0 {
+		if err = cgroup.SetPidsLimit(options.PidsLimit); err != nil {
+			cgroup.Release()
+			return nil, err
+		}
+	}
+	return cgroup, nil
 }
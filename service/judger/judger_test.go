@@ -2,20 +2,27 @@ package judger
 
 import (
 	"FanCode/constants"
+	"context"
 	"gotest.tools/v3/assert"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
 
 func TestJudgeCore_Execute(t *testing.T) {
-	execute(constants.ProgramC, t)
-	execute(constants.ProgramJava, t)
+	execute(constants.LanguageC, t)
+	execute(constants.LanguageCpp, t)
+	execute(constants.LanguagePython, t)
+	execute(constants.LanguageJavaScript, t)
+	execute(constants.LanguageJava, t)
 }
 
-func execute(language string, t *testing.T) {
+func execute(language constants.LanguageType, t *testing.T) {
 	judgeCore := NewJudgeCore()
 	// 程序进行编译
 	input := make(chan []byte)
@@ -25,12 +32,18 @@ func execute(language string, t *testing.T) {
 	// 编译
 	var compileFiles []string
 	switch language {
-	case constants.ProgramC:
+	case constants.LanguageC:
 		compileFiles = []string{"./test_file/test_execute.c"}
-	case constants.ProgramJava:
+	case constants.LanguageCpp:
+		compileFiles = []string{"./test_file/test_execute.cpp"}
+	case constants.LanguagePython:
+		compileFiles = []string{"./test_file/test_execute.py"}
+	case constants.LanguageJavaScript:
+		compileFiles = []string{"./test_file/test_execute.js"}
+	case constants.LanguageJava:
 		compileFiles = []string{"./test_file/test_execute.java"}
 	}
-	_, err := judgeCore.Compile(compileFiles, "./test_file/test_execute", &CompileOptions{
+	compileResult, err := judgeCore.Compile(compileFiles, "./test_file/test_execute", &CompileOptions{
 		Language:  language,
 		LimitTime: int64(1000 * time.Second),
 	})
@@ -39,6 +52,9 @@ func execute(language string, t *testing.T) {
 		return
 	}
 	defer os.Remove("./test_file/test_execute")
+	// py_compile会生成字节码缓存
+	defer os.RemoveAll("./test_file/__pycache__")
+	assert.Equal(t, true, compileResult.Compiled)
 
 	// 运行
 	executeOption := &ExecuteOptions{
@@ -48,7 +64,7 @@ func execute(language string, t *testing.T) {
 		CPUQuota:    100000,
 	}
 
-	err = judgeCore.Execute("./test_file/test_execute", input, output, exitCh, executeOption)
+	err = judgeCore.Execute(compileResult.CompiledFilePath, input, output, exitCh, executeOption)
 	if err != nil {
 		assert.NilError(t, err)
 		return
@@ -65,6 +81,7 @@ func execute(language string, t *testing.T) {
 		select {
 		case result := <-output:
 			assert.Equal(t, true, result.Executed)
+			assert.Equal(t, constants.RunSuccess, result.Verdict)
 			assert.Equal(t, strconv.Itoa(a+b)+"\n", string(result.Output))
 		}
 	}
@@ -89,7 +106,7 @@ func TestJudgeCore_Timeout(t *testing.T) {
 	}()
 
 	executeOption := &ExecuteOptions{
-		Language:    constants.ProgramC,
+		Language:    constants.LanguageC,
 		LimitTime:   int64(1 * time.Second),
 		MemoryLimit: 1 * 1024 * 1024, //限制1m
 		CPUQuota:    10000,           //限制cpu
@@ -100,6 +117,7 @@ func TestJudgeCore_Timeout(t *testing.T) {
 	select {
 	case result := <-output:
 		assert.Equal(t, false, result.Executed)
+		assert.Equal(t, constants.TimeLimitExceeded, result.Verdict)
 		assert.Equal(t, "运行超时\n", result.ErrorMessage)
 	}
 }
@@ -123,7 +141,7 @@ func TestJudgeCore_MemoryOut(t *testing.T) {
 	}()
 
 	executeOption := &ExecuteOptions{
-		Language:    constants.ProgramC,
+		Language:    constants.LanguageC,
 		LimitTime:   int64(1 * time.Second),
 		MemoryLimit: 1 * 1024 * 1024, //限制1m
 		CPUQuota:    10000,           //限制cpu
@@ -133,11 +151,211 @@ func TestJudgeCore_MemoryOut(t *testing.T) {
 	input <- []byte("1 2")
 	select {
 	case result := <-output:
-		assert.Equal(t, false, result.Executed)
+		assert.Equal(t, false, result.Executed, result.ErrorMessage)
+		assert.Equal(t, constants.MemoryLimitExceeded, result.Verdict)
 		assert.Equal(t, "内存超出限制\n", result.ErrorMessage)
 	}
 }
 
+func TestJudgeCore_UsedResources(t *testing.T) {
+	judgeCore := NewJudgeCore()
+	dir := t.TempDir()
+	for _, name := range []string{"test_memory_usage", "test_cpu_child"} {
+		_, err := judgeCore.Compile([]string{"./test_file/" + name + ".c"}, filepath.Join(dir, name),
+			&CompileOptions{LimitTime: int64(2 * time.Second)})
+		assert.NilError(t, err)
+	}
+	executeOption := &ExecuteOptions{
+		Language:    constants.LanguageC,
+		LimitTime:   int64(5 * time.Second),
+		MemoryLimit: 256 * 1024 * 1024,
+	}
+
+	// 内存使用为cgroup统计的峰值，不小于程序实际使用的内存
+	result := executeOnce(t, judgeCore, filepath.Join(dir, "test_memory_usage"), "64", executeOption)
+	assert.Equal(t, constants.RunSuccess, result.Verdict, result.ErrorMessage)
+	assert.Assert(t, result.UsedMemory >= 64*1024*1024, "UsedMemory: %d", result.UsedMemory)
+	assert.Equal(t, false, result.OOMKilled)
+
+	// cpu时间包含子进程以及内核态的时间，父进程和子进程各使用300ms。
+	// cgroup和进程的cpu时钟统计方式不同，只检查明显超过单个进程的时间
+	result = executeOnce(t, judgeCore, filepath.Join(dir, "test_cpu_child"), "300", executeOption)
+	assert.Equal(t, constants.RunSuccess, result.Verdict, result.ErrorMessage)
+	assert.Equal(t, "done\n", string(result.Output))
+	assert.Assert(t, result.UsedCpuTime >= int64(500*time.Millisecond), "UsedCpuTime: %d", result.UsedCpuTime)
+
+	// 超出内存限制被oom killer杀死时为内存超限，而不是被SIGKILL终止的运行错误
+	executeOption.MemoryLimit = 32 * 1024 * 1024
+	result = executeOnce(t, judgeCore, filepath.Join(dir, "test_memory_usage"), "64", executeOption)
+	assert.Equal(t, true, result.OOMKilled)
+	assert.Equal(t, "SIGKILL", result.Signal)
+	assert.Equal(t, constants.MemoryLimitExceeded, result.Verdict)
+	assert.Equal(t, "内存超出限制\n", result.ErrorMessage)
+}
+
+func TestJudgeCore_RuntimeError(t *testing.T) {
+	judgeCore := NewJudgeCore()
+	input := make(chan []byte)
+	output := make(chan ExecuteResult)
+	exitCh := make(chan string)
+
+	// 编译
+	_, err := judgeCore.Compile([]string{"./test_file/test_runtime_error.c"}, "./test_file/test_runtime_error",
+		&CompileOptions{LimitTime: int64(2 * time.Second)})
+	assert.NilError(t, err)
+	defer func() {
+		err = os.Remove("./test_file/test_runtime_error")
+		assert.NilError(t, err)
+		exitCh <- "exit"
+	}()
+
+	executeOption := &ExecuteOptions{
+		Language:    constants.LanguageC,
+		LimitTime:   int64(1 * time.Second),
+		MemoryLimit: 100 * 1024 * 1024,
+	}
+	err = judgeCore.Execute("./test_file/test_runtime_error", input, output, exitCh, executeOption)
+	assert.NilError(t, err)
+	input <- []byte("1 2")
+	result := <-output
+	assert.Equal(t, false, result.Executed)
+	assert.Equal(t, constants.RuntimeError, result.Verdict)
+	assert.Equal(t, "SIGSEGV", result.Signal)
+	assert.Equal(t, -1, result.ExitCode)
+}
+
+func TestJudgeCore_Sandbox(t *testing.T) {
+	judgeCore := NewJudgeCore()
+	input := make(chan []byte)
+	output := make(chan ExecuteResult)
+	exitCh := make(chan string)
+
+	// 沙箱中的用户需要能访问可执行文件所在目录
+	dir, err := os.MkdirTemp("", "sandbox")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NilError(t, os.Chmod(dir, 0755))
+	execFile := filepath.Join(dir, "test_sandbox")
+	_, err = judgeCore.Compile([]string{"./test_file/test_sandbox.c"}, execFile,
+		&CompileOptions{LimitTime: int64(2 * time.Second)})
+	assert.NilError(t, err)
+	defer func() {
+		exitCh <- "exit"
+	}()
+
+	executeOption := &ExecuteOptions{
+		Language:    constants.LanguageC,
+		LimitTime:   int64(1 * time.Second),
+		MemoryLimit: 100 * 1024 * 1024,
+		Sandbox:     DefaultSandboxOptions(),
+	}
+	err = judgeCore.Execute(execFile, input, output, exitCh, executeOption)
+	assert.NilError(t, err)
+	// 宿主机上的文件不可见，程序是新的pid命名空间中的1号进程
+	input <- []byte("./test_file/test_sandbox.c")
+	result := <-output
+	assert.Equal(t, constants.RunSuccess, result.Verdict, result.ErrorMessage)
+	assert.Equal(t, "0 1\n", string(result.Output))
+	// 可执行文件所在目录只读可见
+	input <- []byte(execFile)
+	result = <-output
+	assert.Equal(t, constants.RunSuccess, result.Verdict, result.ErrorMessage)
+	assert.Equal(t, "1 1\n", string(result.Output))
+}
+
+func TestNewSandboxCommand_Env(t *testing.T) {
+	// 用户程序不继承判题服务的环境变量
+	t.Setenv("FANCODE_TEST_SECRET", "secret")
+	dir := t.TempDir()
+	for _, options := range []*SandboxOptions{nil, DefaultSandboxOptions()} {
+		_, s, err := newSandboxCommand(context.Background(), dir, "sh", nil, options, nil, 0)
+		assert.NilError(t, err)
+		s.close()
+		assert.Assert(t, !strings.Contains(strings.Join(s.config.Env, "\n"), "FANCODE_TEST_SECRET"))
+		if options == nil {
+			assert.DeepEqual(t, sandboxEnv(dir), s.config.Env)
+		} else {
+			assert.DeepEqual(t, sandboxDefaultEnv, s.config.Env)
+		}
+	}
+}
+
+func TestJudgeCore_RestrictedFunction(t *testing.T) {
+	judgeCore := NewJudgeCore()
+
+	// 沙箱中的用户需要能访问可执行文件所在目录
+	dir, err := os.MkdirTemp("", "seccomp")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NilError(t, os.Chmod(dir, 0755))
+	for _, name := range []string{"test_execute", "test_restricted", "test_sendmsg"} {
+		_, err = judgeCore.Compile([]string{"./test_file/" + name + ".c"}, filepath.Join(dir, name),
+			&CompileOptions{LimitTime: int64(2 * time.Second)})
+		assert.NilError(t, err)
+	}
+
+	for _, sandbox := range []*SandboxOptions{nil, DefaultSandboxOptions()} {
+		executeOption := &ExecuteOptions{
+			Language:    constants.LanguageC,
+			LimitTime:   int64(1 * time.Second),
+			MemoryLimit: 100 * 1024 * 1024,
+			Sandbox:     sandbox,
+			Seccomp:     true,
+		}
+		// 正常程序不受影响
+		result := executeOnce(t, judgeCore, filepath.Join(dir, "test_execute"), "1 2", executeOption)
+		assert.Equal(t, constants.RunSuccess, result.Verdict, result.ErrorMessage)
+		assert.Equal(t, "3\n", string(result.Output))
+		// 调用fork被拦截，glibc中fork使用clone系统调用
+		result = executeOnce(t, judgeCore, filepath.Join(dir, "test_restricted"), "1 2", executeOption)
+		assert.Equal(t, false, result.Executed)
+		assert.Equal(t, constants.RestrictedFunction, result.Verdict)
+		assert.Equal(t, "clone", result.RestrictedSyscall)
+		// 只有沙箱初始化进程可以使用sendmsg发送监听描述符
+		result = executeOnce(t, judgeCore, filepath.Join(dir, "test_sendmsg"), "1 2", executeOption)
+		assert.Equal(t, constants.RestrictedFunction, result.Verdict)
+		assert.Equal(t, "sendmsg", result.RestrictedSyscall)
+	}
+}
+
+func TestJudgeCore_OutputLimit(t *testing.T) {
+	judgeCore := NewJudgeCore()
+	_, err := judgeCore.Compile([]string{"./test_file/test_output_limit.c"}, "./test_file/test_output_limit",
+		&CompileOptions{LimitTime: int64(2 * time.Second)})
+	assert.NilError(t, err)
+	defer os.Remove("./test_file/test_output_limit")
+
+	executeOption := &ExecuteOptions{
+		Language:    constants.LanguageC,
+		LimitTime:   int64(5 * time.Second),
+		MemoryLimit: 100 * 1024 * 1024,
+		OutputLimit: 1024 * 1024,
+	}
+	beginTime := time.Now()
+	result := executeOnce(t, judgeCore, "./test_file/test_output_limit", "", executeOption)
+	// 输出超限后立即终止，不需要等到超时
+	assert.Assert(t, time.Since(beginTime) < 5*time.Second)
+	assert.Equal(t, false, result.Executed)
+	assert.Equal(t, constants.OutputLimitExceeded, result.Verdict)
+	assert.Equal(t, true, result.OutputTruncated)
+	assert.Equal(t, 1024*1024, len(result.Output))
+	assert.Assert(t, result.OutputSize > int64(len(result.Output)))
+}
+
+// executeOnce 使用一个输入执行程序
+func executeOnce(t *testing.T, judgeCore *JudgeCore, execFile string, input string, options *ExecuteOptions) ExecuteResult {
+	inputCh := make(chan []byte)
+	outputCh := make(chan ExecuteResult)
+	exitCh := make(chan string)
+	err := judgeCore.Execute(execFile, inputCh, outputCh, exitCh, options)
+	assert.NilError(t, err)
+	defer func() {
+		exitCh <- "exit"
+	}()
+	inputCh <- []byte(input)
+	return <-outputCh
+}
+
 func TestJudgeCore_Compile(t *testing.T) {
 	judgeCore := NewJudgeCore()
 	// 编译
@@ -150,3 +368,219 @@ func TestJudgeCore_Compile(t *testing.T) {
 	assert.Equal(t, false, compileResult.Compiled)
 	return
 }
+
+func TestJudgeCore_Traceback(t *testing.T) {
+	judgeCore := NewJudgeCore()
+	dir, err := filepath.Abs("./test_file")
+	assert.NilError(t, err)
+	// 解释型语言直接运行源文件，异常信息中屏蔽源文件所在目录
+	result := executeOnce(t, judgeCore, "./test_file/test_traceback.py", "1 2", &ExecuteOptions{
+		Language:      constants.LanguagePython,
+		LimitTime:     int64(2 * time.Second),
+		MemoryLimit:   100 * 1024 * 1024,
+		ExcludedPaths: []string{dir},
+	})
+	assert.Equal(t, false, result.Executed)
+	assert.Equal(t, constants.RuntimeError, result.Verdict)
+	assert.Equal(t, 1, result.ExitCode)
+	assert.Assert(t, strings.Contains(result.ErrorMessage, "ZeroDivisionError"))
+	assert.Assert(t, strings.Contains(result.ErrorMessage, `File "/test_traceback.py"`))
+	assert.Assert(t, !strings.Contains(result.ErrorMessage, dir))
+}
+
+func TestJudgeCore_Check(t *testing.T) {
+	judgeCore := NewJudgeCore()
+	// 沙箱中的用户需要能访问特判程序所在目录
+	dir, err := os.MkdirTemp("", "checker")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NilError(t, os.Chmod(dir, 0755))
+	checkerFile := filepath.Join(dir, "checker")
+	compileResult, err := judgeCore.Compile([]string{"./test_file/test_checker.c"}, checkerFile,
+		&CompileOptions{LimitTime: int64(10 * time.Second)})
+	assert.NilError(t, err)
+	assert.Equal(t, true, compileResult.Compiled, compileResult.ErrorMessage)
+
+	options := &ExecuteOptions{
+		Language:    constants.LanguageC,
+		LimitTime:   int64(time.Second),
+		MemoryLimit: 100 * 1024 * 1024,
+		Sandbox:     DefaultSandboxOptions(),
+		Seccomp:     true,
+	}
+	cases := []struct {
+		output  string
+		verdict int
+		message string
+	}{
+		{"0.3333333\n", constants.Accepted, "ok answer is 0.333333"},
+		{"0.33\n", constants.WrongAnswer, "wrong answer expected 0.333333, found 0.330000"},
+		{"abc\n", constants.PresentationError, "wrong answer output is not a number"},
+	}
+	for _, c := range cases {
+		result, err := judgeCore.Check(checkerFile, strings.NewReader("1 3\n"), []byte(c.output),
+			strings.NewReader("0.333333\n"), options)
+		assert.NilError(t, err)
+		assert.Equal(t, c.verdict, result.Verdict, result.Message)
+		assert.Equal(t, c.message, result.Message)
+	}
+
+	// 特判程序本身出错
+	result, err := judgeCore.Check(checkerFile, strings.NewReader("1 3\n"), []byte("1\n"), strings.NewReader("x\n"), options)
+	assert.NilError(t, err)
+	assert.Equal(t, constants.SystemError, result.Verdict)
+	assert.Equal(t, "fail answer is not a number", result.Message)
+	// 检查的文件已经删除
+	entries, err := os.ReadDir(dir)
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(entries))
+}
+
+func TestJudgeCore_Interact(t *testing.T) {
+	judgeCore := NewJudgeCore()
+	dir, err := os.MkdirTemp("", "interactor")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NilError(t, os.Chmod(dir, 0755))
+	interactorFile := filepath.Join(dir, "interactor")
+	execFile := filepath.Join(dir, "main")
+	compileResult, err := judgeCore.Compile([]string{"./test_file/test_interactor.c"}, interactorFile,
+		&CompileOptions{LimitTime: int64(10 * time.Second)})
+	assert.NilError(t, err)
+	assert.Equal(t, true, compileResult.Compiled, compileResult.ErrorMessage)
+	compileResult, err = judgeCore.Compile([]string{"./test_file/test_interact.c"}, execFile,
+		&CompileOptions{LimitTime: int64(10 * time.Second)})
+	assert.NilError(t, err)
+	assert.Equal(t, true, compileResult.Compiled, compileResult.ErrorMessage)
+
+	options := &ExecuteOptions{
+		Language:    constants.LanguageC,
+		LimitTime:   int64(time.Second),
+		MemoryLimit: 100 * 1024 * 1024,
+		Sandbox:     DefaultSandboxOptions(),
+		Seccomp:     true,
+	}
+	cases := []struct {
+		input   string
+		verdict int
+		message string
+	}{
+		{"100 37 7\n", constants.Accepted, "ok found 37 in 3 guesses"},
+		{"100 50 7\n", constants.Accepted, "ok found 50 in 1 guesses"},
+		{"100 37 2\n", constants.WrongAnswer, "wrong answer too many guesses"},
+	}
+	for _, c := range cases {
+		result, err := judgeCore.Interact(execFile, interactorFile, strings.NewReader(c.input), nil, options, options)
+		assert.NilError(t, err)
+		assert.Equal(t, c.verdict, result.Verdict, result.Message)
+		assert.Equal(t, c.message, result.Message)
+	}
+
+	// 用户程序超时，交互程序等待输入
+	timeoutFile := filepath.Join(dir, "timeout")
+	compileResult, err = judgeCore.Compile([]string{"./test_file/test_timeout.c"}, timeoutFile,
+		&CompileOptions{LimitTime: int64(10 * time.Second)})
+	assert.NilError(t, err)
+	assert.Equal(t, true, compileResult.Compiled, compileResult.ErrorMessage)
+	result, err := judgeCore.Interact(timeoutFile, interactorFile, strings.NewReader("100 37 7\n"), nil, options, options)
+	assert.NilError(t, err)
+	assert.Equal(t, constants.TimeLimitExceeded, result.Verdict, result.Message)
+}
+
+func TestJudgeCore_StackLimit(t *testing.T) {
+	judgeCore := NewJudgeCore()
+	_, err := judgeCore.Compile([]string{"./test_file/test_stack.c"}, "./test_file/test_stack",
+		&CompileOptions{LimitTime: int64(2 * time.Second)})
+	assert.NilError(t, err)
+	defer os.Remove("./test_file/test_stack")
+
+	// 递归使用约32MB的栈，超过系统默认的8MB
+	run := func(stackLimit int64) ExecuteResult {
+		input := make(chan []byte)
+		output := make(chan ExecuteResult)
+		exitCh := make(chan string)
+		err := judgeCore.Execute("./test_file/test_stack", input, output, exitCh, &ExecuteOptions{
+			Language:    constants.LanguageC,
+			LimitTime:   int64(2 * time.Second),
+			MemoryLimit: 256 * 1024 * 1024,
+			StackLimit:  stackLimit,
+		})
+		assert.NilError(t, err)
+		defer func() {
+			exitCh <- "exit"
+		}()
+		input <- []byte("32767")
+		return <-output
+	}
+	result := run(128 * 1024 * 1024)
+	assert.Equal(t, constants.RunSuccess, result.Verdict)
+	assert.Equal(t, "32768\n", string(result.Output))
+
+	result = run(4 * 1024 * 1024)
+	assert.Equal(t, constants.RuntimeError, result.Verdict)
+	assert.Equal(t, "SIGSEGV", result.Signal)
+}
+
+func TestJudgeCore_ExecuteCases(t *testing.T) {
+	judgeCore := NewPooledJudgeCore(nil, nil, 2)
+	_, err := judgeCore.Compile([]string{"./test_file/test_execute.c"}, "./test_file/test_execute_cases",
+		&CompileOptions{LimitTime: int64(2 * time.Second)})
+	assert.NilError(t, err)
+	defer os.Remove("./test_file/test_execute_cases")
+
+	inputs := make([]CaseInput, 20)
+	for i := range inputs {
+		inputs[i] = BytesInput([]byte(strconv.Itoa(i) + " " + strconv.Itoa(i)))
+	}
+	options := &ExecuteOptions{
+		Language:    constants.LanguageC,
+		LimitTime:   int64(2 * time.Second),
+		MemoryLimit: 100 * 1024 * 1024,
+	}
+	// 运行所有输入，每个输入只运行一次。handle在worker协程中调用，不能在其中断言，运行结束后再检查结果
+	verdicts := make([]int, len(inputs))
+	outputs := make([]string, len(inputs))
+	err = judgeCore.ExecuteCases("./test_file/test_execute_cases", inputs, 4, options,
+		func(index int, result ExecuteResult) bool {
+			verdicts[index] = result.Verdict
+			outputs[index] = string(result.Output)
+			return true
+		})
+	assert.NilError(t, err)
+	for i, output := range outputs {
+		assert.Equal(t, constants.RunSuccess, verdicts[i])
+		assert.Equal(t, strconv.Itoa(2*i)+"\n", output)
+	}
+
+	// 第5个输入失败后不再启动后面的输入，前面的输入都会运行完成
+	var mu sync.Mutex
+	executed := make(map[int]bool)
+	err = judgeCore.ExecuteCases("./test_file/test_execute_cases", inputs, 4, options,
+		func(index int, result ExecuteResult) bool {
+			mu.Lock()
+			executed[index] = true
+			mu.Unlock()
+			return index != 5
+		})
+	assert.NilError(t, err)
+	for i := 0; i <= 5; i++ {
+		assert.Assert(t, executed[i])
+	}
+	// 最多还有3个正在运行的输入
+	assert.Assert(t, len(executed) <= 9)
+
+	// 输入保存在文件中，文件不存在时为系统错误
+	inputFile := filepath.Join(t.TempDir(), "input.txt")
+	assert.NilError(t, os.WriteFile(inputFile, []byte("3 4\n"), 0644))
+	fileInputs := []CaseInput{FileInput(inputFile), FileInput(inputFile + ".missing")}
+	results := make([]ExecuteResult, len(fileInputs))
+	err = judgeCore.ExecuteCases("./test_file/test_execute_cases", fileInputs, 2, options,
+		func(index int, result ExecuteResult) bool {
+			results[index] = result
+			return true
+		})
+	assert.NilError(t, err)
+	assert.Equal(t, constants.RunSuccess, results[0].Verdict)
+	assert.Equal(t, "7\n", string(results[0].Output))
+	assert.Equal(t, constants.SystemError, results[1].Verdict)
+}
@@ -0,0 +1,234 @@
+package judger
+
+import (
+	"FanCode/config"
+	"FanCode/constants"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// 命令模板中的占位符
+const (
+	placeholderSources  = "{sources}"  // 所有源文件，第一个文件是main文件
+	placeholderOut      = "{out}"      // 编译输出文件
+	placeholderBuildDir = "{buildDir}" // 编译使用的临时目录，编译完成后删除
+	placeholderMainName = "{mainName}" // main文件去掉扩展名后的名称，比如java的主类名
+	placeholderExec     = "{exec}"     // 运行的文件，不需要编译的语言为main文件
+	placeholderExecDir  = "{execDir}"  // 运行的文件所在目录
+	placeholderStd      = "{std}"      // 语言标准，比如c++17
+	// 编译命令中分隔多条命令
+	commandSeparator = "&&"
+)
+
+// LanguageSpec 编程语言的编译、运行以及调试方式
+type LanguageSpec struct {
+	Name         constants.LanguageType
+	SourceFile   string  // main文件名称，比如main.c
+	CompileCmd   string  // 编译命令模板，多条命令使用&&分隔，为空表示不需要编译
+	RunCmd       string  // 运行命令模板
+	TimeFactor   float64 // 时间限制的倍数
+	MemoryFactor float64 // 内存限制的倍数
+	Debugger     string  // 调试器类型，取值为constants中的GdbCore等，为空表示不支持调试
+	Template     string  // acm模式代码模板文件路径
+	Seccomp      string  // 系统调用过滤规则名称
+	Standard     string  // 语言标准，替换编译命令中的{std}
+	// 解释型语言，编译命令只做语法检查，不生成输出文件，运行时直接执行main文件
+	Interpreted bool
+}
+
+var (
+	languageSpecsLock sync.RWMutex
+	// languageSpecs 内置的语言，可以通过配置文件覆盖
+	languageSpecs = map[constants.LanguageType]*LanguageSpec{
+		constants.LanguageC: {
+			Name:         constants.LanguageC,
+			SourceFile:   "main.c",
+			CompileCmd:   "gcc -g -o {out} {sources}",
+			RunCmd:       "{exec}",
+			TimeFactor:   1,
+			MemoryFactor: 1,
+			Debugger:     constants.GdbCore,
+			Template:     "./resources/acmTemplate/c",
+			Seccomp:      seccompProfileNative,
+		},
+		constants.LanguageCpp: {
+			Name:         constants.LanguageCpp,
+			SourceFile:   "main.cpp",
+			CompileCmd:   "g++ -g -O2 -std={std} -o {out} {sources}",
+			RunCmd:       "{exec}",
+			TimeFactor:   1,
+			MemoryFactor: 1,
+			Debugger:     constants.GdbCore,
+			Template:     "./resources/acmTemplate/cpp",
+			Seccomp:      seccompProfileNative,
+			Standard:     "c++17",
+		},
+		constants.LanguageJava: {
+			Name:         constants.LanguageJava,
+			SourceFile:   "Main.java",
+			CompileCmd:   "javac -encoding UTF-8 -d {buildDir} {sources} && jar cfe {out} {mainName} -C {buildDir} .",
+			RunCmd:       "java -jar {exec}",
+			TimeFactor:   2,
+			MemoryFactor: 2,
+			Template:     "./resources/acmTemplate/java",
+			Seccomp:      seccompProfileJVM,
+		},
+		constants.LanguagePython: {
+			Name:         constants.LanguagePython,
+			SourceFile:   "main.py",
+			CompileCmd:   "python3 -m py_compile {sources}",
+			RunCmd:       "python3 {exec}",
+			TimeFactor:   3,
+			MemoryFactor: 2,
+			Template:     "./resources/acmTemplate/python",
+			Seccomp:      seccompProfilePython,
+			Interpreted:  true,
+		},
+		constants.LanguageJavaScript: {
+			Name:         constants.LanguageJavaScript,
+			SourceFile:   "main.js",
+			CompileCmd:   "node --check {sources}",
+			RunCmd:       "node {exec}",
+			TimeFactor:   3,
+			MemoryFactor: 2,
+			Template:     "./resources/acmTemplate/javascript",
+			Seccomp:      seccompProfileNode,
+			Interpreted:  true,
+		},
+		constants.LanguageGo: {
+			Name:         constants.LanguageGo,
+			SourceFile:   "main.go",
+			CompileCmd:   "go build -o {out} {sources}",
+			RunCmd:       "{exec}",
+			TimeFactor:   1,
+			MemoryFactor: 1,
+			Template:     "./resources/acmTemplate/go",
+			Seccomp:      seccompProfileGo,
+		},
+	}
+)
+
+// InitLanguageSpecs 加载配置文件中的语言，同名的语言会覆盖内置的配置
+func InitLanguageSpecs(configs []*config.LanguageConfig) error {
+	for _, c := range configs {
+		spec := &LanguageSpec{}
+		if old, ok := GetLanguageSpec(constants.LanguageType(c.Name)); ok {
+			*spec = *old
+		}
+		spec.Name = constants.LanguageType(c.Name)
+		if c.SourceFile != "" {
+			spec.SourceFile = c.SourceFile
+		}
+		if c.CompileCmd != "" {
+			spec.CompileCmd = c.CompileCmd
+		}
+		if c.RunCmd != "" {
+			spec.RunCmd = c.RunCmd
+		}
+		if c.TimeFactor != 0 {
+			spec.TimeFactor = c.TimeFactor
+		}
+		if c.MemoryFactor != 0 {
+			spec.MemoryFactor = c.MemoryFactor
+		}
+		if c.Debugger != "" {
+			spec.Debugger = c.Debugger
+		}
+		if c.Template != "" {
+			spec.Template = c.Template
+		}
+		if c.Seccomp != "" {
+			spec.Seccomp = c.Seccomp
+		}
+		if c.Standard != "" {
+			spec.Standard = c.Standard
+		}
+		if c.Interpreted {
+			spec.Interpreted = true
+		}
+		if err := RegisterLanguageSpec(spec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterLanguageSpec 注册一个语言，已经存在时覆盖
+func RegisterLanguageSpec(spec *LanguageSpec) error {
+	if spec.Name == "" || spec.SourceFile == "" || spec.RunCmd == "" {
+		return fmt.Errorf("language %s: name, sourceFile and runCmd are required", spec.Name)
+	}
+	if spec.TimeFactor == 0 {
+		spec.TimeFactor = 1
+	}
+	if spec.MemoryFactor == 0 {
+		spec.MemoryFactor = 1
+	}
+	languageSpecsLock.Lock()
+	defer languageSpecsLock.Unlock()
+	languageSpecs[spec.Name] = spec
+	return nil
+}
+
+// GetLanguageSpec 获取语言的配置
+func GetLanguageSpec(language constants.LanguageType) (*LanguageSpec, bool) {
+	languageSpecsLock.RLock()
+	defer languageSpecsLock.RUnlock()
+	spec, ok := languageSpecs[language]
+	return spec, ok
+}
+
+// GetLanguages 获取所有支持的语言
+func GetLanguages() []constants.LanguageType {
+	languageSpecsLock.RLock()
+	defer languageSpecsLock.RUnlock()
+	languages := make([]constants.LanguageType, 0, len(languageSpecs))
+	for language := range languageSpecs {
+		languages = append(languages, language)
+	}
+	return languages
+}
+
+// NeedCompile 是否需要编译
+func (s *LanguageSpec) NeedCompile() bool {
+	return strings.TrimSpace(s.CompileCmd) != ""
+}
+
+// compileCommands 根据模板生成编译命令
+func (s *LanguageSpec) compileCommands(sources []string, out string, buildDir string) [][]string {
+	mainName := strings.TrimSuffix(path.Base(sources[0]), path.Ext(sources[0]))
+	replacer := strings.NewReplacer(placeholderOut, out, placeholderBuildDir, buildDir,
+		placeholderMainName, mainName, placeholderStd, s.Standard)
+	var commands [][]string
+	var command []string
+	for _, field := range strings.Fields(s.CompileCmd) {
+		switch field {
+		case commandSeparator:
+			if len(command) != 0 {
+				commands = append(commands, command)
+			}
+			command = nil
+		case placeholderSources:
+			command = append(command, sources...)
+		default:
+			command = append(command, replacer.Replace(field))
+		}
+	}
+	if len(command) != 0 {
+		commands = append(commands, command)
+	}
+	return commands
+}
+
+// runCommand 根据模板生成运行命令
+func (s *LanguageSpec) runCommand(execFile string) []string {
+	replacer := strings.NewReplacer(placeholderExec, execFile, placeholderExecDir, filepath.Dir(execFile))
+	var command []string
+	for _, field := range strings.Fields(s.RunCmd) {
+		command = append(command, replacer.Replace(field))
+	}
+	return command
+}
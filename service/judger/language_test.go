@@ -0,0 +1,62 @@
+package judger
+
+import (
+	"FanCode/config"
+	"FanCode/constants"
+	"gotest.tools/v3/assert"
+	"testing"
+)
+
+func TestLanguageSpec_Commands(t *testing.T) {
+	spec, ok := GetLanguageSpec(constants.LanguageJava)
+	assert.Assert(t, ok)
+	commands := spec.compileCommands([]string{"/tmp/a/Main.java", "/tmp/a/Util.java"}, "/tmp/a/main", "/tmp/a/build")
+	assert.DeepEqual(t, [][]string{
+		{"javac", "-encoding", "UTF-8", "-d", "/tmp/a/build", "/tmp/a/Main.java", "/tmp/a/Util.java"},
+		{"jar", "cfe", "/tmp/a/main", "Main", "-C", "/tmp/a/build", "."},
+	}, commands)
+	assert.DeepEqual(t, []string{"java", "-jar", "/tmp/a/main"}, spec.runCommand("/tmp/a/main"))
+
+	// c++的语言标准替换到编译命令中
+	spec, ok = GetLanguageSpec(constants.LanguageCpp)
+	assert.Assert(t, ok)
+	commands = spec.compileCommands([]string{"/tmp/a/main.cpp"}, "/tmp/a/main", "/tmp/a/build")
+	assert.DeepEqual(t, [][]string{
+		{"g++", "-g", "-O2", "-std=c++17", "-o", "/tmp/a/main", "/tmp/a/main.cpp"},
+	}, commands)
+}
+
+func TestInitLanguageSpecs(t *testing.T) {
+	// 测试结束后恢复内置的语言
+	cSpec, _ := GetLanguageSpec(constants.LanguageC)
+	defer func() {
+		languageSpecsLock.Lock()
+		defer languageSpecsLock.Unlock()
+		languageSpecs[constants.LanguageC] = cSpec
+		delete(languageSpecs, "python3")
+	}()
+	err := InitLanguageSpecs([]*config.LanguageConfig{
+		{Name: "python3", SourceFile: "main.py", RunCmd: "python3 {exec}", TimeFactor: 3},
+		{Name: string(constants.LanguageC), CompileCmd: "gcc -O2 -o {out} {sources}"},
+	})
+	assert.NilError(t, err)
+
+	// 新增的语言不需要编译
+	spec, ok := GetLanguageSpec("python3")
+	assert.Assert(t, ok)
+	assert.Equal(t, false, spec.NeedCompile())
+	assert.Equal(t, float64(3), spec.TimeFactor)
+	assert.Equal(t, float64(1), spec.MemoryFactor)
+	assert.DeepEqual(t, []string{"python3", "/tmp/main.py"}, spec.runCommand("/tmp/main.py"))
+
+	// 覆盖内置语言时，没有配置的字段保留默认值
+	spec, ok = GetLanguageSpec(constants.LanguageC)
+	assert.Assert(t, ok)
+	assert.Equal(t, "gcc -O2 -o {out} {sources}", spec.CompileCmd)
+	assert.Equal(t, "main.c", spec.SourceFile)
+	assert.Equal(t, constants.GdbCore, spec.Debugger)
+
+	// 新增语言必须配置main文件和运行命令
+	err = InitLanguageSpecs([]*config.LanguageConfig{{Name: "rust", CompileCmd: "rustc -o {out} {sources}"}})
+	assert.Assert(t, err != nil)
+}
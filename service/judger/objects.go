@@ -1,6 +1,11 @@
 package judger
 
-import "FanCode/constants"
+import (
+	"FanCode/constants"
+	"bytes"
+	"io"
+	"os"
+)
 
 // ExecuteOptions 执行文件可选操作
 type ExecuteOptions struct {
@@ -8,18 +13,54 @@ type ExecuteOptions struct {
 	LimitTime       int64 // 资源限制
 	MemoryLimit     int64
 	CPUQuota        int64
-	ExcludedPaths   []string // 屏蔽的敏感路径
-	ReplacementPath string   // 取代敏感路径的路径
+	StackLimit      int64           // 栈大小限制（以字节为单位），为0时使用系统默认值
+	PidsLimit       int64           // 最大进程（线程）数
+	OutputLimit     int64           // 标准输出的最大长度（以字节为单位），为0时不限制
+	ExcludedPaths   []string        // 屏蔽的敏感路径
+	ReplacementPath string          // 取代敏感路径的路径
+	Sandbox         *SandboxOptions // 沙箱选项，为nil时不使用沙箱直接运行
+	Seccomp         bool            // 是否根据语言过滤系统调用
+	Args            []string        // 追加到运行命令后面的参数
+}
+
+// CaseInput 打开一个用例的输入，运行用户程序时才打开，程序结束后关闭。
+// 输入为文件时直接作为子进程的标准输入，不需要读入内存
+type CaseInput func() (io.ReadCloser, error)
+
+// BytesInput 内存中的输入
+func BytesInput(data []byte) CaseInput {
+	return func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+}
+
+// FileInput 保存在文件中的输入
+func FileInput(path string) CaseInput {
+	return func() (io.ReadCloser, error) {
+		return os.Open(path)
+	}
 }
 
 // ExecuteResult 程序执行结果
 type ExecuteResult struct {
 	Executed     bool   // 判题是否执行成功
+	Verdict      int    // 执行结果，取值为constants中的判题状态，执行成功为RunSuccess
 	ErrorMessage string // 异常信息
+	ExitCode     int    // 程序退出码，被信号终止时为-1
+	Signal       string // 终止程序的信号名称，比如SIGSEGV
 	Output       []byte // 输出结果（正常输出结果，如果有）
 	UsedTime     int64  // 执行时间（以纳秒为单位）
-	UsedMemory   int64  // 内存使用量（以字节为单位）
-	UsedCpuTime  int64  // cpu使用
+	UsedMemory   int64  // 内存使用峰值（以字节为单位）
+	UsedCpuTime  int64  // cpu使用时间，用户态+内核态（以纳秒为单位）
+	OOMKilled    bool   // 是否因为内存超出限制被kill
+	// 调用的被禁止的系统调用名称，比如fork、socket
+	RestrictedSyscall string
+	// 程序实际输出的长度（以字节为单位），输出超限时大于Output的长度
+	OutputSize int64
+	// 输出是否超出限制被截断，截断时Output只包含前OutputLimit个字节
+	OutputTruncated bool
+	// 标准错误输出，已经屏蔽了敏感路径
+	ErrorOutput string
 }
 
 // CompileOptions 编译文件可选参数
@@ -28,6 +69,22 @@ type CompileOptions struct {
 	LimitTime       int64
 	ExcludedPaths   []string // 屏蔽的敏感路径
 	ReplacementPath string   // 取代敏感路径的路径
+	// 不传给编译器但是会影响编译结果的文件，比如c/c++的头文件，计算编译缓存时使用
+	IncludeFiles []string
+}
+
+// CheckResult 特判程序的检查结果
+type CheckResult struct {
+	Verdict int    // 检查结果，取值为Accepted、WrongAnswer、PresentationError，特判程序出错时为SystemError
+	Message string // 特判程序输出的信息
+}
+
+// InteractResult 交互式判题结果
+type InteractResult struct {
+	Verdict    int           // 判题结果，取值为constants中的判题状态，通过时为Accepted
+	Message    string        // 交互程序输出的信息，或者用户程序的异常信息
+	Execute    ExecuteResult // 用户程序的执行结果
+	Interactor ExecuteResult // 交互程序的执行结果
 }
 
 // CompileResult 系统编译结果
@@ -35,4 +92,5 @@ type CompileResult struct {
 	Compiled         bool   // 判题是否编译成功
 	ErrorMessage     string // 异常信息
 	CompiledFilePath string // 输出文件路径
+	Cached           bool   // 是否使用了缓存的编译结果
 }
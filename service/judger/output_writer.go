@@ -0,0 +1,72 @@
+package judger
+
+import (
+	"bytes"
+	"sync"
+)
+
+// 标准错误输出的最大保存长度，超过部分直接丢弃，不影响判题结果
+const stderrLimit = 64 * 1024
+
+// limitedBuffer 有长度限制的输出缓冲区，超出限制时只保存前limit个字节，
+// 并调用onOverflow，用于在输出超限时及时终止程序
+type limitedBuffer struct {
+	mu         sync.Mutex
+	buf        bytes.Buffer
+	limit      int64 // 最大长度，为0时不限制
+	size       int64 // 程序实际输出的长度
+	onOverflow func()
+	overflowed bool
+}
+
+func newLimitedBuffer(limit int64, onOverflow func()) *limitedBuffer {
+	return &limitedBuffer{
+		limit:      limit,
+		onOverflow: onOverflow,
+	}
+}
+
+// Write 超出限制时不返回错误，继续读取管道中的数据，避免程序阻塞在写操作上
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.size += int64(len(p))
+	if b.limit == 0 {
+		return b.buf.Write(p)
+	}
+	if remain := b.limit - int64(b.buf.Len()); remain > 0 {
+		if int64(len(p)) > remain {
+			b.buf.Write(p[:remain])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	if b.size > b.limit && !b.overflowed {
+		b.overflowed = true
+		if b.onOverflow != nil {
+			b.onOverflow()
+		}
+	}
+	return len(p), nil
+}
+
+// Bytes 保存的输出
+func (b *limitedBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Bytes()
+}
+
+// Size 程序实际输出的长度
+func (b *limitedBuffer) Size() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.size
+}
+
+// Overflowed 输出是否超出限制
+func (b *limitedBuffer) Overflowed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.overflowed
+}
@@ -0,0 +1,483 @@
+package judger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang.org/x/sys/unix"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+)
+
+const (
+	// 沙箱初始化进程的名称，判题进程通过/proc/self/exe以该名称重新执行自身
+	sandboxInitName = "fancode-sandbox-init"
+	// 沙箱中的root用户在宿主机上对应nobody用户
+	sandboxDefaultID = 65534
+	// 沙箱的新根目录挂载点，挂载tmpfs后原目录会被覆盖，所以需要提前打开要挂载的路径
+	sandboxRootMountPoint = "/tmp"
+	// 用户程序的工作目录，是沙箱中私有的tmpfs
+	sandboxWorkDir = "/tmp"
+	// 传递配置和返回初始化错误的文件描述符，对应ExtraFiles
+	sandboxConfigFd = 3
+	sandboxErrorFd  = 4
+)
+
+var (
+	// 默认只读挂载的目录，只包含运行程序所需要的动态库、解释器和jvm
+	sandboxDefaultBinds = []string{
+		"/bin", "/lib", "/lib32", "/lib64", "/usr",
+		"/etc/alternatives", "/etc/ld.so.cache", "/etc/java-*",
+	}
+	// 挂载到沙箱中的设备
+	sandboxDevices = []string{"/dev/null", "/dev/zero", "/dev/random", "/dev/urandom"}
+	// 用户程序的默认环境变量，不继承判题服务的环境变量
+	sandboxDefaultEnv = sandboxEnv(sandboxWorkDir)
+)
+
+// sandboxEnv 用户程序固定的最小环境变量，home为用户程序的工作目录
+func sandboxEnv(home string) []string {
+	return []string{
+		"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
+		"HOME=" + home,
+		"LANG=C.UTF-8",
+	}
+}
+
+// SandboxOptions 沙箱选项，程序会运行在新的user/mount/pid/net/ipc/uts命名空间中，
+// 根目录只包含只读挂载的系统目录和可执行文件所在目录，工作目录是私有的tmpfs
+type SandboxOptions struct {
+	ReadOnlyBinds []string // 只读挂载到沙箱中的宿主机路径，支持通配符
+	UID           int      // 沙箱中的用户在宿主机上对应的用户id
+	GID           int      // 沙箱中的用户组在宿主机上对应的用户组id
+	TmpfsSize     int64    // 工作目录大小（以字节为单位）
+	Env           []string // 用户程序的环境变量
+}
+
+// DefaultSandboxOptions 默认沙箱选项
+func DefaultSandboxOptions() *SandboxOptions {
+	return &SandboxOptions{
+		ReadOnlyBinds: sandboxDefaultBinds,
+		UID:           sandboxDefaultID,
+		GID:           sandboxDefaultID,
+		TmpfsSize:     16 * 1024 * 1024,
+		Env:           sandboxDefaultEnv,
+	}
+}
+
+// sandboxConfig 传递给沙箱初始化进程的配置
+type sandboxConfig struct {
+	Path      string   `json:"path"`
+	Args      []string `json:"args"`
+	Env       []string `json:"env"`
+	Binds     []string `json:"binds"`
+	ExecDir   string   `json:"execDir"`
+	Isolated  bool     `json:"isolated"`
+	Seccomp   []string `json:"seccomp"`
+	TmpfsSize int64    `json:"tmpfsSize"`
+	// 栈大小限制，为0时不修改
+	StackLimit int64 `json:"stackLimit"`
+}
+
+// sandboxCmd 父进程中沙箱相关的管道
+type sandboxCmd struct {
+	config        *sandboxConfig
+	configReader  *os.File
+	configWriter  *os.File
+	errReader     *os.File
+	errWriter     *os.File
+	seccompReader *os.File
+	seccompWriter *os.File
+	seccomp       *seccompMonitor
+}
+
+func init() {
+	// 沙箱初始化进程，完成初始化后执行用户程序，不会返回
+	if len(os.Args) > 0 && os.Args[0] == sandboxInitName {
+		runSandboxInit()
+	}
+}
+
+// newSandboxCommand 创建一个通过沙箱初始化进程运行cmdName的命令，execDir为可执行文件所在目录。
+// options为nil时不创建命名空间，初始化进程只负责等待加入cgroup后再执行用户程序。
+// seccomp为允许的系统调用列表，不为空时在执行用户程序前安装系统调用过滤，stackLimit不为0时设置用户程序的栈大小限制
+func newSandboxCommand(ctx context.Context, execDir string, cmdName string, cmdArg []string,
+	options *SandboxOptions, seccomp []string, stackLimit int64) (*exec.Cmd, *sandboxCmd, error) {
+	s := &sandboxCmd{
+		config: &sandboxConfig{
+			Path:       cmdName,
+			Args:       append([]string{cmdName}, cmdArg...),
+			Env:        sandboxEnv(execDir),
+			ExecDir:    execDir,
+			Seccomp:    seccomp,
+			StackLimit: stackLimit,
+		},
+	}
+	sysProcAttr := &syscall.SysProcAttr{
+		Setpgid:   true,
+		Pdeathsig: syscall.SIGKILL,
+	}
+	if options == nil {
+		// 不创建命名空间时解释器等可能不在固定的PATH中，使用判题服务的PATH查找
+		if path, err := exec.LookPath(cmdName); err == nil {
+			s.config.Path = path
+		}
+	} else {
+		s.config.Isolated = true
+		s.config.TmpfsSize = options.TmpfsSize
+		s.config.Env = options.Env
+		if len(s.config.Env) == 0 {
+			s.config.Env = sandboxDefaultEnv
+		}
+		binds := options.ReadOnlyBinds
+		if len(binds) == 0 {
+			binds = sandboxDefaultBinds
+		}
+		for _, bind := range binds {
+			matches, _ := filepath.Glob(bind)
+			s.config.Binds = append(s.config.Binds, matches...)
+		}
+		// 非root用户只能映射自己
+		uid, gid := os.Getuid(), os.Getgid()
+		if uid == 0 {
+			uid, gid = options.UID, options.GID
+		}
+		sysProcAttr.Cloneflags = syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWPID |
+			syscall.CLONE_NEWNET | syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS
+		// 切换为命名空间中的root用户，exec后才拥有命名空间中的能力
+		sysProcAttr.Credential = &syscall.Credential{Uid: 0, Gid: 0, NoSetGroups: true}
+		sysProcAttr.UidMappings = []syscall.SysProcIDMap{{ContainerID: 0, HostID: uid, Size: 1}}
+		sysProcAttr.GidMappings = []syscall.SysProcIDMap{{ContainerID: 0, HostID: gid, Size: 1}}
+	}
+
+	var err error
+	if s.configReader, s.configWriter, err = os.Pipe(); err != nil {
+		return nil, nil, err
+	}
+	if s.errReader, s.errWriter, err = os.Pipe(); err != nil {
+		s.close()
+		return nil, nil, err
+	}
+	cmd := exec.CommandContext(ctx, "/proc/self/exe")
+	cmd.Args = []string{sandboxInitName}
+	cmd.Env = []string{}
+	cmd.ExtraFiles = []*os.File{s.configReader, s.errWriter}
+	if len(seccomp) != 0 {
+		fds, err := unix.Socketpair(unix.AF_UNIX, unix.SOCK_SEQPACKET|unix.SOCK_CLOEXEC, 0)
+		if err != nil {
+			s.close()
+			return nil, nil, err
+		}
+		s.seccompReader = os.NewFile(uintptr(fds[0]), "seccomp")
+		s.seccompWriter = os.NewFile(uintptr(fds[1]), "seccomp")
+		s.seccomp = newSeccompMonitor(s.seccompReader)
+		cmd.ExtraFiles = append(cmd.ExtraFiles, s.seccompWriter)
+	}
+	cmd.SysProcAttr = sysProcAttr
+	return cmd, s, nil
+}
+
+// started 子进程启动后关闭父进程中子进程使用的管道端，并开始处理被过滤的系统调用
+func (s *sandboxCmd) started(pid int) {
+	s.configReader.Close()
+	s.errWriter.Close()
+	if s.seccomp != nil {
+		s.seccompWriter.Close()
+		s.seccomp.start(pid)
+	}
+}
+
+// run 发送配置，沙箱初始化进程收到配置后才会执行用户程序，保证用户程序启动前已经加入cgroup
+func (s *sandboxCmd) run() error {
+	defer s.configWriter.Close()
+	data, err := json.Marshal(s.config)
+	if err != nil {
+		return err
+	}
+	_, err = s.configWriter.Write(data)
+	return err
+}
+
+// wait 读取沙箱初始化错误，用户程序执行后管道会被自动关闭
+func (s *sandboxCmd) wait() error {
+	defer s.errReader.Close()
+	data, err := io.ReadAll(s.errReader)
+	if err != nil {
+		return err
+	}
+	if len(data) != 0 {
+		return fmt.Errorf("sandbox: %s", string(data))
+	}
+	return nil
+}
+
+// restrictedSyscall 用户程序调用的被禁止的系统调用，需要在进程退出后调用
+func (s *sandboxCmd) restrictedSyscall() string {
+	if s.seccomp == nil {
+		return ""
+	}
+	return s.seccomp.stop()
+}
+
+func (s *sandboxCmd) close() {
+	for _, f := range []*os.File{s.configReader, s.configWriter, s.errReader, s.errWriter,
+		s.seccompReader, s.seccompWriter} {
+		if f != nil {
+			f.Close()
+		}
+	}
+}
+
+// runSandboxInit 沙箱初始化进程的入口，开启隔离时在新的命名空间中以pid 1运行
+func runSandboxInit() {
+	// 能力和no_new_privs都是线程级别的，需要在同一个线程中执行exec
+	runtime.LockOSThread()
+	errPipe := os.NewFile(sandboxErrorFd, "error")
+	syscall.CloseOnExec(sandboxErrorFd)
+	err := sandboxInit()
+	fmt.Fprint(errPipe, err.Error())
+	os.Exit(1)
+}
+
+// sandboxInit 初始化沙箱并执行用户程序，成功时不会返回
+func sandboxInit() error {
+	configFile := os.NewFile(sandboxConfigFd, "config")
+	data, err := io.ReadAll(configFile)
+	configFile.Close()
+	if err != nil {
+		return err
+	}
+	// 父进程没有发送配置就关闭了管道
+	if len(data) == 0 {
+		return errors.New("no sandbox config")
+	}
+	config := &sandboxConfig{}
+	if err = json.Unmarshal(data, config); err != nil {
+		return err
+	}
+	if config.Isolated {
+		if err = setupSandboxRootfs(config); err != nil {
+			return err
+		}
+		if err = unix.Sethostname([]byte("sandbox")); err != nil {
+			return err
+		}
+		if err = dropCapabilities(); err != nil {
+			return err
+		}
+	}
+	execPath, err := lookPathInEnv(config.Path, config.Env)
+	if err != nil {
+		return err
+	}
+	// 栈大小限制在exec后保留
+	if config.StackLimit > 0 {
+		limit := &unix.Rlimit{Cur: uint64(config.StackLimit), Max: uint64(config.StackLimit)}
+		if err = unix.Setrlimit(unix.RLIMIT_STACK, limit); err != nil {
+			return fmt.Errorf("setrlimit stack: %w", err)
+		}
+	}
+	// 安装过滤程序后只能进行少量系统调用，所以放在exec之前最后执行
+	if len(config.Seccomp) != 0 {
+		syscall.CloseOnExec(sandboxSeccompFd)
+		if err = installSeccompFilter(config.Seccomp); err != nil {
+			return err
+		}
+	}
+	if err = unix.Exec(execPath, config.Args, config.Env); err != nil {
+		return fmt.Errorf("exec %s: %w", execPath, err)
+	}
+	return nil
+}
+
+// sandboxBind 需要挂载到沙箱中的路径
+type sandboxBind struct {
+	target   string
+	fd       int
+	isDir    bool
+	link     string // 软链接在沙箱中重新创建
+	readOnly bool
+}
+
+// setupSandboxRootfs 构建沙箱的根目录并切换过去
+func setupSandboxRootfs(config *sandboxConfig) error {
+	// 挂载事件不传播到宿主机
+	if err := unix.Mount("", "/", "", unix.MS_REC|unix.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("make / private: %w", err)
+	}
+
+	// 在挂载tmpfs之前打开所有要挂载的路径，避免路径被tmpfs覆盖
+	var binds []*sandboxBind
+	defer func() {
+		for _, b := range binds {
+			if b.fd > 0 {
+				unix.Close(b.fd)
+			}
+		}
+	}()
+	add := func(p string, readOnly bool) error {
+		info, err := os.Lstat(p)
+		if err != nil {
+			return fmt.Errorf("stat %s: %w", p, err)
+		}
+		b := &sandboxBind{target: p, isDir: info.IsDir(), readOnly: readOnly}
+		if info.Mode()&os.ModeSymlink != 0 {
+			if b.link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		} else if b.fd, err = unix.Open(p, unix.O_PATH|unix.O_CLOEXEC, 0); err != nil {
+			return fmt.Errorf("open %s: %w", p, err)
+		}
+		binds = append(binds, b)
+		return nil
+	}
+	for _, p := range config.Binds {
+		if err := add(p, true); err != nil {
+			return err
+		}
+	}
+	for _, p := range sandboxDevices {
+		if err := add(p, false); err != nil {
+			return err
+		}
+	}
+	if err := add(config.ExecDir, true); err != nil {
+		return err
+	}
+
+	// 新的根目录
+	root := sandboxRootMountPoint
+	if err := unix.Mount("tmpfs", root, "tmpfs", unix.MS_NOSUID|unix.MS_NODEV, "size=1m,mode=755"); err != nil {
+		return fmt.Errorf("mount rootfs: %w", err)
+	}
+	// 私有的工作目录，先于其他路径挂载，避免覆盖/tmp下的可执行文件目录
+	workDir := path.Join(root, sandboxWorkDir)
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		return err
+	}
+	if err := unix.Mount("tmpfs", workDir, "tmpfs", unix.MS_NOSUID|unix.MS_NODEV,
+		fmt.Sprintf("size=%d,mode=777", config.TmpfsSize)); err != nil {
+		return fmt.Errorf("mount workdir: %w", err)
+	}
+	for _, b := range binds {
+		if err := mountSandboxBind(root, b); err != nil {
+			return err
+		}
+	}
+	// 新的pid命名空间的proc，部分容器环境不允许挂载proc，忽略错误
+	procDir := path.Join(root, "proc")
+	if err := os.MkdirAll(procDir, 0755); err != nil {
+		return err
+	}
+	_ = unix.Mount("proc", procDir, "proc", unix.MS_NOSUID|unix.MS_NODEV|unix.MS_NOEXEC, "")
+
+	// 切换根目录，并卸载原来的根目录
+	if err := os.Chdir(root); err != nil {
+		return err
+	}
+	if err := unix.PivotRoot(".", "."); err != nil {
+		return fmt.Errorf("pivot root: %w", err)
+	}
+	if err := unix.Unmount(".", unix.MNT_DETACH); err != nil {
+		return fmt.Errorf("unmount old root: %w", err)
+	}
+	// 根目录设置为只读
+	if err := unix.Mount("", "/", "", unix.MS_REMOUNT|unix.MS_BIND|unix.MS_RDONLY|unix.MS_NOSUID|unix.MS_NODEV, ""); err != nil {
+		return fmt.Errorf("remount rootfs: %w", err)
+	}
+	return os.Chdir(sandboxWorkDir)
+}
+
+// mountSandboxBind 把宿主机上的路径挂载到沙箱根目录下的相同位置
+func mountSandboxBind(root string, b *sandboxBind) error {
+	target := path.Join(root, b.target)
+	if err := os.MkdirAll(path.Dir(target), 0755); err != nil {
+		return err
+	}
+	if b.link != "" {
+		return os.Symlink(b.link, target)
+	}
+	if b.isDir {
+		if err := os.MkdirAll(target, 0755); err != nil {
+			return err
+		}
+	} else {
+		f, err := os.Create(target)
+		if err != nil {
+			return err
+		}
+		f.Close()
+	}
+	source := fmt.Sprintf("/proc/self/fd/%d", b.fd)
+	if err := unix.Mount(source, target, "", unix.MS_BIND|unix.MS_REC, ""); err != nil {
+		return fmt.Errorf("bind %s: %w", b.target, err)
+	}
+	if !b.readOnly {
+		return nil
+	}
+	// 重新挂载为只读时，必须保留原挂载点上被锁定的标志
+	var st unix.Statfs_t
+	if err := unix.Fstatfs(b.fd, &st); err != nil {
+		return err
+	}
+	flags := uintptr(unix.MS_REMOUNT | unix.MS_BIND | unix.MS_RDONLY | unix.MS_NOSUID)
+	for stFlag, msFlag := range map[int64]uintptr{
+		unix.ST_NODEV:    unix.MS_NODEV,
+		unix.ST_NOEXEC:   unix.MS_NOEXEC,
+		unix.ST_NOATIME:  unix.MS_NOATIME,
+		unix.ST_RELATIME: unix.MS_RELATIME,
+	} {
+		if int64(st.Flags)&stFlag != 0 {
+			flags |= msFlag
+		}
+	}
+	if err := unix.Mount("", target, "", flags, ""); err != nil {
+		return fmt.Errorf("remount %s: %w", b.target, err)
+	}
+	return nil
+}
+
+// dropCapabilities 清空能力集合并设置no_new_privs，exec之后用户程序没有任何能力
+func dropCapabilities() error {
+	for c := 0; c <= unix.CAP_LAST_CAP; c++ {
+		// 内核不支持的能力会返回EINVAL
+		if err := unix.Prctl(unix.PR_CAPBSET_DROP, uintptr(c), 0, 0, 0); err != nil && !errors.Is(err, unix.EINVAL) {
+			return fmt.Errorf("drop capability %d: %w", c, err)
+		}
+	}
+	if err := unix.Prctl(unix.PR_CAP_AMBIENT, unix.PR_CAP_AMBIENT_CLEAR_ALL, 0, 0, 0); err != nil && !errors.Is(err, unix.EINVAL) {
+		return err
+	}
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return err
+	}
+	header := unix.CapUserHeader{Version: unix.LINUX_CAPABILITY_VERSION_3}
+	data := [2]unix.CapUserData{}
+	return unix.Capset(&header, &data[0])
+}
+
+// lookPathInEnv 使用env中的PATH查找可执行文件
+func lookPathInEnv(file string, env []string) (string, error) {
+	if strings.Contains(file, "/") {
+		return file, nil
+	}
+	for _, e := range env {
+		if !strings.HasPrefix(e, "PATH=") {
+			continue
+		}
+		for _, dir := range filepath.SplitList(strings.TrimPrefix(e, "PATH=")) {
+			p := filepath.Join(dir, file)
+			if info, err := os.Stat(p); err == nil && !info.IsDir() && info.Mode()&0111 != 0 {
+				return p, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%s: executable file not found in $PATH", file)
+}
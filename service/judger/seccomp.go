@@ -0,0 +1,341 @@
+package judger
+
+import (
+	"errors"
+	"fmt"
+	"golang.org/x/sys/unix"
+	"log"
+	"os"
+	"runtime"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	// 传递seccomp监听描述符的socket，对应ExtraFiles
+	sandboxSeccompFd = 5
+	// 轮询监听描述符的超时时间（毫秒）
+	seccompPollTimeout = 100
+	// seccomp_data中各个字段的偏移
+	seccompDataNrOffset   = 0
+	seccompDataArchOffset = 4
+	seccompDataArgsOffset = 16
+)
+
+// 系统调用过滤规则名称，在LanguageSpec中配置
+const (
+	seccompProfileNative = "native" // c、c++等编译为本地代码的语言
+	seccompProfileGo     = "go"
+	seccompProfileJVM    = "jvm"
+	seccompProfilePython = "python"
+	seccompProfileNode   = "node"
+)
+
+var (
+	// seccompBaseSyscalls 所有语言都允许的系统调用，包括动态链接、内存分配、标准输入输出和线程同步
+	seccompBaseSyscalls = []string{
+		"read", "write", "readv", "writev", "pread64", "pwrite64", "lseek", "close",
+		"open", "openat", "stat", "fstat", "lstat", "newfstatat", "statx", "access", "faccessat", "faccessat2",
+		"readlink", "readlinkat", "getcwd", "fcntl", "ioctl", "dup", "dup2", "dup3",
+		"brk", "mmap", "munmap", "mprotect", "mremap", "madvise",
+		"arch_prctl", "set_tid_address", "set_robust_list", "get_robust_list", "rseq", "prlimit64", "getrlimit",
+		"futex", "exit", "exit_group", "rt_sigaction", "rt_sigprocmask", "rt_sigreturn", "sigaltstack",
+		"clock_gettime", "clock_getres", "clock_nanosleep", "nanosleep", "gettimeofday", "time",
+		"getpid", "gettid", "getppid", "getuid", "geteuid", "getgid", "getegid", "uname", "getrandom",
+		"sched_yield", "sched_getaffinity", "tgkill", "tkill", "getrusage", "times",
+	}
+	// seccompGoSyscalls go运行时额外需要的系统调用
+	seccompGoSyscalls = []string{
+		"getdents64", "epoll_create1", "epoll_ctl", "epoll_pwait", "epoll_wait", "eventfd2", "pipe2",
+		"setitimer", "timer_create", "timer_settime", "timer_delete", "mincore",
+	}
+	// seccompJVMSyscalls jvm额外需要的系统调用，jvm会在/tmp下创建性能数据文件
+	seccompJVMSyscalls = []string{
+		"getdents64", "sysinfo", "statfs", "fstatfs", "mkdir", "mkdirat", "unlink", "unlinkat", "rename",
+		"ftruncate", "fallocate", "fchmod", "fsync", "flock", "msync", "mincore", "membarrier", "prctl",
+		"sched_getparam", "sched_getscheduler", "getpriority", "setpriority", "kill", "poll", "ppoll",
+		"pipe", "pipe2", "eventfd2", "epoll_create1", "epoll_ctl", "epoll_wait", "epoll_pwait",
+		"clock_getres", "set_mempolicy", "get_mempolicy", "mbind",
+	}
+	// seccompPythonSyscalls python解释器额外需要的系统调用，启动时会遍历模块目录
+	seccompPythonSyscalls = []string{
+		"getdents64", "sysinfo", "pipe2",
+	}
+	// seccompNodeSyscalls node额外需要的系统调用，libuv使用线程池和epoll处理io
+	seccompNodeSyscalls = []string{
+		"getdents64", "epoll_create1", "epoll_ctl", "epoll_pwait", "epoll_wait", "eventfd2", "pipe2",
+		"capget", "prctl", "mincore", "membarrier", "sysinfo", "statfs", "fstatfs", "sched_getparam",
+		"sched_getscheduler", "poll", "ppoll", "pkey_alloc", "pkey_free", "pkey_mprotect",
+	}
+	// seccompProfiles 各个过滤规则允许的系统调用，不在其中的系统调用会被判定为调用受限函数
+	seccompProfiles = map[string][]string{
+		seccompProfileNative: seccompBaseSyscalls,
+		seccompProfileGo:     append(append([]string{}, seccompBaseSyscalls...), seccompGoSyscalls...),
+		seccompProfileJVM:    append(append([]string{}, seccompBaseSyscalls...), seccompJVMSyscalls...),
+		seccompProfilePython: append(append([]string{}, seccompBaseSyscalls...), seccompPythonSyscalls...),
+		seccompProfileNode:   append(append([]string{}, seccompBaseSyscalls...), seccompNodeSyscalls...),
+	}
+	syscallNumbers     map[string]uint32
+	syscallNumbersOnce sync.Once
+	// 不支持系统调用过滤的架构只警告一次
+	seccompUnsupportedOnce sync.Once
+)
+
+// seccompData 对应内核中的struct seccomp_data
+type seccompData struct {
+	Nr                 int32
+	Arch               uint32
+	InstructionPointer uint64
+	Args               [6]uint64
+}
+
+// seccompNotif 对应内核中的struct seccomp_notif
+type seccompNotif struct {
+	ID    uint64
+	Pid   uint32
+	Flags uint32
+	Data  seccompData
+}
+
+// seccompNotifResp 对应内核中的struct seccomp_notif_resp
+type seccompNotifResp struct {
+	ID    uint64
+	Val   int64
+	Error int32
+	Flags uint32
+}
+
+// getSyscallNumber 根据名称获取当前架构的系统调用号
+func getSyscallNumber(name string) (uint32, bool) {
+	syscallNumbersOnce.Do(func() {
+		syscallNumbers = make(map[string]uint32, len(syscallNames))
+		for nr, n := range syscallNames {
+			syscallNumbers[n] = nr
+		}
+	})
+	nr, ok := syscallNumbers[name]
+	return nr, ok
+}
+
+// getSyscallName 获取系统调用的名称，未知的系统调用返回调用号
+func getSyscallName(nr uint32) string {
+	if name, ok := syscallNames[nr]; ok {
+		return name
+	}
+	return fmt.Sprintf("syscall_%d", nr)
+}
+
+// SeccompSupported 当前架构是否支持系统调用过滤，目前只有amd64有系统调用表
+func SeccompSupported() bool {
+	return len(syscallNames) != 0
+}
+
+// warnSeccompUnsupported 配置开启了系统调用过滤但是当前架构不支持时，记录警告并且不过滤系统调用
+func warnSeccompUnsupported() {
+	seccompUnsupportedOnce.Do(func() {
+		log.Printf("seccomp is not supported on %s, user programs run without syscall filtering\n", runtime.GOARCH)
+	})
+}
+
+// GetSeccompProfile 获取过滤规则允许的系统调用列表
+func GetSeccompProfile(name string) ([]string, error) {
+	if len(syscallNames) == 0 {
+		return nil, errors.New("seccomp is not supported on this architecture")
+	}
+	profile, ok := seccompProfiles[name]
+	if !ok {
+		return nil, fmt.Errorf("no seccomp profile named %s", name)
+	}
+	return profile, nil
+}
+
+// buildSeccompFilter 生成bpf过滤程序，允许列表中的系统调用直接放行，
+// clone只允许创建线程，其他系统调用交给父进程处理
+func buildSeccompFilter(allow []string) []unix.SockFilter {
+	stmt := func(code uint16, k uint32) unix.SockFilter {
+		return unix.SockFilter{Code: code, K: k}
+	}
+	jump := func(code uint16, k uint32, jt uint8, jf uint8) unix.SockFilter {
+		return unix.SockFilter{Code: code, Jt: jt, Jf: jf, K: k}
+	}
+	allowRet := stmt(unix.BPF_RET|unix.BPF_K, unix.SECCOMP_RET_ALLOW)
+	notifyRet := stmt(unix.BPF_RET|unix.BPF_K, unix.SECCOMP_RET_USER_NOTIF)
+	killRet := stmt(unix.BPF_RET|unix.BPF_K, unix.SECCOMP_RET_KILL_PROCESS)
+
+	filter := []unix.SockFilter{
+		// 检查架构，防止通过其他ABI绕过过滤
+		stmt(unix.BPF_LD|unix.BPF_W|unix.BPF_ABS, seccompDataArchOffset),
+		jump(unix.BPF_JMP|unix.BPF_JEQ|unix.BPF_K, seccompAuditArch, 1, 0),
+		killRet,
+		stmt(unix.BPF_LD|unix.BPF_W|unix.BPF_ABS, seccompDataNrOffset),
+		jump(unix.BPF_JMP|unix.BPF_JGE|unix.BPF_K, seccompSyscallLimit, 0, 1),
+		killRet,
+	}
+	// clone3的参数在用户内存中无法检查，返回ENOSYS让libc退化为clone
+	if nr, ok := getSyscallNumber("clone3"); ok {
+		filter = append(filter,
+			jump(unix.BPF_JMP|unix.BPF_JEQ|unix.BPF_K, nr, 0, 1),
+			stmt(unix.BPF_RET|unix.BPF_K, unix.SECCOMP_RET_ERRNO|uint32(unix.ENOSYS)))
+	}
+	if nr, ok := getSyscallNumber("clone"); ok {
+		filter = append(filter,
+			jump(unix.BPF_JMP|unix.BPF_JEQ|unix.BPF_K, nr, 0, 4),
+			stmt(unix.BPF_LD|unix.BPF_W|unix.BPF_ABS, seccompDataArgsOffset),
+			jump(unix.BPF_JMP|unix.BPF_JSET|unix.BPF_K, unix.CLONE_THREAD, 0, 1),
+			allowRet,
+			notifyRet)
+	}
+	for _, name := range allow {
+		nr, ok := getSyscallNumber(name)
+		if !ok {
+			continue
+		}
+		filter = append(filter, jump(unix.BPF_JMP|unix.BPF_JEQ|unix.BPF_K, nr, 0, 1), allowRet)
+	}
+	// 沙箱初始化进程安装过滤程序后需要通过sendmsg发送监听描述符，只允许发送到sandboxSeccompFd。
+	// sandboxSeccompFd在exec时关闭，用户程序调用sendmsg会交给父进程处理
+	if nr, ok := getSyscallNumber("sendmsg"); ok {
+		filter = append(filter,
+			jump(unix.BPF_JMP|unix.BPF_JEQ|unix.BPF_K, nr, 0, 6),
+			stmt(unix.BPF_LD|unix.BPF_W|unix.BPF_ABS, seccompDataArgsOffset),
+			jump(unix.BPF_JMP|unix.BPF_JEQ|unix.BPF_K, sandboxSeccompFd, 0, 3),
+			stmt(unix.BPF_LD|unix.BPF_W|unix.BPF_ABS, seccompDataArgsOffset+4),
+			jump(unix.BPF_JMP|unix.BPF_JEQ|unix.BPF_K, 0, 0, 1),
+			allowRet,
+			notifyRet)
+	}
+	return append(filter, notifyRet)
+}
+
+// installSeccompFilter 在当前线程上安装过滤程序，并把监听描述符发送给父进程
+func installSeccompFilter(allow []string) error {
+	// 未授权的进程安装过滤程序需要设置no_new_privs
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return err
+	}
+	filter := buildSeccompFilter(allow)
+	prog := unix.SockFprog{Len: uint16(len(filter)), Filter: &filter[0]}
+	fd, _, errno := unix.Syscall(unix.SYS_SECCOMP, unix.SECCOMP_SET_MODE_FILTER,
+		unix.SECCOMP_FILTER_FLAG_NEW_LISTENER, uintptr(unsafe.Pointer(&prog)))
+	if errno != 0 {
+		return fmt.Errorf("install seccomp filter: %w", errno)
+	}
+	// 安装之后的系统调用都会被过滤，在父进程收到监听描述符之前只能使用允许列表中的系统调用
+	if err := unix.Sendmsg(sandboxSeccompFd, []byte{0}, unix.UnixRights(int(fd)), nil, 0); err != nil {
+		return fmt.Errorf("send seccomp listener: %w", err)
+	}
+	unix.Close(int(fd))
+	return nil
+}
+
+// seccompMonitor 父进程中处理被过滤的系统调用
+type seccompMonitor struct {
+	socket *os.File // 接收监听描述符的socket
+	pgid   int
+	done   chan struct{}
+	exited chan struct{}
+	// 第一个被禁止的系统调用
+	violation string
+}
+
+func newSeccompMonitor(socket *os.File) *seccompMonitor {
+	return &seccompMonitor{
+		socket: socket,
+		done:   make(chan struct{}),
+		exited: make(chan struct{}),
+	}
+}
+
+// start 开始处理被过滤的系统调用。沙箱初始化进程执行用户程序前的系统调用全部放行，
+// 第一次execve之后，用户程序的系统调用如果不在允许列表中，记录系统调用并杀死进程组
+func (m *seccompMonitor) start(pgid int) {
+	m.pgid = pgid
+	go func() {
+		defer close(m.exited)
+		listener, err := m.receiveListener()
+		if err != nil {
+			return
+		}
+		defer unix.Close(listener)
+		executed := false
+		execveNr, _ := getSyscallNumber("execve")
+		for {
+			select {
+			case <-m.done:
+				return
+			default:
+			}
+			fds := []unix.PollFd{{Fd: int32(listener), Events: unix.POLLIN}}
+			n, err := unix.Poll(fds, seccompPollTimeout)
+			if err != nil && !errors.Is(err, unix.EINTR) {
+				return
+			}
+			if n == 0 {
+				continue
+			}
+			// 所有使用该过滤程序的进程都已经退出
+			if fds[0].Revents&unix.POLLHUP != 0 {
+				return
+			}
+			notif := seccompNotif{}
+			if err = seccompIoctl(listener, unix.SECCOMP_IOCTL_NOTIF_RECV, unsafe.Pointer(&notif)); err != nil {
+				// 进程在通知被读取前退出
+				if errors.Is(err, unix.ENOENT) || errors.Is(err, unix.EINTR) {
+					continue
+				}
+				return
+			}
+			resp := seccompNotifResp{ID: notif.ID}
+			nr := uint32(notif.Data.Nr)
+			if !executed {
+				executed = nr == execveNr
+				resp.Flags = unix.SECCOMP_USER_NOTIF_FLAG_CONTINUE
+			} else {
+				if m.violation == "" {
+					m.violation = getSyscallName(nr)
+				}
+				resp.Error = -int32(unix.EPERM)
+				_ = syscall.Kill(-m.pgid, syscall.SIGKILL)
+			}
+			_ = seccompIoctl(listener, unix.SECCOMP_IOCTL_NOTIF_SEND, unsafe.Pointer(&resp))
+		}
+	}()
+}
+
+// receiveListener 接收沙箱初始化进程发送的监听描述符，初始化进程退出时返回错误
+func (m *seccompMonitor) receiveListener() (int, error) {
+	buf := make([]byte, 1)
+	oob := make([]byte, unix.CmsgSpace(4))
+	_, oobn, _, _, err := unix.Recvmsg(int(m.socket.Fd()), buf, oob, 0)
+	if err != nil {
+		return -1, err
+	}
+	msgs, err := unix.ParseSocketControlMessage(oob[:oobn])
+	if err != nil {
+		return -1, err
+	}
+	if len(msgs) == 0 {
+		return -1, errors.New("no seccomp listener received")
+	}
+	fds, err := unix.ParseUnixRights(&msgs[0])
+	if err != nil {
+		return -1, err
+	}
+	return fds[0], nil
+}
+
+// stop 停止处理，返回被禁止的系统调用名称，没有则返回空字符串
+func (m *seccompMonitor) stop() string {
+	close(m.done)
+	<-m.exited
+	return m.violation
+}
+
+func seccompIoctl(fd int, req uint, arg unsafe.Pointer) error {
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), uintptr(req), uintptr(arg))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build !amd64
+
+package judger
+
+// 目前只支持amd64，其他架构即使配置开启也不过滤系统调用，运行时会在日志中警告
+const (
+	seccompAuditArch    = 0
+	seccompSyscallLimit = 0
+)
+
+var syscallNames = map[uint32]string{}
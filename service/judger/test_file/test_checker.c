@@ -0,0 +1,27 @@
+#include <math.h>
+#include <stdio.h>
+
+// 特判程序：比较浮点数，误差不超过1e-6
+int main(int argc, char *argv[]) {
+    double output, answer;
+    FILE *out = fopen(argv[2], "r");
+    FILE *ans = fopen(argv[3], "r");
+    if (out == NULL || ans == NULL) {
+        fprintf(stderr, "fail cannot open files\n");
+        return 3;
+    }
+    if (fscanf(ans, "%lf", &answer) != 1) {
+        fprintf(stderr, "fail answer is not a number\n");
+        return 3;
+    }
+    if (fscanf(out, "%lf", &output) != 1) {
+        fprintf(stderr, "wrong answer output is not a number\n");
+        return 2;
+    }
+    if (fabs(output - answer) > 1e-6) {
+        fprintf(stderr, "wrong answer expected %.6f, found %.6f\n", answer, output);
+        return 1;
+    }
+    fprintf(stderr, "ok answer is %.6f\n", answer);
+    return 0;
+}
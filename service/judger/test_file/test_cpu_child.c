@@ -0,0 +1,33 @@
+#include <stdio.h>
+#include <time.h>
+#include <unistd.h>
+#include <sys/syscall.h>
+#include <sys/wait.h>
+
+// cpuTime 当前进程使用的cpu时间（毫秒）
+long cpuTime() {
+    struct timespec ts;
+    clock_gettime(CLOCK_PROCESS_CPUTIME_ID, &ts);
+    return ts.tv_sec * 1000 + ts.tv_nsec / 1000000;
+}
+
+int main() {
+    int ms;
+    scanf("%d", &ms);
+    // 子进程在用户态空转
+    pid_t pid = fork();
+    if (pid == 0) {
+        volatile long n = 0;
+        while (cpuTime() < ms) {
+            n++;
+        }
+        return 0;
+    }
+    // 父进程不断进行系统调用，主要占用内核态时间
+    while (cpuTime() < ms) {
+        syscall(SYS_getppid);
+    }
+    waitpid(pid, NULL, 0);
+    printf("done\n");
+    return 0;
+}
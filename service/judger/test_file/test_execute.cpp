@@ -0,0 +1,9 @@
+#include <iostream>
+
+int main() {
+    int num1, num2;
+    std::cin >> num1 >> num2;
+    std::cout << num1 + num2 << std::endl;
+
+    return 0;
+}
@@ -0,0 +1,23 @@
+#include <stdio.h>
+
+// 二分猜数字
+int main() {
+    int n;
+    char result[2];
+    scanf("%d", &n);
+    int left = 1, right = n;
+    while (left <= right) {
+        int mid = (left + right) / 2;
+        printf("%d\n", mid);
+        fflush(stdout);
+        if (scanf("%1s", result) != 1 || result[0] == '=') {
+            break;
+        }
+        if (result[0] == '<') {
+            left = mid + 1;
+        } else {
+            right = mid - 1;
+        }
+    }
+    return 0;
+}
@@ -0,0 +1,29 @@
+#include <stdio.h>
+
+// 交互程序：猜数字，输入文件中依次为范围n、答案和最多猜测次数
+int main(int argc, char *argv[]) {
+    int n, secret, limit, guess;
+    FILE *in = fopen(argv[1], "r");
+    if (in == NULL || fscanf(in, "%d%d%d", &n, &secret, &limit) != 3) {
+        fprintf(stderr, "fail cannot read input\n");
+        return 3;
+    }
+    printf("%d\n", n);
+    fflush(stdout);
+    for (int i = 1; i <= limit; i++) {
+        if (scanf("%d", &guess) != 1) {
+            fprintf(stderr, "wrong answer guess %d is not a number\n", i);
+            return 1;
+        }
+        if (guess == secret) {
+            printf("=\n");
+            fflush(stdout);
+            fprintf(stderr, "ok found %d in %d guesses\n", secret, i);
+            return 0;
+        }
+        printf(guess < secret ? "<\n" : ">\n");
+        fflush(stdout);
+    }
+    fprintf(stderr, "wrong answer too many guesses\n");
+    return 1;
+}
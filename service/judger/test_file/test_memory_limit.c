@@ -1,5 +1,6 @@
 #include <stdio.h>
 #include <stdlib.h>
+#include <string.h>
 
 int main() {
     int num1, num2, sum;
@@ -11,6 +12,7 @@ int main() {
         printf("内存申请失败\n");
         return 1;
     }
+    memset(memory, 1, size);  // 使用内存，只申请不使用不会占用物理内存
     free(memory);  // 释放内存
     sum = num1 + num2;
     printf("%d\n",sum);
@@ -0,0 +1,17 @@
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+int main() {
+    int mb;
+    scanf("%d", &mb);
+    size_t size = (size_t)mb * 1024 * 1024;
+    char* memory = malloc(size);
+    if (memory == NULL) {
+        return 1;
+    }
+    memset(memory, 1, size);  // 使用内存，只申请不使用不会占用物理内存
+    printf("%d\n", memory[size - 1]);
+    free(memory);
+    return 0;
+}
@@ -0,0 +1,9 @@
+#include <stdio.h>
+
+int main() {
+    // 无限输出
+    while (1) {
+        printf("x");
+    }
+    return 0;
+}
@@ -0,0 +1,13 @@
+#include <stdio.h>
+#include <unistd.h>
+
+int main() {
+    int a, b;
+    scanf("%d %d", &a, &b);
+    // 创建子进程，会被系统调用过滤拦截
+    if (fork() == 0) {
+        return 0;
+    }
+    printf("%d\n", a + b);
+    return 0;
+}
@@ -0,0 +1,11 @@
+#include <stdio.h>
+
+int main() {
+    int a, b;
+    scanf("%d", &a);
+    scanf("%d", &b);
+    int *p = NULL;
+    *p = a + b;  // 空指针，触发SIGSEGV
+    printf("%d\n", *p);
+    return 0;
+}
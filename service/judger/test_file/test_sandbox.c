@@ -0,0 +1,11 @@
+#include <stdio.h>
+#include <unistd.h>
+
+int main() {
+    char path[256];
+    scanf("%255s", path);
+    // 宿主机上的文件在沙箱中不可见
+    FILE *f = fopen(path, "r");
+    printf("%d %d\n", f != NULL, getpid());
+    return 0;
+}
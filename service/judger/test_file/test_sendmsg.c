@@ -0,0 +1,18 @@
+#include <stdio.h>
+#include <string.h>
+#include <sys/socket.h>
+
+int main() {
+    int a, b;
+    scanf("%d %d", &a, &b);
+    // 沙箱初始化进程发送监听描述符使用的sendmsg不能被用户程序使用
+    char data[] = "1";
+    struct iovec iov = {data, 1};
+    struct msghdr msg;
+    memset(&msg, 0, sizeof(msg));
+    msg.msg_iov = &iov;
+    msg.msg_iovlen = 1;
+    sendmsg(1, &msg, 0);
+    printf("%d\n", a + b);
+    return 0;
+}
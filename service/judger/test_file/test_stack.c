@@ -0,0 +1,18 @@
+#include <stdio.h>
+
+// 递归深度为n，每层占用约1KB的栈空间
+int depth(int n) {
+    volatile char buffer[1024];
+    buffer[0] = 1;
+    if (n == 0) {
+        return buffer[0];
+    }
+    return depth(n - 1) + buffer[0];
+}
+
+int main() {
+    int n;
+    scanf("%d", &n);
+    printf("%d\n", depth(n));
+    return 0;
+}
@@ -0,0 +1,4 @@
+import sys
+
+nums = list(map(int, sys.stdin.read().split()))
+print(nums[0] // (nums[1] - nums[1]))
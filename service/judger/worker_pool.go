@@ -0,0 +1,186 @@
+package judger
+
+import (
+	"FanCode/utils"
+	"log"
+	"sync"
+	"time"
+)
+
+// WorkerPool 预先创建的cgroup池，执行用户程序时从池中借用，执行结束后重置资源统计再归还，
+// 避免每次执行都创建和删除cgroup，同时限制同时执行的用户程序数量
+type WorkerPool struct {
+	size        int
+	idleTimeout time.Duration
+	slots       chan struct{} // 限制同时借出的worker数量
+	mu          sync.Mutex
+	idle        []*worker // 空闲的worker，最近归还的在最后
+	closed      bool
+	stopCh      chan struct{}
+}
+
+type worker struct {
+	cgroup   *CGroup
+	lastUsed time.Time
+}
+
+// NewWorkerPool 创建worker池并预先创建size个cgroup，
+// 空闲超过idleTimeout的worker会被释放，每隔healthCheckInterval检查一次空闲的worker
+func NewWorkerPool(size int, idleTimeout time.Duration, healthCheckInterval time.Duration) *WorkerPool {
+	p := &WorkerPool{
+		size:        size,
+		idleTimeout: idleTimeout,
+		slots:       make(chan struct{}, size),
+		stopCh:      make(chan struct{}),
+	}
+	now := time.Now()
+	for i := 0; i < size; i++ {
+		w, err := p.newWorker()
+		if err != nil {
+			log.Println(err)
+			break
+		}
+		w.lastUsed = now
+		p.idle = append(p.idle, w)
+	}
+	if healthCheckInterval > 0 {
+		go p.maintain(healthCheckInterval)
+	}
+	return p
+}
+
+// acquire 借用一个worker并设置资源限制，没有空闲的worker时创建新的cgroup，
+// 借出的worker数量达到size时阻塞等待
+func (p *WorkerPool) acquire(options *ExecuteOptions) (*worker, error) {
+	p.slots <- struct{}{}
+	for {
+		w := p.pop()
+		if w == nil {
+			var err error
+			if w, err = p.newWorker(); err != nil {
+				<-p.slots
+				return nil, err
+			}
+		} else if err := w.cgroup.Reset(); err != nil {
+			// 上一次执行残留了进程或者内核不支持重置，丢弃该worker
+			log.Println(err)
+			p.destroy(w)
+			continue
+		}
+		if err := p.setLimits(w.cgroup, options); err != nil {
+			p.destroy(w)
+			<-p.slots
+			return nil, err
+		}
+		return w, nil
+	}
+}
+
+// release 归还worker，池已经关闭或者空闲worker已满时直接释放
+func (p *WorkerPool) release(w *worker) {
+	defer func() { <-p.slots }()
+	w.lastUsed = time.Now()
+	p.mu.Lock()
+	if p.closed || len(p.idle) >= p.size {
+		p.mu.Unlock()
+		p.destroy(w)
+		return
+	}
+	p.idle = append(p.idle, w)
+	p.mu.Unlock()
+}
+
+// Close 关闭worker池，释放所有空闲的cgroup，借出的worker归还时释放
+func (p *WorkerPool) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	idle := p.idle
+	p.idle = nil
+	p.mu.Unlock()
+	close(p.stopCh)
+	for _, w := range idle {
+		p.destroy(w)
+	}
+}
+
+// pop 取出最近归还的空闲worker
+func (p *WorkerPool) pop() *worker {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.idle) == 0 {
+		return nil
+	}
+	w := p.idle[len(p.idle)-1]
+	p.idle = p.idle[:len(p.idle)-1]
+	return w
+}
+
+func (p *WorkerPool) newWorker() (*worker, error) {
+	cgroup, err := NewCGroup(utils.GetUUID())
+	if err != nil {
+		return nil, err
+	}
+	return &worker{cgroup: cgroup}, nil
+}
+
+// setLimits 设置资源限制，为0的限制需要取消上一次执行的设置
+func (p *WorkerPool) setLimits(cgroup *CGroup, options *ExecuteOptions) error {
+	if err := cgroup.SetMemoryLimit(options.MemoryLimit); err != nil {
+		return err
+	}
+	if err := cgroup.SetCPUQuota(options.CPUQuota); err != nil {
+		return err
+	}
+	return cgroup.SetPidsLimit(options.PidsLimit)
+}
+
+func (p *WorkerPool) destroy(w *worker) {
+	if err := w.cgroup.Release(); err != nil {
+		log.Println(err)
+	}
+}
+
+// maintain 定期释放空闲超时的worker，并检查空闲worker是否可用
+func (p *WorkerPool) maintain(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.checkIdle()
+		case <-p.stopCh:
+			return
+		}
+	}
+}
+
+func (p *WorkerPool) checkIdle() {
+	p.mu.Lock()
+	var expired []*worker
+	idle := p.idle[:0]
+	for _, w := range p.idle {
+		if p.idleTimeout > 0 && time.Since(w.lastUsed) > p.idleTimeout {
+			expired = append(expired, w)
+		} else if !p.healthy(w) {
+			log.Printf("worker cgroup %s is unhealthy\n", w.cgroup.containerID)
+			expired = append(expired, w)
+		} else {
+			idle = append(idle, w)
+		}
+	}
+	p.idle = idle
+	p.mu.Unlock()
+	for _, w := range expired {
+		p.destroy(w)
+	}
+}
+
+// healthy cgroup仍然存在并且没有残留的进程
+func (p *WorkerPool) healthy(w *worker) bool {
+	procs, err := w.cgroup.Procs()
+	return err == nil && len(procs) == 0
+}
@@ -0,0 +1,81 @@
+package judger
+
+import (
+	"FanCode/constants"
+	"gotest.tools/v3/assert"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJudgeCore_WorkerPool(t *testing.T) {
+	workerPool := NewWorkerPool(1, time.Minute, 0)
+	defer workerPool.Close()
+	judgeCore := NewPooledJudgeCore(nil, workerPool, 0)
+	assert.Equal(t, 1, len(workerPool.idle))
+	containerID := workerPool.idle[0].cgroup.containerID
+
+	dir := t.TempDir()
+	for _, name := range []string{"test_execute", "test_memory_limit"} {
+		result, err := judgeCore.Compile([]string{"./test_file/" + name + ".c"}, filepath.Join(dir, name),
+			&CompileOptions{LimitTime: int64(10 * time.Second)})
+		assert.NilError(t, err)
+		assert.Equal(t, true, result.Compiled, result.ErrorMessage)
+	}
+
+	// 使用10m内存的程序
+	result := executeOnce(t, judgeCore, filepath.Join(dir, "test_memory_limit"), "1 2", &ExecuteOptions{
+		Language:    constants.LanguageC,
+		LimitTime:   int64(time.Second),
+		MemoryLimit: 64 * 1024 * 1024,
+	})
+	assert.Equal(t, constants.RunSuccess, result.Verdict, result.ErrorMessage)
+
+	// 重复使用cgroup时重置内存峰值，不会因为上一次执行超出内存限制
+	result = executeOnce(t, judgeCore, filepath.Join(dir, "test_execute"), "1 2", &ExecuteOptions{
+		Language:    constants.LanguageC,
+		LimitTime:   int64(time.Second),
+		MemoryLimit: 5 * 1024 * 1024,
+	})
+	assert.Equal(t, constants.RunSuccess, result.Verdict, result.ErrorMessage)
+	assert.Equal(t, "3\n", string(result.Output))
+	assert.Assert(t, result.UsedMemory < 5*1024*1024)
+	assert.Assert(t, result.UsedCpuTime < int64(time.Second))
+
+	// 每次执行都重新设置资源限制
+	result = executeOnce(t, judgeCore, filepath.Join(dir, "test_memory_limit"), "1 2", &ExecuteOptions{
+		Language:    constants.LanguageC,
+		LimitTime:   int64(time.Second),
+		MemoryLimit: 1 * 1024 * 1024,
+	})
+	assert.Equal(t, constants.MemoryLimitExceeded, result.Verdict, result.ErrorMessage)
+	result = executeOnce(t, judgeCore, filepath.Join(dir, "test_memory_limit"), "1 2", &ExecuteOptions{
+		Language:  constants.LanguageC,
+		LimitTime: int64(time.Second),
+	})
+	assert.Equal(t, constants.RunSuccess, result.Verdict, result.ErrorMessage)
+
+	// 始终使用同一个cgroup
+	assert.Equal(t, 1, len(workerPool.idle))
+	assert.Equal(t, containerID, workerPool.idle[0].cgroup.containerID)
+}
+
+func TestWorkerPool_IdleTimeout(t *testing.T) {
+	workerPool := NewWorkerPool(2, 10*time.Millisecond, 0)
+	defer workerPool.Close()
+	assert.Equal(t, 2, len(workerPool.idle))
+
+	// 借出的worker不会因为空闲超时被释放
+	w, err := workerPool.acquire(&ExecuteOptions{})
+	assert.NilError(t, err)
+	time.Sleep(20 * time.Millisecond)
+	workerPool.checkIdle()
+	assert.Equal(t, 0, len(workerPool.idle))
+
+	workerPool.release(w)
+	workerPool.checkIdle()
+	assert.Equal(t, 1, len(workerPool.idle))
+	time.Sleep(20 * time.Millisecond)
+	workerPool.checkIdle()
+	assert.Equal(t, 0, len(workerPool.idle))
+}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	conf "FanCode/config"
+	"FanCode/file_store"
+	"FanCode/models/po"
+	"FanCode/service/judger"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"strings"
+)
+
+// 用例数据在对象存储中的目录，本地缓存保存在题目文件目录下的相同目录中
+const caseDataDir = "case_data"
+
+// caseDataPath 用例数据在对象存储中的路径，相同内容的数据只保存一份
+func caseDataPath(hash string) string {
+	return path.Join(caseDataDir, hash[:2], hash)
+}
+
+// isCaseDataHash 是否为合法的sha256，避免拼接出其他路径
+func isCaseDataHash(hash string) bool {
+	if len(hash) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(hash)
+	return err == nil
+}
+
+// saveCaseData 保存用例数据到对象存储，同时放入本地缓存，返回数据的sha256、长度和开头部分的预览
+func saveCaseData(config *conf.AppConfig, store file_store.Store, data io.Reader) (string, int64, string, error) {
+	dir := path.Join(config.FilePathConfig.ProblemFileDir, caseDataDir)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", 0, "", err
+	}
+	file, err := os.CreateTemp(dir, ".upload")
+	if err != nil {
+		return "", 0, "", err
+	}
+	defer func() {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+	}()
+
+	// 写入临时文件的同时计算sha256
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(file, h), data)
+	if err != nil {
+		return "", 0, "", err
+	}
+	hash := hex.EncodeToString(h.Sum(nil))
+
+	// 多读取一个字节，截断时不会截断utf8字符
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return "", 0, "", err
+	}
+	buf := make([]byte, LimitCasePreview+1)
+	n, err := io.ReadFull(file, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", 0, "", err
+	}
+	preview := truncateOutput(buf[:n], LimitCasePreview)
+
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return "", 0, "", err
+	}
+	if err = store.SaveFile(caseDataPath(hash), file); err != nil {
+		return "", 0, "", err
+	}
+	// 放入本地缓存失败不影响保存，判题时会重新下载
+	localPath := path.Join(config.FilePathConfig.ProblemFileDir, caseDataPath(hash))
+	if err = os.MkdirAll(path.Dir(localPath), os.ModePerm); err == nil {
+		err = os.Rename(file.Name(), localPath)
+	}
+	if err != nil {
+		log.Println(err)
+	}
+	return hash, size, preview, nil
+}
+
+// checkAndDownloadCaseData 本地没有用例数据的缓存时从对象存储下载，返回本地缓存的路径
+func checkAndDownloadCaseData(config *conf.AppConfig, store file_store.Store, hash string) (string, error) {
+	if !isCaseDataHash(hash) {
+		return "", fmt.Errorf("用例数据的sha256不合法：%s", hash)
+	}
+	storePath := caseDataPath(hash)
+	localPath := path.Join(config.FilePathConfig.ProblemFileDir, storePath)
+	err := checkAndDownload(localPath, func(tmpPath string) error {
+		if err := store.DownloadFile(storePath, tmpPath); err != nil {
+			return err
+		}
+		// 校验下载的数据，避免缓存不完整的文件
+		sum, err := hashFile(tmpPath)
+		if err != nil {
+			return err
+		}
+		if sum != hash {
+			return fmt.Errorf("用例数据%s校验失败", hash)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return localPath, nil
+}
+
+// checkAndDownload 本地路径不存在时先下载到同一目录下的临时路径，下载完成后再移动到本地路径，
+// 多个判题同时下载时不会读取到不完整的文件
+func checkAndDownload(localPath string, download func(tmpPath string) error) error {
+	if _, err := os.Stat(localPath); err == nil {
+		return nil
+	}
+	dir := path.Dir(localPath)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	tmpDir, err := os.MkdirTemp(dir, ".download")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	tmpPath := path.Join(tmpDir, path.Base(localPath))
+	if err = download(tmpPath); err != nil {
+		return err
+	}
+	if err = os.Rename(tmpPath, localPath); err != nil {
+		// 其他判题已经下载完成
+		if _, statErr := os.Stat(localPath); statErr == nil {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+func hashFile(name string) (string, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// 提交记录中的用例数据被截断时添加的标记
+const caseDataTruncatedMarker = "\n...（数据过长，已截断）"
+
+// caseData 一个用例的输入和期望输出，保存在对象存储中时为本地缓存的路径，否则使用数据库中的数据
+type caseData struct {
+	*po.ProblemCase
+	inputPath  string
+	outputPath string
+}
+
+// loadCaseData 准备所有用例的数据，保存在对象存储中的数据下载到本地缓存
+func loadCaseData(config *conf.AppConfig, cases []*po.ProblemCase) ([]*caseData, error) {
+	var store file_store.Store
+	download := func(hash string) (string, error) {
+		if hash == "" {
+			return "", nil
+		}
+		if store == nil {
+			store = file_store.NewProblemCOS(config.COSConfig)
+		}
+		return checkAndDownloadCaseData(config, store, hash)
+	}
+	data := make([]*caseData, len(cases))
+	for i, c := range cases {
+		d := &caseData{ProblemCase: c}
+		var err error
+		if d.inputPath, err = download(c.InputHash); err != nil {
+			return nil, err
+		}
+		if d.outputPath, err = download(c.OutputHash); err != nil {
+			return nil, err
+		}
+		data[i] = d
+	}
+	return data, nil
+}
+
+// input 用户程序的输入，保存在文件中时直接作为标准输入
+func (d *caseData) input() judger.CaseInput {
+	if d.inputPath != "" {
+		return judger.FileInput(d.inputPath)
+	}
+	return judger.BytesInput([]byte(d.Input))
+}
+
+// openInput 打开输入，传递给特判程序和交互程序
+func (d *caseData) openInput() (io.ReadSeekCloser, error) {
+	return openCaseData(d.inputPath, d.Input)
+}
+
+// openOutput 打开期望输出
+func (d *caseData) openOutput() (io.ReadSeekCloser, error) {
+	return openCaseData(d.outputPath, d.Output)
+}
+
+// readCaseData 读取保存到提交记录中的用例数据，数据库中的数据在保存到对象存储时只是预览，需要读取完整的数据。
+// 超过LimitSubmissionCaseData时截断并添加截断标记，读取失败时使用预览
+func readCaseData(open func() (io.ReadSeekCloser, error), preview string) string {
+	data, err := open()
+	if err != nil {
+		log.Printf("open case data error: %v\n", err)
+		return preview
+	}
+	defer data.Close()
+	// 多读取一个字节判断是否超过长度限制
+	buf := make([]byte, LimitSubmissionCaseData+1)
+	n, err := io.ReadFull(data, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		log.Printf("read case data error: %v\n", err)
+		return preview
+	}
+	if n <= LimitSubmissionCaseData {
+		return string(buf[:n])
+	}
+	return truncateOutput(buf[:n], LimitSubmissionCaseData) + caseDataTruncatedMarker
+}
+
+func openCaseData(localPath string, data string) (io.ReadSeekCloser, error) {
+	if localPath != "" {
+		return os.Open(localPath)
+	}
+	return nopSeekCloser{strings.NewReader(data)}, nil
+}
+
+type nopSeekCloser struct {
+	io.ReadSeeker
+}
+
+func (nopSeekCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	conf "FanCode/config"
+	"FanCode/models/po"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// localStore 使用本地目录模拟对象存储
+type localStore struct {
+	dir       string
+	downloads int
+}
+
+func (s *localStore) SaveFile(storePath string, file io.Reader) error {
+	name := filepath.Join(s.dir, storePath)
+	if err := os.MkdirAll(filepath.Dir(name), os.ModePerm); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, data, 0644)
+}
+
+func (s *localStore) DownloadFile(storePath, localPath string) error {
+	s.downloads++
+	data, err := os.ReadFile(filepath.Join(s.dir, storePath))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(localPath, data, 0644)
+}
+
+func (s *localStore) DownloadFolder(storePath, localPath string) error {
+	return errors.New("not implemented")
+}
+
+func (s *localStore) DownloadAndCompressFolder(storePath, localPath, zipPath string) error {
+	return errors.New("not implemented")
+}
+
+func (s *localStore) ReadFile(storePath string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(s.dir, storePath))
+}
+
+func (s *localStore) UploadFolder(storePath string, localPath string) {
+}
+
+func (s *localStore) DeleteFolder(storePath string) error {
+	return os.RemoveAll(filepath.Join(s.dir, storePath))
+}
+
+func newCaseDataConfig(t *testing.T) *conf.AppConfig {
+	return &conf.AppConfig{FilePathConfig: &conf.FilePathConfig{ProblemFileDir: t.TempDir()}}
+}
+
+func TestSaveCaseData(t *testing.T) {
+	config := newCaseDataConfig(t)
+	store := &localStore{dir: t.TempDir()}
+	data := strings.Repeat("1 2 3\n", 2000)
+	hash, size, preview, err := saveCaseData(config, store, strings.NewReader(data))
+	assert.Nil(t, err)
+	sum := sha256.Sum256([]byte(data))
+	assert.Equal(t, hex.EncodeToString(sum[:]), hash)
+	assert.Equal(t, int64(len(data)), size)
+	assert.Equal(t, data[:LimitCasePreview], preview)
+
+	// 保存到对象存储，并且已经放入本地缓存
+	saved, err := store.ReadFile(caseDataPath(hash))
+	assert.Nil(t, err)
+	assert.Equal(t, data, string(saved))
+	localPath, err := checkAndDownloadCaseData(config, store, hash)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, store.downloads)
+	cached, err := os.ReadFile(localPath)
+	assert.Nil(t, err)
+	assert.Equal(t, data, string(cached))
+}
+
+func TestCheckAndDownloadCaseData(t *testing.T) {
+	config := newCaseDataConfig(t)
+	store := &localStore{dir: t.TempDir()}
+	hash, _, _, err := saveCaseData(newCaseDataConfig(t), store, strings.NewReader("1 2\n"))
+	assert.Nil(t, err)
+
+	// 本地没有缓存时下载，之后使用缓存
+	for i := 0; i < 2; i++ {
+		localPath, err := checkAndDownloadCaseData(config, store, hash)
+		assert.Nil(t, err)
+		data, err := os.ReadFile(localPath)
+		assert.Nil(t, err)
+		assert.Equal(t, "1 2\n", string(data))
+	}
+	assert.Equal(t, 1, store.downloads)
+
+	// 数据被修改时校验失败，不会放入缓存
+	sum := sha256.Sum256([]byte("3 4\n"))
+	other := hex.EncodeToString(sum[:])
+	assert.Nil(t, store.SaveFile(caseDataPath(other), strings.NewReader("5 6\n")))
+	_, err = checkAndDownloadCaseData(config, store, other)
+	assert.NotNil(t, err)
+	_, err = os.Stat(filepath.Join(config.FilePathConfig.ProblemFileDir, caseDataPath(other)))
+	assert.True(t, os.IsNotExist(err))
+
+	_, err = checkAndDownloadCaseData(config, store, "../../etc/passwd")
+	assert.NotNil(t, err)
+}
+
+func TestLoadCaseData(t *testing.T) {
+	// 没有保存在对象存储中的用例使用数据库中的数据
+	data, err := loadCaseData(newCaseDataConfig(t), []*po.ProblemCase{{Input: "1 2\n", Output: "3\n"}})
+	assert.Nil(t, err)
+	input, err := data[0].input()()
+	assert.Nil(t, err)
+	b, _ := io.ReadAll(input)
+	assert.Equal(t, "1 2\n", string(b))
+	output, err := data[0].openOutput()
+	assert.Nil(t, err)
+	b, _ = io.ReadAll(output)
+	assert.Equal(t, "3\n", string(b))
+}
+
+func TestReadCaseData(t *testing.T) {
+	// 保存在对象存储中的用例读取本地缓存中的完整数据，而不是数据库中的预览
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "input")
+	assert.Nil(t, os.WriteFile(inputPath, []byte("1 2 3 4\n"), 0644))
+	c := &caseData{ProblemCase: &po.ProblemCase{Input: "1 2", Output: "10\n"}, inputPath: inputPath}
+	assert.Equal(t, "1 2 3 4\n", readCaseData(c.openInput, c.Input))
+	assert.Equal(t, "10\n", readCaseData(c.openOutput, c.Output))
+
+	// 超过长度限制时截断并添加截断标记
+	outputPath := filepath.Join(dir, "output")
+	assert.Nil(t, os.WriteFile(outputPath, []byte(strings.Repeat("a", LimitSubmissionCaseData+1)), 0644))
+	c.outputPath = outputPath
+	assert.Equal(t, strings.Repeat("a", LimitSubmissionCaseData)+caseDataTruncatedMarker,
+		readCaseData(c.openOutput, c.Output))
+
+	// 本地缓存读取失败时使用预览
+	c.inputPath = filepath.Join(dir, "missing")
+	assert.Equal(t, "1 2", readCaseData(c.openInput, c.Input))
+}
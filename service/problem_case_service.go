@@ -4,11 +4,13 @@ import (
 	conf "FanCode/config"
 	"FanCode/dao"
 	e "FanCode/error"
+	"FanCode/file_store"
 	"FanCode/global"
 	"FanCode/models/dto"
 	"FanCode/models/po"
 	"errors"
 	"gorm.io/gorm"
+	"io"
 	"log"
 	"strconv"
 	"unicode"
@@ -23,10 +25,10 @@ type ProblemCaseService interface {
 	GetProblemCaseByID(id uint) (*dto.ProblemCaseDtoForGet, *e.Error)
 	// DeleteProblemCaseByID 通过id删除题目用例
 	DeleteProblemCaseByID(id uint) *e.Error
-	// InsertProblemCase 添加题目用例
-	InsertProblemCase(problemCase *po.ProblemCase) (uint, *e.Error)
-	// UpdateProblemCase 更新题目用例
-	UpdateProblemCase(problemCase *po.ProblemCase) *e.Error
+	// InsertProblemCase 添加题目用例，输入和期望输出保存到对象存储中
+	InsertProblemCase(problemCase *po.ProblemCase, input io.Reader, output io.Reader) (uint, *e.Error)
+	// UpdateProblemCase 更新题目用例，子任务、输入或者期望输出为nil时不修改
+	UpdateProblemCase(problemCase *po.ProblemCase, subtask *int, input io.Reader, output io.Reader) *e.Error
 	// CheckProblemCaseName 检测用例名称是否重复
 	CheckProblemCaseName(id uint, name string, problemID uint) (bool, *e.Error)
 	// GenerateNewProblemCaseName 生成一个题目唯一用例名称，递增
@@ -101,7 +103,14 @@ func (p *problemCaseService) DeleteProblemCaseByID(id uint) *e.Error {
 	return nil
 }
 
-func (p *problemCaseService) InsertProblemCase(problemCase *po.ProblemCase) (uint, *e.Error) {
+func (p *problemCaseService) InsertProblemCase(problemCase *po.ProblemCase, input io.Reader,
+	output io.Reader) (uint, *e.Error) {
+	if problemCase.Subtask < 0 {
+		return 0, e.ErrBadRequest
+	}
+	if err := p.saveProblemCaseData(problemCase, input, output); err != nil {
+		return 0, err
+	}
 	err := p.problemCaseDao.InsertProblemCase(global.Mysql, problemCase)
 	if err != nil {
 		log.Println("Error while inserting problem case:", err)
@@ -110,8 +119,15 @@ func (p *problemCaseService) InsertProblemCase(problemCase *po.ProblemCase) (uin
 	return problemCase.ID, nil
 }
 
-func (p *problemCaseService) UpdateProblemCase(problemCase *po.ProblemCase) *e.Error {
-	err := p.problemCaseDao.UpdateProblemCase(global.Mysql, problemCase)
+func (p *problemCaseService) UpdateProblemCase(problemCase *po.ProblemCase, subtask *int, input io.Reader,
+	output io.Reader) *e.Error {
+	if subtask != nil && *subtask < 0 {
+		return e.ErrBadRequest
+	}
+	if err := p.saveProblemCaseData(problemCase, input, output); err != nil {
+		return err
+	}
+	err := p.problemCaseDao.UpdateProblemCase(global.Mysql, problemCase, subtask)
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return e.ErrProblemNotExist
 	}
@@ -122,6 +138,32 @@ func (p *problemCaseService) UpdateProblemCase(problemCase *po.ProblemCase) *e.E
 	return nil
 }
 
+// saveProblemCaseData 保存用例的输入和期望输出到对象存储，数据库中只保存sha256、长度和预览。
+// 数据按照内容保存，可能被多个用例引用，所以删除用例时不删除数据
+func (p *problemCaseService) saveProblemCaseData(problemCase *po.ProblemCase, input io.Reader,
+	output io.Reader) *e.Error {
+	if input == nil && output == nil {
+		return nil
+	}
+	store := file_store.NewProblemCOS(p.config.COSConfig)
+	var err error
+	if input != nil {
+		if problemCase.InputHash, problemCase.InputSize, problemCase.Input, err = saveCaseData(p.config, store,
+			input); err != nil {
+			log.Println("Error while saving problem case input:", err)
+			return e.ErrServer
+		}
+	}
+	if output != nil {
+		if problemCase.OutputHash, problemCase.OutputSize, problemCase.Output, err = saveCaseData(p.config, store,
+			output); err != nil {
+			log.Println("Error while saving problem case output:", err)
+			return e.ErrServer
+		}
+	}
+	return nil
+}
+
 func (p *problemCaseService) CheckProblemCaseName(id uint, name string, problemID uint) (bool, *e.Error) {
 	l, err := p.problemCaseDao.GetProblemCaseList(global.Mysql, &dto.PageQuery{
 		Page:     1,
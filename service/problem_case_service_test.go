@@ -1 +1,56 @@
 package service
+
+import (
+	"FanCode/dao"
+	"FanCode/global"
+	"FanCode/models/po"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestProblemCaseService_UpdateProblemCase(t *testing.T) {
+	// mock数据库
+	db, mock, err := sqlmock.New()
+	assert.Nil(t, err)
+	defer db.Close()
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		SkipInitializeWithVersion: true,
+		Conn:                      db,
+	}), &gorm.Config{})
+	assert.Nil(t, err)
+	global.Mysql = gormDB
+	problemCaseService := NewProblemCaseService(nil, dao.NewProblemCaseDao(), nil)
+
+	// 只修改名称时不修改子任务，子任务为2的用例修改名称后仍然是子任务2
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `problem_cases` SET `updated_at`=\\?,`name`=\\? WHERE .*`id` = \\?").
+		WithArgs(sqlmock.AnyArg(), "renamed", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	problemCase := &po.ProblemCase{Name: "renamed"}
+	problemCase.ID = 1
+	assert.Nil(t, problemCaseService.UpdateProblemCase(problemCase, nil, nil, nil))
+	assert.Nil(t, mock.ExpectationsWereMet())
+
+	// 提交了子任务时修改子任务，可以修改为0
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `problem_cases` SET `updated_at`=\\?,`name`=\\? WHERE .*`id` = \\?").
+		WithArgs(sqlmock.AnyArg(), "renamed", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `problem_cases` SET `subtask`=\\?,`updated_at`=\\? WHERE .*`id` = \\?").
+		WithArgs(0, sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	subtask := 0
+	assert.Nil(t, problemCaseService.UpdateProblemCase(problemCase, &subtask, nil, nil))
+	assert.Nil(t, mock.ExpectationsWereMet())
+
+	// 子任务编号不能为负数
+	subtask = -1
+	assert.NotNil(t, problemCaseService.UpdateProblemCase(problemCase, &subtask, nil, nil))
+}
@@ -79,6 +79,31 @@ func (q *problemService) InsertProblem(problem *po.Problem, ctx *gin.Context) (u
 	if problem.Number == "" {
 		problem.Number = "未命名编号" + utils.GetGenerateUniqueCode()
 	}
+	// 检测支持的语言以及特判程序和交互程序的语言
+	languages, checkErr := checkLanguages(problem.Languages)
+	if checkErr != nil {
+		return 0, checkErr
+	}
+	problem.Languages = languages
+	if checkErr = checkCheckerLanguage(problem); checkErr != nil {
+		return 0, checkErr
+	}
+	// 检测比较方式
+	if checkErr = checkComparator(problem); checkErr != nil {
+		return 0, checkErr
+	}
+	// 检测子任务
+	if checkErr = checkSubtasks(problem); checkErr != nil {
+		return 0, checkErr
+	}
+	// 检测时间和内存限制
+	if checkErr = checkLimits(problem); checkErr != nil {
+		return 0, checkErr
+	}
+	// 检测函数签名
+	if checkErr = checkFunctionSignature(problem); checkErr != nil {
+		return 0, checkErr
+	}
 	// 检测编号是否重复
 	if problem.Number != "" {
 		b, checkError := q.problemDao.CheckProblemNumberExists(global.Mysql, problem.Number)
@@ -104,6 +129,31 @@ func (q *problemService) InsertProblem(problem *po.Problem, ctx *gin.Context) (u
 
 func (q *problemService) UpdateProblem(problem *po.Problem, ctx *gin.Context) *e.Error {
 	problem.UpdatedAt = time.Now()
+	// 检测支持的语言以及特判程序和交互程序的语言
+	languages, checkErr := checkLanguages(problem.Languages)
+	if checkErr != nil {
+		return checkErr
+	}
+	problem.Languages = languages
+	if checkErr = checkCheckerLanguage(problem); checkErr != nil {
+		return checkErr
+	}
+	// 检测比较方式
+	if checkErr = checkComparator(problem); checkErr != nil {
+		return checkErr
+	}
+	// 检测子任务
+	if checkErr = checkSubtasks(problem); checkErr != nil {
+		return checkErr
+	}
+	// 检测时间和内存限制
+	if checkErr = checkLimits(problem); checkErr != nil {
+		return checkErr
+	}
+	// 检测函数签名
+	if checkErr = checkFunctionSignature(problem); checkErr != nil {
+		return checkErr
+	}
 	// 更新题目
 	if err := q.problemDao.UpdateProblem(global.Mysql, problem); err != nil {
 		log.Println(err)
@@ -208,7 +258,9 @@ func (q *problemService) GetProblemByID(id uint) (*dto.ProblemDtoForGet, *e.Erro
 	if err != nil {
 		return nil, e.ErrMysql
 	}
-	return dto.NewProblemDtoForGet(problem), nil
+	response := dto.NewProblemDtoForGet(problem)
+	response.Limits = getProblemLimits(problem)
+	return response, nil
 }
 
 func (q *problemService) GetProblemByNumber(number string) (*dto.ProblemDtoForGet, *e.Error) {
@@ -216,7 +268,9 @@ func (q *problemService) GetProblemByNumber(number string) (*dto.ProblemDtoForGe
 	if err != nil {
 		return nil, e.ErrMysql
 	}
-	return dto.NewProblemDtoForGet(problem), nil
+	response := dto.NewProblemDtoForGet(problem)
+	response.Limits = getProblemLimits(problem)
+	return response, nil
 }
 
 func (q *problemService) UpdateProblemEnable(id uint, enable int) *e.Error {
@@ -3,21 +3,28 @@ package service
 import (
 	"FanCode/config"
 	"FanCode/constants"
+	e "FanCode/error"
+	"FanCode/models/dto"
+	"FanCode/models/po"
+	"FanCode/service/harness"
+	"FanCode/service/judger"
 	"FanCode/utils"
+	"encoding/json"
+	"fmt"
+	"log"
 	"os"
 	"path"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 /**
  * 放一些公用的方法
  */
 
-const (
-	AcmCCodeFilePath    = "./resources/acmTemplate/c"
-	AcmGoCodeFilePath   = "./resources/acmTemplate/go"
-	AcmJavaCodeFilePath = "./resources/acmTemplate/java"
-)
-
 // getExecutePath 给用户的此次运行生成一个临时目录
 func getExecutePath(config *config.AppConfig) string {
 	uuid := utils.GetUUID()
@@ -32,16 +39,303 @@ func getTempDir(config *config.AppConfig) string {
 	return executePath
 }
 
+// getCompileCache 根据配置创建编译缓存，没有配置缓存目录或者创建失败时返回nil，每次都重新编译
+func getCompileCache(config *config.AppConfig) *judger.CompileCache {
+	if config.JudgeConfig == nil || config.JudgeConfig.CompileCacheDir == "" {
+		return nil
+	}
+	compileCache, err := judger.NewCompileCache(config.JudgeConfig.CompileCacheDir, config.JudgeConfig.CompileCacheSize)
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+	return compileCache
+}
+
+// getWorkerPool 根据配置创建worker池，没有配置worker数量时返回nil
+func getWorkerPool(config *config.AppConfig) *judger.WorkerPool {
+	if config.JudgeConfig == nil || config.JudgeConfig.WorkerPoolSize <= 0 {
+		return nil
+	}
+	return judger.NewWorkerPool(config.JudgeConfig.WorkerPoolSize,
+		time.Duration(config.JudgeConfig.WorkerIdleTimeout)*time.Second,
+		time.Duration(config.JudgeConfig.WorkerHealthCheck)*time.Second)
+}
+
+// getJudgeQueueConfig 获取判题队列的长度和worker数量
+func getJudgeQueueConfig(config *config.AppConfig) (int, int) {
+	queueSize, workers := 1024, 4
+	if config.JudgeConfig != nil {
+		if config.JudgeConfig.JudgeQueueSize > 0 {
+			queueSize = config.JudgeConfig.JudgeQueueSize
+		}
+		if config.JudgeConfig.JudgeWorkers > 0 {
+			workers = config.JudgeConfig.JudgeWorkers
+		}
+	}
+	return queueSize, workers
+}
+
+// getCaseParallelism 获取一次提交中同时运行的用例数量
+func getCaseParallelism(config *config.AppConfig) int {
+	if config.JudgeConfig == nil || config.JudgeConfig.CaseParallelism <= 0 {
+		return 1
+	}
+	return config.JudgeConfig.CaseParallelism
+}
+
+// getCPUSlots 获取所有提交同时运行的用户程序数量上限，没有配置时使用cpu核数
+func getCPUSlots(config *config.AppConfig) int {
+	if config.JudgeConfig == nil || config.JudgeConfig.CPUSlots <= 0 {
+		return runtime.NumCPU()
+	}
+	return config.JudgeConfig.CPUSlots
+}
+
+// getSandboxOptions 根据配置获取用户程序的沙箱选项，没有开启沙箱时返回nil
+func getSandboxOptions(config *config.AppConfig) *judger.SandboxOptions {
+	if config.JudgeConfig == nil || !config.JudgeConfig.Sandbox {
+		return nil
+	}
+	options := judger.DefaultSandboxOptions()
+	options.UID = config.JudgeConfig.SandboxUID
+	options.GID = config.JudgeConfig.SandboxGID
+	options.TmpfsSize = config.JudgeConfig.SandboxTmpfsSize
+	return options
+}
+
+// isSeccompEnabled 是否开启用户程序的系统调用过滤
+func isSeccompEnabled(config *config.AppConfig) bool {
+	return config.JudgeConfig != nil && config.JudgeConfig.Seccomp
+}
+
 func getAcmCodeTemplate(language constants.LanguageType) (string, error) {
-	var filePath string
-	switch language {
-	case constants.LanguageC:
-		filePath = AcmCCodeFilePath
-	case constants.LanguageGo:
-		filePath = AcmGoCodeFilePath
-	case constants.LanguageJava:
-		filePath = AcmJavaCodeFilePath
-	}
-	code, err := os.ReadFile(filePath)
+	spec, ok := judger.GetLanguageSpec(language)
+	if !ok {
+		return "", fmt.Errorf("language %s is not supported", language)
+	}
+	code, err := os.ReadFile(spec.Template)
 	return string(code), err
 }
+
+// splitLanguages 解析题目支持的语言，语言之间用,分割
+func splitLanguages(languages string) []string {
+	var list []string
+	for _, language := range strings.Split(languages, ",") {
+		if language = strings.TrimSpace(language); language != "" {
+			list = append(list, language)
+		}
+	}
+	return list
+}
+
+// checkLanguages 检查题目支持的语言是否都已经配置，并返回格式化后的语言列表
+func checkLanguages(languages string) (string, *e.Error) {
+	list := splitLanguages(languages)
+	for _, language := range list {
+		if _, ok := judger.GetLanguageSpec(constants.LanguageType(language)); !ok {
+			return "", e.ErrLanguageNotSupported
+		}
+	}
+	return strings.Join(list, ","), nil
+}
+
+// checkCheckerLanguage 检查特判程序和交互程序的语言，设置了程序但没有设置语言时默认使用c++
+func checkCheckerLanguage(problem *po.Problem) *e.Error {
+	if err := checkProgramLanguage(&problem.Checker, &problem.CheckerLanguage); err != nil {
+		return err
+	}
+	return checkProgramLanguage(&problem.Interactor, &problem.InteractorLanguage)
+}
+
+func checkProgramLanguage(code *string, language *string) *e.Error {
+	if strings.TrimSpace(*code) == "" {
+		*code = ""
+		*language = ""
+		return nil
+	}
+	if *language == "" {
+		*language = string(constants.LanguageCpp)
+	}
+	if _, ok := judger.GetLanguageSpec(constants.LanguageType(*language)); !ok {
+		return e.ErrLanguageNotSupported
+	}
+	return nil
+}
+
+// checkComparator 检查题目的比较方式
+func checkComparator(problem *po.Problem) *e.Error {
+	switch constants.ComparatorType(problem.Comparator) {
+	case constants.ComparatorDefault, constants.ComparatorExact, constants.ComparatorLine, constants.ComparatorToken,
+		constants.ComparatorCaseInsensitive, constants.ComparatorFloat, constants.ComparatorUnordered:
+		return nil
+	}
+	return e.ErrBadRequest
+}
+
+// checkFunctionSignature 检查题目的函数签名，核心代码模式的题目不能是交互题，并且只能使用支持生成代码的语言
+func checkFunctionSignature(problem *po.Problem) *e.Error {
+	problem.FunctionSignature = strings.TrimSpace(problem.FunctionSignature)
+	if problem.FunctionSignature == "" {
+		return nil
+	}
+	if problem.Interactor != "" {
+		return e.ErrBadRequest
+	}
+	if _, err := harness.ParseSignature(problem.FunctionSignature); err != nil {
+		log.Println(err)
+		return e.ErrBadRequest
+	}
+	for _, language := range splitLanguages(problem.Languages) {
+		if !harness.Supported(constants.LanguageType(language)) {
+			return e.ErrLanguageNotSupported
+		}
+	}
+	return nil
+}
+
+// parseFunctionSignature 解析题目的函数签名，不是核心代码模式的题目返回nil
+func parseFunctionSignature(problem *po.Problem) (*harness.Signature, error) {
+	if problem == nil || problem.FunctionSignature == "" {
+		return nil, nil
+	}
+	return harness.ParseSignature(problem.FunctionSignature)
+}
+
+// getProblemTemplateCode 核心代码模式的题目根据函数签名生成模板代码，否则读取acm模板
+func getProblemTemplateCode(problem *po.Problem, language constants.LanguageType) (string, error) {
+	signature, err := parseFunctionSignature(problem)
+	if err != nil {
+		return "", err
+	}
+	if signature == nil {
+		return getAcmCodeTemplate(language)
+	}
+	return harness.Stub(language, signature)
+}
+
+// checkSubtasks 检查题目的子任务设置
+func checkSubtasks(problem *po.Problem) *e.Error {
+	if _, err := parseSubtasks(problem.Subtasks); err != nil {
+		log.Println(err)
+		return e.ErrBadRequest
+	}
+	return nil
+}
+
+// supportLanguage 题目是否支持该语言，没有设置支持的语言时支持所有语言
+func supportLanguage(problem *po.Problem, language constants.LanguageType) bool {
+	list := splitLanguages(problem.Languages)
+	if len(list) == 0 {
+		return true
+	}
+	for _, l := range list {
+		if l == string(language) {
+			return true
+		}
+	}
+	return false
+}
+
+// getExecuteLimit 根据题目的限制和语言的倍数计算运行的时间、内存和栈大小限制，problem为nil时使用默认限制。
+// 题目设置了语言的倍数时优先使用题目的倍数，栈大小限制不乘以倍数
+func getExecuteLimit(problem *po.Problem, language constants.LanguageType) (int64, int64, int64) {
+	limitTime, memoryLimit, stackLimit := LimitExecuteTime, int64(LimitExecuteMemory), int64(0)
+	timeFactor, memoryFactor := 1.0, 1.0
+	if spec, ok := judger.GetLanguageSpec(language); ok {
+		timeFactor, memoryFactor = spec.TimeFactor, spec.MemoryFactor
+	}
+	if problem != nil {
+		if problem.TimeLimit > 0 {
+			limitTime = problem.TimeLimit * int64(time.Millisecond)
+		}
+		if problem.MemoryLimit > 0 {
+			memoryLimit = problem.MemoryLimit * 1024 * 1024
+		}
+		stackLimit = problem.StackLimit * 1024 * 1024
+		// 题目的倍数在保存时已经检查过
+		factors, _ := parseLimitFactors(problem.LimitFactors)
+		if factor, ok := factors[string(language)]; ok {
+			if factor.Time > 0 {
+				timeFactor = factor.Time
+			}
+			if factor.Memory > 0 {
+				memoryFactor = factor.Memory
+			}
+		}
+	}
+	limitTime = int64(float64(limitTime) * timeFactor)
+	memoryLimit = int64(float64(memoryLimit) * memoryFactor)
+	if stackLimit == 0 {
+		stackLimit = memoryLimit
+	}
+	return limitTime, memoryLimit, stackLimit
+}
+
+// getProblemLimits 获取题目支持的每种语言的限制，用于展示给用户
+func getProblemLimits(problem *po.Problem) []*dto.ProblemLimitDto {
+	languages := splitLanguages(problem.Languages)
+	if len(languages) == 0 {
+		for _, language := range judger.GetLanguages() {
+			languages = append(languages, string(language))
+		}
+		sort.Strings(languages)
+	}
+	limits := make([]*dto.ProblemLimitDto, len(languages))
+	for i, language := range languages {
+		limitTime, memoryLimit, stackLimit := getExecuteLimit(problem, constants.LanguageType(language))
+		limits[i] = &dto.ProblemLimitDto{
+			Language:    language,
+			TimeLimit:   limitTime / int64(time.Millisecond),
+			MemoryLimit: memoryLimit / 1024 / 1024,
+			StackLimit:  stackLimit / 1024 / 1024,
+		}
+	}
+	return limits
+}
+
+// parseLimitFactors 解析题目中每种语言的时间和内存限制倍数
+func parseLimitFactors(limitFactors string) (map[string]*po.LimitFactor, error) {
+	factors := make(map[string]*po.LimitFactor)
+	if strings.TrimSpace(limitFactors) == "" {
+		return factors, nil
+	}
+	if err := json.Unmarshal([]byte(limitFactors), &factors); err != nil {
+		return nil, err
+	}
+	return factors, nil
+}
+
+// checkLimits 检查题目的时间、内存、栈大小限制以及语言的倍数
+func checkLimits(problem *po.Problem) *e.Error {
+	if problem.TimeLimit < 0 || problem.TimeLimit > MaxProblemTimeLimit ||
+		problem.MemoryLimit < 0 || problem.MemoryLimit > MaxProblemMemoryLimit ||
+		problem.StackLimit < 0 || problem.StackLimit > MaxProblemMemoryLimit {
+		return e.ErrBadRequest
+	}
+	factors, err := parseLimitFactors(problem.LimitFactors)
+	if err != nil {
+		log.Println(err)
+		return e.ErrBadRequest
+	}
+	for language, factor := range factors {
+		if _, ok := judger.GetLanguageSpec(constants.LanguageType(language)); !ok {
+			return e.ErrLanguageNotSupported
+		}
+		if factor == nil || factor.Time < 0 || factor.Memory < 0 {
+			return e.ErrBadRequest
+		}
+	}
+	return nil
+}
+
+// truncateOutput 截断超过长度限制的输出，保证不会截断utf8字符
+func truncateOutput(output []byte, limit int) string {
+	if len(output) <= limit {
+		return string(output)
+	}
+	for limit > 0 && !utf8.RuneStart(output[limit]) {
+		limit--
+	}
+	return string(output[:limit])
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"FanCode/constants"
+	e "FanCode/error"
+	"FanCode/models/po"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestGetExecuteLimit(t *testing.T) {
+	// 没有题目时使用默认限制和语言的倍数
+	limitTime, memoryLimit, stackLimit := getExecuteLimit(nil, constants.LanguageJava)
+	assert.Equal(t, 2*LimitExecuteTime, limitTime)
+	assert.Equal(t, int64(2*LimitExecuteMemory), memoryLimit)
+	assert.Equal(t, memoryLimit, stackLimit)
+
+	problem := &po.Problem{
+		TimeLimit:    1000,
+		MemoryLimit:  256,
+		StackLimit:   64,
+		LimitFactors: `{"java": {"time": 3}, "python": {"time": 5, "memory": 1.5}}`,
+	}
+	limitTime, memoryLimit, stackLimit = getExecuteLimit(problem, constants.LanguageC)
+	assert.Equal(t, int64(time.Second), limitTime)
+	assert.Equal(t, int64(256*1024*1024), memoryLimit)
+	assert.Equal(t, int64(64*1024*1024), stackLimit)
+	// 题目只设置了时间倍数时内存倍数使用语言配置
+	limitTime, memoryLimit, _ = getExecuteLimit(problem, constants.LanguageJava)
+	assert.Equal(t, int64(3*time.Second), limitTime)
+	assert.Equal(t, int64(512*1024*1024), memoryLimit)
+	limitTime, memoryLimit, _ = getExecuteLimit(problem, constants.LanguagePython)
+	assert.Equal(t, int64(5*time.Second), limitTime)
+	assert.Equal(t, int64(384*1024*1024), memoryLimit)
+}
+
+func TestCheckLimits(t *testing.T) {
+	assert.Nil(t, checkLimits(&po.Problem{}))
+	assert.Nil(t, checkLimits(&po.Problem{TimeLimit: 2000, MemoryLimit: 512, LimitFactors: `{"java": {"time": 2}}`}))
+	assert.Equal(t, e.ErrBadRequest, checkLimits(&po.Problem{TimeLimit: -1}))
+	assert.Equal(t, e.ErrBadRequest, checkLimits(&po.Problem{MemoryLimit: MaxProblemMemoryLimit + 1}))
+	assert.Equal(t, e.ErrBadRequest, checkLimits(&po.Problem{LimitFactors: `{"java": 2}`}))
+	assert.Equal(t, e.ErrBadRequest, checkLimits(&po.Problem{LimitFactors: `{"java": {"time": -1}}`}))
+	assert.Equal(t, e.ErrLanguageNotSupported, checkLimits(&po.Problem{LimitFactors: `{"cobol": {"time": 2}}`}))
+}
+
+func TestCheckFunctionSignature(t *testing.T) {
+	signature := `{"name":"twoSum","params":[{"name":"nums","type":"int[]"},{"name":"target","type":"int"}],"returnType":"int[]"}`
+	assert.Nil(t, checkFunctionSignature(&po.Problem{}))
+	problem := &po.Problem{FunctionSignature: " " + signature + "\n", Languages: "c,go"}
+	assert.Nil(t, checkFunctionSignature(problem))
+	assert.Equal(t, signature, problem.FunctionSignature)
+	assert.Equal(t, e.ErrBadRequest, checkFunctionSignature(&po.Problem{FunctionSignature: `{"name":"1x"}`}))
+	assert.Equal(t, e.ErrBadRequest, checkFunctionSignature(&po.Problem{FunctionSignature: signature, Interactor: "int main() {}"}))
+	assert.Equal(t, e.ErrLanguageNotSupported, checkFunctionSignature(&po.Problem{FunctionSignature: signature, Languages: "c,cobol"}))
+}
+
+func TestGetProblemTemplateCode(t *testing.T) {
+	problem := &po.Problem{
+		FunctionSignature: `{"name":"twoSum","params":[{"name":"nums","type":"int[]"},{"name":"target","type":"int"}],"returnType":"int[]"}`,
+	}
+	code, err := getProblemTemplateCode(problem, constants.LanguageGo)
+	assert.Nil(t, err)
+	assert.Equal(t, "package main\n\nfunc twoSum(nums []int, target int) []int {\n\n}\n", code)
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"FanCode/constants"
+	e "FanCode/error"
+	"FanCode/models/dto"
+	"FanCode/models/po"
+	"encoding/json"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// 文件路径中每一级的名称只能使用字母、数字、_、-和.，使用/分隔
+var sourceFilePathRegexp = regexp.MustCompile(`^[A-Za-z0-9_.\-]+(/[A-Za-z0-9_.\-]+)*$`)
+
+// 文件路径的最大长度
+const maxSourceFilePathLength = 128
+
+// sourceFiles 用户提交的所有源文件
+type sourceFiles struct {
+	// 入口文件的路径，编译和运行时作为main文件，只能放在根目录
+	entry string
+	// 文件的相对路径和内容
+	files map[string]string
+	// 是否为多文件提交，单文件提交时只有入口文件
+	multiple bool
+}
+
+// newSourceFiles 检查用户提交的文件，files为空时是单文件提交，code为入口文件的内容。
+// entryFile为空时使用语言默认的main文件名称
+func newSourceFiles(language constants.LanguageType, code string, files map[string]string,
+	entryFile string) (*sourceFiles, *e.Error) {
+	mainFile, err := getMainFileNameByLanguage(language)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return &sourceFiles{entry: mainFile, files: map[string]string{mainFile: code}}, nil
+	}
+	if entryFile == "" {
+		entryFile = mainFile
+	}
+	if len(files) > MaxSourceFileCount {
+		return nil, e.ErrBadRequest
+	}
+	size := 0
+	for name, content := range files {
+		// 编译输出的执行文件为执行目录下的main，不能和用户的文件冲突
+		if !checkSourceFilePath(name) || name == "main" || strings.HasPrefix(name, "main/") {
+			return nil, e.ErrBadRequest
+		}
+		// go所有源文件作为同一个main包编译，子目录中的文件无法作为其他包导入
+		if language == constants.LanguageGo && strings.Contains(name, "/") {
+			return nil, e.ErrBadRequest
+		}
+		size += len(name) + len(content)
+	}
+	if size > MaxSourceFileSize {
+		return nil, e.ErrBadRequest
+	}
+	// 入口文件必须在根目录并且和语言的main文件扩展名相同，编译命令中的{mainName}等依赖入口文件的名称
+	if _, ok := files[entryFile]; !ok || strings.Contains(entryFile, "/") ||
+		path.Ext(entryFile) != path.Ext(mainFile) {
+		return nil, e.ErrBadRequest
+	}
+	// 文件和目录不能重名，比如同时提交a和a/b.c
+	for name := range files {
+		for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+			if _, ok := files[dir]; ok {
+				return nil, e.ErrBadRequest
+			}
+		}
+	}
+	return &sourceFiles{entry: entryFile, files: files, multiple: true}, nil
+}
+
+// checkSourceFilePath 文件路径必须是相对路径，不能包含.和..，避免写到执行目录之外
+func checkSourceFilePath(name string) bool {
+	if len(name) > maxSourceFilePathLength || !sourceFilePathRegexp.MatchString(name) {
+		return false
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// code 入口文件的内容
+func (s *sourceFiles) code() string {
+	return s.files[s.entry]
+}
+
+// marshal 多文件提交时保存到提交记录中的文件列表，单文件提交时为空，只保存入口文件的内容
+func (s *sourceFiles) marshal() (string, error) {
+	if !s.multiple {
+		return "", nil
+	}
+	data, err := json.Marshal(s.files)
+	return string(data), err
+}
+
+// newSubmitRequest 根据提交记录重新生成判题请求，用于分布式判题和重判
+func newSubmitRequest(submission *po.Submission) *dto.SubmitRequestDto {
+	request := &dto.SubmitRequestDto{
+		ProblemID: submission.ProblemID,
+		Language:  constants.LanguageType(submission.Language),
+		Code:      submission.Code,
+		EntryFile: submission.EntryFile,
+	}
+	if submission.Files != "" {
+		if err := json.Unmarshal([]byte(submission.Files), &request.Files); err != nil {
+			log.Printf("unmarshal source files error: %v\n", err)
+			request.Files = nil
+		}
+	}
+	return request
+}
+
+// save 把所有文件写入executePath，返回需要编译的文件列表以及不需要编译的文件列表，比如头文件。
+// 入口文件为编译文件列表中的第一个，和入口文件扩展名相同的文件也需要编译
+func (s *sourceFiles) save(executePath string) ([]string, []string, error) {
+	names := make([]string, 0, len(s.files))
+	for name := range s.files {
+		if name != s.entry {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	names = append([]string{s.entry}, names...)
+
+	var compileFiles, includeFiles []string
+	for _, name := range names {
+		filePath := filepath.Join(executePath, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+			return nil, nil, err
+		}
+		if err := os.WriteFile(filePath, []byte(s.files[name]), 0644); err != nil {
+			return nil, nil, err
+		}
+		if path.Ext(name) == path.Ext(s.entry) {
+			compileFiles = append(compileFiles, filePath)
+		} else {
+			includeFiles = append(includeFiles, filePath)
+		}
+	}
+	return compileFiles, includeFiles, nil
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"FanCode/constants"
+	e "FanCode/error"
+	"FanCode/models/po"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewSourceFiles(t *testing.T) {
+	// 单文件提交使用语言默认的main文件
+	sources, err := newSourceFiles(constants.LanguageC, "int main() {}", nil, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "main.c", sources.entry)
+	assert.Equal(t, "int main() {}", sources.code())
+	assert.False(t, sources.multiple)
+	files, err2 := sources.marshal()
+	assert.Nil(t, err2)
+	assert.Equal(t, "", files)
+
+	sources, err = newSourceFiles(constants.LanguageJava, "", map[string]string{
+		"Solution.java":      "class Solution {}",
+		"util/Helper.java":   "class Helper {}",
+		"resources/data.txt": "1 2",
+	}, "Solution.java")
+	assert.Nil(t, err)
+	assert.Equal(t, "Solution.java", sources.entry)
+	assert.Equal(t, "class Solution {}", sources.code())
+	assert.True(t, sources.multiple)
+
+	tests := []struct {
+		name      string
+		files     map[string]string
+		entryFile string
+	}{
+		{"missing entry", map[string]string{"lib.c": ""}, ""},
+		{"entry in directory", map[string]string{"src/main.c": ""}, "src/main.c"},
+		{"entry extension", map[string]string{"main.c": "", "lib.h": ""}, "lib.h"},
+		{"parent directory", map[string]string{"main.c": "", "../lib.c": ""}, ""},
+		{"current directory", map[string]string{"main.c": "", "./lib.c": ""}, ""},
+		{"absolute path", map[string]string{"main.c": "", "/tmp/lib.c": ""}, ""},
+		{"backslash", map[string]string{"main.c": "", "lib\\a.c": ""}, ""},
+		{"empty name", map[string]string{"main.c": "", "lib//a.c": ""}, ""},
+		{"executable name", map[string]string{"main.c": "", "main": ""}, ""},
+		{"file and directory", map[string]string{"main.c": "", "lib": "", "lib/a.h": ""}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newSourceFiles(constants.LanguageC, "", tt.files, tt.entryFile)
+			assert.Equal(t, e.ErrBadRequest, err)
+		})
+	}
+
+	// go的文件只能放在根目录
+	_, err = newSourceFiles(constants.LanguageGo, "", map[string]string{
+		"main.go":      "package main",
+		"util/util.go": "package util",
+	}, "")
+	assert.Equal(t, e.ErrBadRequest, err)
+	_, err = newSourceFiles(constants.LanguageGo, "", map[string]string{
+		"main.go": "package main",
+		"util.go": "package main",
+	}, "")
+	assert.Nil(t, err)
+}
+
+func TestSourceFiles_Save(t *testing.T) {
+	sources, err := newSourceFiles(constants.LanguageC, "", map[string]string{
+		"main.c":        "#include \"lib/util.h\"",
+		"lib/util.h":    "int add(int a, int b);",
+		"lib/util.c":    "int add(int a, int b) { return a + b; }",
+		"lib/README.md": "util",
+	}, "")
+	assert.Nil(t, err)
+	dir := t.TempDir()
+	compileFiles, includeFiles, err2 := sources.save(dir)
+	assert.Nil(t, err2)
+	// 入口文件在编译列表的第一个，头文件等不参与编译
+	assert.Equal(t, []string{filepath.Join(dir, "main.c"), filepath.Join(dir, "lib", "util.c")}, compileFiles)
+	assert.Equal(t, []string{filepath.Join(dir, "lib", "README.md"), filepath.Join(dir, "lib", "util.h")},
+		includeFiles)
+	content, err2 := os.ReadFile(filepath.Join(dir, "lib", "util.h"))
+	assert.Nil(t, err2)
+	assert.Equal(t, "int add(int a, int b);", string(content))
+}
+
+func TestNewSubmitRequest(t *testing.T) {
+	sources, err := newSourceFiles(constants.LanguageCpp, "", map[string]string{
+		"solution.cpp": "int main() {}",
+		"lib.hpp":      "",
+	}, "solution.cpp")
+	assert.Nil(t, err)
+	files, err2 := sources.marshal()
+	assert.Nil(t, err2)
+	submission := &po.Submission{
+		ProblemID: 1,
+		Language:  string(constants.LanguageCpp),
+		Code:      sources.code(),
+		Files:     files,
+		EntryFile: sources.entry,
+	}
+	request := newSubmitRequest(submission)
+	assert.Equal(t, sources.files, request.Files)
+	assert.Equal(t, "solution.cpp", request.EntryFile)
+	restored, err := newSourceFiles(request.Language, request.Code, request.Files, request.EntryFile)
+	assert.Nil(t, err)
+	assert.Equal(t, sources, restored)
+
+	// 单文件提交只有代码
+	request = newSubmitRequest(&po.Submission{Language: string(constants.LanguageC), Code: "int main() {}"})
+	assert.Nil(t, request.Files)
+	assert.Equal(t, "", request.EntryFile)
+}
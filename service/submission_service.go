@@ -6,7 +6,9 @@ import (
 	"FanCode/global"
 	"FanCode/models/dto"
 	"FanCode/models/po"
+	"errors"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"log"
 	"strconv"
 	"time"
@@ -19,18 +21,23 @@ type SubmissionService interface {
 	GetActivityYear(ctx *gin.Context) ([]string, *e.Error)
 	// GetUserSubmissionList 获取用户
 	GetUserSubmissionList(ctx *gin.Context, pageQuery *dto.PageQuery) (*dto.PageInfo, *e.Error)
+	// GetSubmission 获取用户的提交详情，包括每个用例的判题结果
+	GetSubmission(ctx *gin.Context, id uint) (*dto.SubmissionDetailDto, *e.Error)
 }
 
-func NewSubmissionService(submissionDao dao.SubmissionDao, problemDao dao.ProblemDao) SubmissionService {
+func NewSubmissionService(submissionDao dao.SubmissionDao, submissionCaseDao dao.SubmissionCaseDao,
+	problemDao dao.ProblemDao) SubmissionService {
 	return &submissionService{
-		submissionDao: submissionDao,
-		problemDao:    problemDao,
+		submissionDao:     submissionDao,
+		submissionCaseDao: submissionCaseDao,
+		problemDao:        problemDao,
 	}
 }
 
 type submissionService struct {
-	submissionDao dao.SubmissionDao
-	problemDao    dao.ProblemDao
+	submissionDao     dao.SubmissionDao
+	submissionCaseDao dao.SubmissionCaseDao
+	problemDao        dao.ProblemDao
 }
 
 func (u *submissionService) GetActivityMap(ctx *gin.Context, year int) ([]*dto.ActivityItem, *e.Error) {
@@ -117,6 +124,34 @@ func (u *submissionService) GetUserSubmissionList(ctx *gin.Context, pageQuery *d
 	}, nil
 }
 
+func (u *submissionService) GetSubmission(ctx *gin.Context, id uint) (*dto.SubmissionDetailDto, *e.Error) {
+	user := ctx.Keys["user"].(*dto.UserInfo)
+	submission, err := u.submissionDao.GetSubmissionByID(global.Mysql, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, e.ErrSubmissionNotExist
+	}
+	if err != nil {
+		log.Println(err)
+		return nil, e.ErrMysql
+	}
+	// 只能查看自己的提交
+	if submission.UserID != user.ID {
+		return nil, e.ErrSubmissionNotExist
+	}
+	cases, err := u.submissionCaseDao.GetSubmissionCaseList(global.Mysql, id)
+	if err != nil {
+		log.Println(err)
+		return nil, e.ErrMysql
+	}
+	response := dto.NewSubmissionDetailDto(submission, cases)
+	response.ProblemName, err = u.problemDao.GetProblemNameByID(global.Mysql, submission.ProblemID)
+	if err != nil {
+		log.Println(err)
+		return nil, e.ErrMysql
+	}
+	return response, nil
+}
+
 func getYearRange(year int) (time.Time, time.Time) {
 	startDate := time.Date(year, 1, 1, 0, 0, 0, 0, time.Local)
 	endDate := time.Date(year, 12, 31, 23, 59, 59, 999999999, time.Local)
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"FanCode/constants"
+	"FanCode/dao/mock"
+	e "FanCode/error"
+	"FanCode/global"
+	"FanCode/models/dto"
+	"FanCode/models/po"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestSubmissionService_GetSubmission(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+	submissionDao := mock.NewMockSubmissionDao(mockCtl)
+	submissionCaseDao := mock.NewMockSubmissionCaseDao(mockCtl)
+	problemDao := mock.NewMockProblemDao(mockCtl)
+	// mock数据
+	submission := &po.Submission{
+		UserID:    1,
+		ProblemID: 2,
+		Status:    constants.WrongAnswer,
+	}
+	submission.ID = 3
+	cases := []*po.SubmissionCase{
+		{SubmissionID: 3, CaseID: 1, CaseName: "case1", Status: constants.Accepted},
+		{SubmissionID: 3, CaseID: 2, CaseName: "case2", Status: constants.WrongAnswer, UserOutput: "1"},
+		{SubmissionID: 3, CaseID: 3, CaseName: "case3", Status: constants.Accepted},
+	}
+	submissionDao.EXPECT().GetSubmissionByID(global.Mysql, uint(3)).Return(submission, nil).Times(2)
+	submissionDao.EXPECT().GetSubmissionByID(global.Mysql, uint(4)).Return(nil, gorm.ErrRecordNotFound)
+	submissionCaseDao.EXPECT().GetSubmissionCaseList(global.Mysql, uint(3)).Return(cases, nil)
+	problemDao.EXPECT().GetProblemNameByID(global.Mysql, uint(2)).Return("problem", nil)
+
+	ctx := &gin.Context{}
+	ctx.Keys = make(map[string]interface{})
+	ctx.Keys["user"] = &dto.UserInfo{
+		ID: 1,
+	}
+	submissionService := NewSubmissionService(submissionDao, submissionCaseDao, problemDao)
+	detail, err := submissionService.GetSubmission(ctx, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, "problem", detail.ProblemName)
+	assert.Equal(t, constants.WrongAnswer, detail.Status)
+	assert.Equal(t, 3, detail.CaseCount)
+	assert.Equal(t, 2, detail.PassedCount)
+	assert.Equal(t, "case2", detail.Cases[1].CaseName)
+	assert.Equal(t, "1", detail.Cases[1].UserOutput)
+
+	// 提交不存在
+	_, err = submissionService.GetSubmission(ctx, 4)
+	assert.Equal(t, e.ErrSubmissionNotExist, err)
+
+	// 不能查看其他用户的提交
+	ctx.Keys["user"] = &dto.UserInfo{
+		ID: 2,
+	}
+	_, err = submissionService.GetSubmission(ctx, 3)
+	assert.Equal(t, e.ErrSubmissionNotExist, err)
+}
@@ -24,12 +24,14 @@ type UserCodeService interface {
 }
 
 type userCodeService struct {
-	codeDao dao.UserCodeDao
+	codeDao    dao.UserCodeDao
+	problemDao dao.ProblemDao
 }
 
-func NewUserCodeService(config *conf.AppConfig, userCodeDao dao.UserCodeDao) UserCodeService {
+func NewUserCodeService(config *conf.AppConfig, userCodeDao dao.UserCodeDao, problemDao dao.ProblemDao) UserCodeService {
 	return &userCodeService{
-		codeDao: userCodeDao,
+		codeDao:    userCodeDao,
+		problemDao: problemDao,
 	}
 }
 
@@ -71,12 +73,7 @@ func (u *userCodeService) GetUserCode(ctx *gin.Context, problemId uint, language
 	}
 	// 如果用户代码不存在，那么读取模板
 	if !exist {
-		// 读取acm模板
-		code, err := getAcmCodeTemplate(language)
-		if err != nil {
-			return "", e.ErrProblemGetFailed
-		}
-		return code, nil
+		return u.GetProblemTemplateCode(problemId, string(language))
 	}
 	var code *po.UserCode
 	code, err = u.codeDao.GetUserCode(global.Mysql, userInfo.ID, problemId, language)
@@ -101,10 +98,9 @@ func (u *userCodeService) GetUserCodeByProblemID(ctx *gin.Context, problemId uin
 	}
 	// 如果用户代码不存在，那么读取模板
 	language := constants.LanguageC
-	// 读取acm模板
-	code, err := getAcmCodeTemplate(language)
-	if err != nil {
-		return nil, e.ErrProblemGetFailed
+	code, err2 := u.GetProblemTemplateCode(problemId, string(language))
+	if err2 != nil {
+		return nil, err2
 	}
 	return &po.UserCode{
 		Code:     code,
@@ -112,10 +108,16 @@ func (u *userCodeService) GetUserCodeByProblemID(ctx *gin.Context, problemId uin
 	}, nil
 }
 
+// GetProblemTemplateCode 获取题目的模板代码，核心代码模式的题目根据函数签名生成，否则为acm模板
 func (u *userCodeService) GetProblemTemplateCode(problemID uint, language string) (string, *e.Error) {
-	// 读取acm模板
-	code, err := getAcmCodeTemplate(constants.LanguageType(language))
+	problem, err := u.problemDao.GetProblemByID(global.Mysql, problemID)
 	if err != nil {
+		log.Printf("GetProblemByID error: %v\n", err)
+		return "", e.ErrProblemGetFailed
+	}
+	code, err := getProblemTemplateCode(problem, constants.LanguageType(language))
+	if err != nil {
+		log.Println(err)
 		return "", e.ErrProblemGetFailed
 	}
 	return code, nil
@@ -23,3 +23,9 @@ func initApp(*config.AppConfig) (*http.Server, error) {
 		routers.SetupRouter,
 		newApp))
 }
+
+func initJudgeWorker(*config.AppConfig) (*service.JudgeWorker, error) {
+	panic(wire.Build(
+		dao.ProviderSet,
+		service.ProviderSet))
+}
@@ -44,21 +44,23 @@ func initApp(appConfig *config.AppConfig) (*http.Server, error) {
 	sysUserService := service.NewSysUserService(appConfig, sysUserDao, sysRoleDao)
 	sysUserController := admin.NewSysUserController(sysUserService)
 	submissionDao := dao.NewSubmissionDao()
-	judgeService := service.NewJudgeService(appConfig, problemService, submissionDao, problemAttemptDao, problemDao, problemCaseDao)
+	submissionCaseDao := dao.NewSubmissionCaseDao()
+	judgeService := service.NewJudgeService(appConfig, problemService, submissionDao, submissionCaseDao, problemAttemptDao, problemDao, problemCaseDao)
+	judgeManagementController := admin.NewJudgeManagementController(judgeService)
 	judgeController := user.NewJudgeController(judgeService)
 	debugService := service.NewDebugService(appConfig, judgeService)
 	debugController := user.NewDebugController(debugService)
 	userCodeDao := dao.NewUserCodeDao()
-	userCodeService := service.NewUserCodeService(appConfig, userCodeDao)
+	userCodeService := service.NewUserCodeService(appConfig, userCodeDao, problemDao)
 	problemController := user.NewProblemController(problemService, userCodeService)
 	problemBankController := user.NewProblemBankController(problemBankService)
-	submissionService := service.NewSubmissionService(submissionDao, problemDao)
+	submissionService := service.NewSubmissionService(submissionDao, submissionCaseDao, problemDao)
 	submissionController := user.NewSubmissionController(submissionService)
 	accountService := service.NewAccountService(appConfig, sysUserDao)
 	accountController := controller.NewAccountController(accountService)
 	authService := service.NewAuthService(appConfig, sysUserDao, sysMenuDao, sysRoleDao)
 	authController := controller.NewAuthController(authService)
-	controllerController := controller.NewController(problemBankManagementController, problemManagementController, problemCaseManagementController, sysApiController, sysMenuController, sysRoleController, sysUserController, judgeController, debugController, problemController, problemBankController, submissionController, accountController, authController)
+	controllerController := controller.NewController(problemBankManagementController, problemManagementController, problemCaseManagementController, sysApiController, sysMenuController, sysRoleController, sysUserController, judgeManagementController, judgeController, debugController, problemController, problemBankController, submissionController, accountController, authController)
 	recoverPanicInterceptor := interceptor.NewRecoverPanicInterceptor()
 	corsInterceptor := interceptor.NewCorsInterceptor()
 	requestInterceptor := interceptor.NewRequestInterceptor(sysRoleService, sysUserService)
@@ -66,3 +68,14 @@ func initApp(appConfig *config.AppConfig) (*http.Server, error) {
 	server := newApp(engine, appConfig)
 	return server, nil
 }
+
+func initJudgeWorker(appConfig *config.AppConfig) (*service.JudgeWorker, error) {
+	problemDao := dao.NewProblemDao()
+	problemCaseDao := dao.NewProblemCaseDao()
+	problemAttemptDao := dao.NewProblemAttemptDao()
+	problemService := service.NewProblemService(appConfig, problemDao, problemCaseDao, problemAttemptDao)
+	submissionDao := dao.NewSubmissionDao()
+	submissionCaseDao := dao.NewSubmissionCaseDao()
+	judgeWorker := service.NewJudgeWorker(appConfig, problemService, submissionDao, submissionCaseDao, problemAttemptDao, problemDao, problemCaseDao)
+	return judgeWorker, nil
+}